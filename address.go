@@ -0,0 +1,98 @@
+package nftmeta
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// Address represents an (Ethereum-style) 20-byte address, such as is used in a "fee_recipient" or a list of creators.
+//
+// An Address always marshals in its EIP-55 mixed-case checksummed form. For example:
+//
+//	0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+type Address [20]byte
+
+// ParseAddress parses a hexadecimal address — "0x" followed by 40 hexadecimal digits.
+//
+// If the hexadecimal digits are mixed-case, then they must have a valid EIP-55 checksum.
+// (If they are all lower-case or all upper-case, then there is no checksum to check.)
+func ParseAddress(value string) (Address, error) {
+	if err := validateAddress(value); nil != err {
+		return Address{}, err
+	}
+
+	var address Address
+	if _, err := hex.Decode(address[:], []byte(value[2:])); nil != err {
+		return Address{}, erorr.Errorf("nftmeta: %q is not a valid address: %w", value, err)
+	}
+
+	var hasLower, hasUpper bool
+	for _, r := range value[2:] {
+		switch {
+		case 'a' <= r && r <= 'f':
+			hasLower = true
+		case 'A' <= r && r <= 'F':
+			hasUpper = true
+		}
+	}
+
+	if hasLower && hasUpper {
+		if checksummed := address.String(); value[2:] != checksummed[2:] {
+			return Address{}, erorr.Errorf("nftmeta: address %q has a bad EIP-55 checksum (expected %q)", value, checksummed)
+		}
+	}
+
+	return address, nil
+}
+
+// String returns the EIP-55 checksummed form of the address.
+func (receiver Address) String() string {
+	var lower [2 * len(Address{})]byte
+	hex.Encode(lower[:], receiver[:])
+
+	digest := keccak256(lower[:])
+
+	var p [2 + len(lower)]byte
+	p[0] = '0'
+	p[1] = 'x'
+
+	for i, b := range lower {
+		var nibble byte = digest[i/2]
+		if 0 == i%2 {
+			nibble >>= 4
+		}
+		nibble &= 0x0f
+
+		if 'a' <= b && b <= 'f' && 8 <= nibble {
+			b -= 'a' - 'A'
+		}
+		p[2+i] = b
+	}
+
+	return string(p[:])
+}
+
+func (receiver Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(receiver.String())
+}
+
+func (receiver *Address) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling address: %w", err)
+	}
+
+	address, err := ParseAddress(str)
+	if nil != err {
+		return err
+	}
+
+	*receiver = address
+	return nil
+}
@@ -0,0 +1,154 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"encoding/json"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestParseAddress(t *testing.T) {
+
+	tests := []struct{
+		Value string
+		Expected string
+	}{
+		// These are the test-vectors from EIP-55.
+		{
+			Value:    "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			Expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		{
+			Value:    "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+			Expected: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		},
+		{
+			Value:    "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+			Expected: "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		},
+		{
+			Value:    "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+			Expected: "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		},
+		{
+			Value:    "0x52908400098527886E0F7030069857D2E4169EE7",
+			Expected: "0x52908400098527886E0F7030069857D2E4169EE7",
+		},
+		{
+			Value:    "0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+			Expected: "0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+		},
+		{
+			Value:    "0xde709f2102306220921060314715629080e2fb77",
+			Expected: "0xde709f2102306220921060314715629080e2fb77",
+		},
+		{
+			Value:    "0x27b1fdb04752bbc536007a920d24acb045561c26",
+			Expected: "0x27b1fdb04752bbc536007a920d24acb045561c26",
+		},
+
+
+
+		{
+			Value:    "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+			Expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		{
+			Value:    "0X5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+			Expected: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		{
+			Value:    "0x0000000000000000000000000000000000000000",
+			Expected: "0x0000000000000000000000000000000000000000",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		address, err := nftmeta.ParseAddress(test.Value)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+
+		{
+			expected := test.Expected
+			actual := address.String()
+
+			if expected != actual {
+				t.Errorf("For test #%d, the actual address is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				t.Logf("VALUE:    %q", test.Value)
+				continue
+			}
+		}
+
+		{
+			expected := `"` + test.Expected + `"`
+
+			actual, err := json.Marshal(address)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected != string(actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %s", actual)
+				continue
+			}
+		}
+	}
+}
+
+func TestParseAddress_fail(t *testing.T) {
+
+	tests := []struct{
+		Value string
+	}{
+		{
+			Value: "",
+		},
+		{
+			Value: "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		},
+		{
+			Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe",
+		},
+		{
+			Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAedd",
+		},
+		{
+			Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeZ",
+		},
+		{
+			Value: "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", // bad checksum
+		},
+		{
+			Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", // bad checksum
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := nftmeta.ParseAddress(test.Value)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+
+		if !strings.HasPrefix(err.Error(), "nftmeta: ") {
+			t.Errorf("For test #%d, the actual error message is not what was expected.", testNumber)
+			t.Logf("ERROR: %s", err)
+			continue
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package nftmeta
+
+import (
+	"crypto/sha512"
+	"encoding/base32"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// algorandAddressEncoding is the (upper-case, unpadded) base32 that Algorand addresses use.
+var algorandAddressEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// algorandAddress returns the Algorand address for the 32-byte public-key (or, for ARC-19, digest) 'key'.
+//
+// An Algorand address is the base32 of the key followed by a 4-byte checksum — the last 4 bytes of the SHA-512/256 of the key.
+func algorandAddress(key []byte) string {
+	checksum := sha512.Sum512_256(key)
+
+	var p []byte
+	p = append(p, key...)
+	p = append(p, checksum[len(checksum)-4:]...)
+
+	return algorandAddressEncoding.EncodeToString(p)
+}
+
+// algorandAddressKey returns the 32-byte key of the Algorand address 'address', after checking its checksum.
+func algorandAddressKey(address string) ([]byte, error) {
+	p, err := algorandAddressEncoding.DecodeString(address)
+	if nil != err || 36 != len(p) {
+		return nil, erorr.Errorf("nftmeta: %q is not a valid Algorand address", address)
+	}
+
+	key := p[:32]
+	if algorandAddress(key) != address {
+		return nil, erorr.Errorf("nftmeta: Algorand address %q has a bad checksum", address)
+	}
+
+	return key, nil
+}
+
+// arc19Template is a parsed ARC-19 template — i.e., template-ipfs://{ipfscid:<version>:<multicodec>:<field>:<hash>}<suffix>.
+type arc19Template struct {
+	version uint64
+	codec   uint64
+	suffix  string
+}
+
+const arc19TemplatePrefix = "template-ipfs://{ipfscid:"
+
+func parseARC19Template(template string) (arc19Template, error) {
+	if !strings.HasPrefix(template, arc19TemplatePrefix) {
+		return arc19Template{}, erorr.Errorf("nftmeta: %q is not an ARC-19 template", template)
+	}
+
+	rest := template[len(arc19TemplatePrefix):]
+
+	end := strings.IndexByte(rest, '}')
+	if end < 0 {
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q is missing its closing '}'", template)
+	}
+
+	parts := strings.Split(rest[:end], ":")
+	if 4 != len(parts) {
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q does not have the form {ipfscid:<version>:<multicodec>:<field>:<hash>}", template)
+	}
+
+	var parsed arc19Template
+	parsed.suffix = rest[end+1:]
+
+	switch parts[0] {
+	case "0":
+		parsed.version = 0
+	case "1":
+		parsed.version = 1
+	default:
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q has unsupported CID version %q", template, parts[0])
+	}
+
+	switch parts[1] {
+	case "raw":
+		parsed.codec = multicodecRaw
+	case "dag-pb":
+		parsed.codec = multicodecDagPB
+	default:
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q has unsupported multicodec %q", template, parts[1])
+	}
+
+	if "reserve" != parts[2] {
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q has unsupported field %q", template, parts[2])
+	}
+
+	if "sha2-256" != parts[3] {
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q has unsupported hash %q", template, parts[3])
+	}
+
+	if 0 == parsed.version && multicodecDagPB != parsed.codec {
+		return arc19Template{}, erorr.Errorf("nftmeta: ARC-19 template %q has a CIDv0 that is not dag-pb", template)
+	}
+
+	return parsed, nil
+}
+
+// ARC19Template returns the ARC-19 template URL, and the reserve address, that together encode the IPFS CID 'cid'.
+//
+// For example, for a CIDv1 that is raw, the template would be:
+//
+//	template-ipfs://{ipfscid:1:raw:reserve:sha2-256}
+//
+// Only CIDs with a sha2-256 multihash can be encoded in a reserve address.
+func ARC19Template(cid string) (template string, reserveAddress string, err error) {
+	parsed, err := parseCID(cid)
+	if nil != err {
+		return "", "", err
+	}
+
+	digest, err := parsed.sha256Digest()
+	if nil != err {
+		return "", "", err
+	}
+
+	var codec string
+	switch parsed.codec {
+	case multicodecRaw:
+		codec = "raw"
+	case multicodecDagPB:
+		codec = "dag-pb"
+	default:
+		return "", "", erorr.Errorf("nftmeta: CID %q has a multicodec (0x%x) that ARC-19 does not support", cid, parsed.codec)
+	}
+
+	var version string = "0"
+	if 1 == parsed.version {
+		version = "1"
+	}
+
+	template = arc19TemplatePrefix + version + ":" + codec + ":reserve:sha2-256}"
+	return template, algorandAddress(digest), nil
+}
+
+// ARC19ReserveAddress returns the reserve address that encodes the IPFS CID 'cid'.
+//
+// The version and multicodec of 'cid' are not in the reserve address — they are in the template. (See ARC19Template.)
+func ARC19ReserveAddress(cid string) (string, error) {
+	_, reserveAddress, err := ARC19Template(cid)
+	return reserveAddress, err
+}
+
+// ARC19CID returns the IPFS CID that the ARC-19 'template' and 'reserveAddress' together encode.
+func ARC19CID(template string, reserveAddress string) (string, error) {
+	parsed, err := parseARC19Template(template)
+	if nil != err {
+		return "", err
+	}
+
+	digest, err := algorandAddressKey(reserveAddress)
+	if nil != err {
+		return "", err
+	}
+
+	return cid{version: parsed.version, codec: parsed.codec, multihash: sha256Multihash(digest)}.String(), nil
+}
+
+// ARC19URL resolves the ARC-19 'template' with 'reserveAddress' into an ipfs:// URL.
+//
+// Anything after the closing '}' of the template (such as a path) is kept. For example:
+//
+//	template-ipfs://{ipfscid:1:raw:reserve:sha2-256}/metadata.json
+//
+// might resolve to:
+//
+//	ipfs://bafkrei.../metadata.json
+func ARC19URL(template string, reserveAddress string) (string, error) {
+	parsed, err := parseARC19Template(template)
+	if nil != err {
+		return "", err
+	}
+
+	cid, err := ARC19CID(template, reserveAddress)
+	if nil != err {
+		return "", err
+	}
+
+	return "ipfs://" + cid + parsed.suffix, nil
+}
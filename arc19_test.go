@@ -0,0 +1,148 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestARC19(t *testing.T) {
+
+	tests := []struct{
+		CID string
+		ExpectedTemplate string
+	}{
+		{
+			CID:              "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedTemplate: "template-ipfs://{ipfscid:0:dag-pb:reserve:sha2-256}",
+		},
+		{
+			CID:              "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+			ExpectedTemplate: "template-ipfs://{ipfscid:1:dag-pb:reserve:sha2-256}",
+		},
+	}
+
+	// These two CIDs are the CIDv0 and CIDv1 of the same content, so they have the same digest, and thus the same reserve address.
+	var reserveAddresses = map[string]struct{}{}
+
+	for testNumber, test := range tests {
+
+		template, reserveAddress, err := nftmeta.ARC19Template(test.CID)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+		reserveAddresses[reserveAddress] = struct{}{}
+
+		if expected, actual := test.ExpectedTemplate, template; expected != actual {
+			t.Errorf("For test #%d, the actual template is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := 58, len(reserveAddress); expected != actual {
+			t.Errorf("For test #%d, the actual length of the reserve address is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			t.Logf("RESERVE-ADDRESS: %q", reserveAddress)
+			continue
+		}
+
+		cid, err := nftmeta.ARC19CID(template, reserveAddress)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.CID, cid; expected != actual {
+			t.Errorf("For test #%d, the actual CID is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+
+	if expected, actual := 1, len(reserveAddresses); expected != actual {
+		t.Errorf("Expected the CIDv0 and CIDv1 of the same content to have the same reserve address, but they did not.")
+		t.Logf("RESERVE-ADDRESSES: %v", reserveAddresses)
+	}
+
+	// Converting from the CIDv0's reserve address under a CIDv1 template gives the CIDv1.
+	{
+		reserveAddress, err := nftmeta.ARC19ReserveAddress("QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR")
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		url, err := nftmeta.ARC19URL("template-ipfs://{ipfscid:1:dag-pb:reserve:sha2-256}/metadata.json", reserveAddress)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if expected, actual := "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/metadata.json", url; expected != actual {
+			t.Errorf("The actual URL is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+}
+
+func TestARC19CID_zeroAddress(t *testing.T) {
+
+	// The all-zeros Algorand address (which is well known) encodes an all-zeros digest.
+	const zeroAddress = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ"
+
+	cid, err := nftmeta.ARC19CID("template-ipfs://{ipfscid:1:raw:reserve:sha2-256}", zeroAddress)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := "bafkreiaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", cid; expected != actual {
+		t.Errorf("The actual CID is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+}
+
+func TestARC19CID_fail(t *testing.T) {
+
+	tests := []struct{
+		Template string
+		ReserveAddress string
+	}{
+		{
+			Template:       "template-ipfs://{ipfscid:1:raw:reserve:sha2-256}",
+			ReserveAddress: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKA", // bad checksum
+		},
+		{
+			Template:       "template-ipfs://{ipfscid:2:raw:reserve:sha2-256}",
+			ReserveAddress: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ",
+		},
+		{
+			Template:       "template-ipfs://{ipfscid:0:raw:reserve:sha2-256}",
+			ReserveAddress: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ",
+		},
+		{
+			Template:       "template-ipfs://{ipfscid:1:raw:manager:sha2-256}",
+			ReserveAddress: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ",
+		},
+		{
+			Template:       "ipfs://{ipfscid:1:raw:reserve:sha2-256}",
+			ReserveAddress: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := nftmeta.ARC19CID(test.Template, test.ReserveAddress)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("TEMPLATE:        %q", test.Template)
+			t.Logf("RESERVE-ADDRESS: %q", test.ReserveAddress)
+			continue
+		}
+	}
+}
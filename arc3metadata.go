@@ -0,0 +1,415 @@
+package nftmeta
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// ARC3MetaData represents the Algorand ARC-3 metadata JSON.
+//
+// ARC-3 is built on top of MetaData — from which the "name", "description", "image", "background_color", and "animation_url" are used,
+// and the "external_link" becomes the ARC-3 "external_url".
+// ARC-3 has no "attributes", so each attribute becomes a "properties" entry of trait-type to value.
+//
+// Use ARC3MetaData.MetadataHash to compute what the "am" (metadata hash) of the ASA must be.
+type ARC3MetaData struct {
+	metadata              MetaData
+	animationURLIntegrity opt.Optional[string]
+	animationURLMimeType  opt.Optional[string]
+	externalURLIntegrity  opt.Optional[string]
+	externalURLMimeType   opt.Optional[string]
+	imageIntegrity        opt.Optional[string]
+	imageMimeType         opt.Optional[string]
+	decimals              opt.Optional[uint64]
+	extraMetadata         opt.Optional[[]byte]
+	properties            map[string]json.RawMessage
+}
+
+// ARC3MetaDataFrom returns ARC-3 metadata built on top of 'metadata'.
+func ARC3MetaDataFrom(metadata MetaData) ARC3MetaData {
+	return ARC3MetaData{
+		metadata: metadata,
+	}
+}
+
+// MetaData returns the MetaData that the ARC-3 metadata is built on top of.
+func (receiver ARC3MetaData) MetaData() MetaData {
+	return receiver.metadata
+}
+
+func (receiver ARC3MetaData) MarshalJSON() ([]byte, error) {
+
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	var extraMetadata opt.Optional[string]
+	if value, something := receiver.extraMetadata.Get(); something {
+		extraMetadata = opt.Something(base64.StdEncoding.EncodeToString(value))
+	}
+
+	p = append(p, '{')
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"animation_url",           receiver.metadata.animationURL},
+		{"animation_url_integrity", receiver.animationURLIntegrity},
+		{"animation_url_mimetype",  receiver.animationURLMimeType},
+		{"background_color",        receiver.metadata.backgroundColor},
+		{"description",             receiver.metadata.description},
+		{"external_url",            receiver.metadata.externalLink},
+		{"external_url_integrity",  receiver.externalURLIntegrity},
+		{"external_url_mimetype",   receiver.externalURLMimeType},
+		{"extra_metadata",          extraMetadata},
+		{"image",                   receiver.metadata.image},
+		{"image_integrity",         receiver.imageIntegrity},
+		{"image_mimetype",          receiver.imageMimeType},
+		{"name",                    receiver.metadata.name},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	{
+		value, something := receiver.decimals.Get()
+		if something {
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			p = append(p, `"decimals":`...)
+			p = strconv.AppendUint(p, value, 10)
+		}
+	}
+
+	{
+		properties, err := receiver.allProperties()
+		if nil != err {
+			return nil, err
+		}
+
+		if 0 < len(properties) {
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			var names []string
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			p = append(p, `"properties":{`...)
+			for index, name := range names {
+				if 0 < index {
+					p = append(p, ',')
+				}
+
+				bytes, err := json.Marshal(name)
+				if nil != err {
+					return nil, erorr.Errorf("nftmeta: problem json-marshaling %T: %w", name, err)
+				}
+				p = append(p, bytes...)
+				p = append(p, ':')
+				p = append(p, properties[name]...)
+			}
+			p = append(p, '}')
+		}
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+// allProperties returns the properties — including the attributes (of the MetaData).
+func (receiver ARC3MetaData) allProperties() (map[string]json.RawMessage, error) {
+	var properties = map[string]json.RawMessage{}
+
+	for _, attribute := range receiver.metadata.attributes {
+		traitType, something := attribute.traitType.Get()
+		if !something {
+			return nil, errTraitTypeNothing
+		}
+		if _, found := properties[traitType]; found {
+			return nil, erorr.Errorf("nftmeta: duplicate trait-type %q cannot be put into ARC-3 properties", traitType)
+		}
+
+		value, err := appendAttributeValue(nil, attribute.value)
+		if nil != err {
+			return nil, err
+		}
+
+		properties[traitType] = value
+	}
+
+	for name, value := range receiver.properties {
+		if _, found := properties[name]; found {
+			return nil, erorr.Errorf("nftmeta: ARC-3 property %q is both an attribute and a property", name)
+		}
+
+		properties[name] = value
+	}
+
+	return properties, nil
+}
+
+func (receiver *ARC3MetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		AnimationURL          *string                    `json:"animation_url"`
+		AnimationURLIntegrity *string                    `json:"animation_url_integrity"`
+		AnimationURLMimeType  *string                    `json:"animation_url_mimetype"`
+		BackgroundColor       *string                    `json:"background_color"`
+		Description           *string                    `json:"description"`
+		ExternalURL           *string                    `json:"external_url"`
+		ExternalURLIntegrity  *string                    `json:"external_url_integrity"`
+		ExternalURLMimeType   *string                    `json:"external_url_mimetype"`
+		ExtraMetadata         *string                    `json:"extra_metadata"`
+		Image                 *string                    `json:"image"`
+		ImageIntegrity        *string                    `json:"image_integrity"`
+		ImageMimeType         *string                    `json:"image_mimetype"`
+		Name                  *string                    `json:"name"`
+		Decimals              *uint64                    `json:"decimals"`
+		Properties            map[string]json.RawMessage `json:"properties"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling arc-3 metadata: %w", err)
+	}
+
+	var metadata ARC3MetaData
+
+	metadata.metadata.animationURL    = optionalString(raw.AnimationURL)
+	metadata.metadata.backgroundColor = optionalString(raw.BackgroundColor)
+	metadata.metadata.description     = optionalString(raw.Description)
+	metadata.metadata.externalLink    = optionalString(raw.ExternalURL)
+	metadata.metadata.image           = optionalString(raw.Image)
+	metadata.metadata.name            = optionalString(raw.Name)
+
+	metadata.animationURLIntegrity = optionalString(raw.AnimationURLIntegrity)
+	metadata.animationURLMimeType  = optionalString(raw.AnimationURLMimeType)
+	metadata.externalURLIntegrity  = optionalString(raw.ExternalURLIntegrity)
+	metadata.externalURLMimeType   = optionalString(raw.ExternalURLMimeType)
+	metadata.imageIntegrity        = optionalString(raw.ImageIntegrity)
+	metadata.imageMimeType         = optionalString(raw.ImageMimeType)
+
+	if nil != raw.Decimals {
+		metadata.decimals = opt.Something(*raw.Decimals)
+	}
+
+	if nil != raw.ExtraMetadata {
+		value, err := base64.StdEncoding.DecodeString(*raw.ExtraMetadata)
+		if nil != err {
+			return erorr.Errorf("nftmeta: problem base64-decoding arc-3 extra_metadata: %w", err)
+		}
+		metadata.extraMetadata = opt.Something(value)
+	}
+
+	// Properties with a string or number value become attributes. Anything else stays a property.
+	{
+		var names []string
+		for name := range raw.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value, err := unmarshalAttributeValue(raw.Properties[name])
+			if nil != err {
+				if err := metadata.SetProperty(name, raw.Properties[name]); nil != err {
+					return err
+				}
+				continue
+			}
+
+			metadata.metadata.attributes = append(metadata.metadata.attributes, Attribute{
+				traitType: opt.Something(name),
+				value:     value,
+			})
+		}
+	}
+
+	*receiver = metadata
+	return nil
+}
+
+// MetadataHash returns what the "am" (metadata hash) of the ASA must be, for this ARC-3 metadata.
+//
+// This is computed over the JSON that ARC3MetaData.MarshalJSON returns. So it is that exact JSON that must be published.
+func (receiver ARC3MetaData) MetadataHash() ([32]byte, error) {
+	p, err := receiver.MarshalJSON()
+	if nil != err {
+		return [32]byte{}, err
+	}
+
+	return ARC3MetadataHash(p)
+}
+
+// ARC3MetadataHash returns what the "am" (metadata hash) of the ASA must be, for the ARC-3 metadata JSON 'data'.
+//
+// If the JSON has no "extra_metadata", then this is:
+//
+//	SHA-512/256("arc0003/amj" || data)
+//
+// If the JSON does have "extra_metadata", then this is:
+//
+//	SHA-512/256("arc0003/am" || SHA-512/256("arc0003/amj" || data) || extra_metadata)
+//
+// (Where 'extra_metadata' is the base64-decoded "extra_metadata".)
+func ARC3MetadataHash(data []byte) ([32]byte, error) {
+	var raw struct {
+		ExtraMetadata *string `json:"extra_metadata"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return [32]byte{}, erorr.Errorf("nftmeta: problem json-unmarshaling arc-3 metadata: %w", err)
+	}
+
+	var jsonHash [32]byte
+	{
+		hasher := sha512.New512_256()
+		hasher.Write([]byte("arc0003/amj"))
+		hasher.Write(data)
+		copy(jsonHash[:], hasher.Sum(nil))
+	}
+
+	if nil == raw.ExtraMetadata {
+		return jsonHash, nil
+	}
+
+	extraMetadata, err := base64.StdEncoding.DecodeString(*raw.ExtraMetadata)
+	if nil != err {
+		return [32]byte{}, erorr.Errorf("nftmeta: problem base64-decoding arc-3 extra_metadata: %w", err)
+	}
+
+	var hash [32]byte
+	{
+		hasher := sha512.New512_256()
+		hasher.Write([]byte("arc0003/am"))
+		hasher.Write(jsonHash[:])
+		hasher.Write(extraMetadata)
+		copy(hash[:], hasher.Sum(nil))
+	}
+
+	return hash, nil
+}
+
+func (receiver ARC3MetaData) AnimationURLIntegrity() opt.Optional[string] {
+	return receiver.animationURLIntegrity
+}
+
+func (receiver ARC3MetaData) AnimationURLMimeType() opt.Optional[string] {
+	return receiver.animationURLMimeType
+}
+
+func (receiver ARC3MetaData) Decimals() opt.Optional[uint64] {
+	return receiver.decimals
+}
+
+func (receiver ARC3MetaData) ExternalURLIntegrity() opt.Optional[string] {
+	return receiver.externalURLIntegrity
+}
+
+func (receiver ARC3MetaData) ExternalURLMimeType() opt.Optional[string] {
+	return receiver.externalURLMimeType
+}
+
+// ExtraMetadata returns the (base64-decoded) "extra_metadata".
+func (receiver ARC3MetaData) ExtraMetadata() opt.Optional[[]byte] {
+	return receiver.extraMetadata
+}
+
+func (receiver ARC3MetaData) ImageIntegrity() opt.Optional[string] {
+	return receiver.imageIntegrity
+}
+
+func (receiver ARC3MetaData) ImageMimeType() opt.Optional[string] {
+	return receiver.imageMimeType
+}
+
+// Property returns the (JSON of the) property named 'name' — not including the properties that are attributes.
+func (receiver ARC3MetaData) Property(name string) (json.RawMessage, bool) {
+	value, found := receiver.properties[name]
+	return value, found
+}
+
+func (receiver *ARC3MetaData) SetAnimationURLIntegrity(value string) {
+	receiver.animationURLIntegrity = opt.Something(value)
+}
+
+func (receiver *ARC3MetaData) SetAnimationURLMimeType(value string) {
+	receiver.animationURLMimeType = opt.Something(value)
+}
+
+func (receiver *ARC3MetaData) SetDecimals(value uint64) {
+	receiver.decimals = opt.Something(value)
+}
+
+func (receiver *ARC3MetaData) SetExternalURLIntegrity(value string) {
+	receiver.externalURLIntegrity = opt.Something(value)
+}
+
+func (receiver *ARC3MetaData) SetExternalURLMimeType(value string) {
+	receiver.externalURLMimeType = opt.Something(value)
+}
+
+// SetExtraMetadata sets the "extra_metadata". (It is base64-encoded when marshaled.)
+func (receiver *ARC3MetaData) SetExtraMetadata(value []byte) {
+	receiver.extraMetadata = opt.Something(append([]byte(nil), value...))
+}
+
+func (receiver *ARC3MetaData) SetImageIntegrity(value string) {
+	receiver.imageIntegrity = opt.Something(value)
+}
+
+func (receiver *ARC3MetaData) SetImageMimeType(value string) {
+	receiver.imageMimeType = opt.Something(value)
+}
+
+// SetProperty sets a "properties" entry to the JSON 'value'.
+//
+// SetProperty returns an error if 'value' is not valid JSON.
+//
+// (The attributes of the MetaData also become "properties" entries; so 'name' should not be the trait-type of an attribute.)
+func (receiver *ARC3MetaData) SetProperty(name string, value json.RawMessage) error {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, value); nil != err {
+		return erorr.Errorf("nftmeta: ARC-3 property %q is not valid JSON: %w", name, err)
+	}
+
+	if nil == receiver.properties {
+		receiver.properties = map[string]json.RawMessage{}
+	}
+
+	receiver.properties[name] = buffer.Bytes()
+	return nil
+}
@@ -0,0 +1,132 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestARC3MetaData_MarshalJSON(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("My Song")
+	metadata.SetDescription("My first and best song!")
+	metadata.SetImage("https://s3.amazonaws.com/your-bucket/song/cover/mysong.png")
+	metadata.SetExternalLink("https://mysongs.com/song/mysong")
+	metadata.AppendAttribute(nftmeta.AttributeString("Genre", "Pop"))
+	metadata.AppendAttribute(nftmeta.AttributeInt64("Track", 1))
+
+	var arc3 nftmeta.ARC3MetaData = nftmeta.ARC3MetaDataFrom(metadata)
+	arc3.SetDecimals(0)
+	arc3.SetImageIntegrity("sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+	arc3.SetImageMimeType("image/png")
+	if err := arc3.SetProperty("license", json.RawMessage(` { "name" : "CC-BY" } `)); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	expected := []byte(`{"description":"My first and best song!","external_url":"https://mysongs.com/song/mysong","image":"https://s3.amazonaws.com/your-bucket/song/cover/mysong.png","image_integrity":"sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=","image_mimetype":"image/png","name":"My Song","decimals":0,"properties":{"Genre":"Pop","Track":1,"license":{"name":"CC-BY"}}}`)
+
+	actual, err := json.Marshal(arc3)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual marshaled-json is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+		return
+	}
+
+	{
+		var parsed nftmeta.ARC3MetaData
+		if err := json.Unmarshal(actual, &parsed); nil != err {
+			t.Fatalf("Did not expect an error when unmarshaling but actually got one: (%T) %s", err, err)
+		}
+
+		again, err := json.Marshal(parsed)
+		if nil != err {
+			t.Fatalf("Did not expect an error when re-marshaling but actually got one: (%T) %s", err, err)
+		}
+
+		if !bytes.Equal(actual, again) {
+			t.Errorf("The re-marshaled json is not what was expected.")
+			t.Logf("EXPECTED: %s", actual)
+			t.Logf("ACTUAL:   %s", again)
+		}
+	}
+}
+
+func TestARC3MetadataHash(t *testing.T) {
+
+	// These were computed independently (with Python's hashlib SHA-512/256) — of "arc0003/amj" followed by the JSON, and, with "extra_metadata" (which is "iamextra", base64-encoded), of "arc0003/am" followed by that and then the extra metadata.
+	tests := []struct{
+		JSON     []byte
+		Expected string
+	}{
+		{
+			JSON:     []byte(`{"name":"My Song"}`),
+			Expected: "d0cf33c2ff54081ea4361b3cc3558e72803651d6f8948a2f60af0053dd14b25a",
+		},
+		{
+			JSON:     []byte(`{"extra_metadata":"aWFtZXh0cmE=","name":"My Song"}`),
+			Expected: "1a0eb273aab57bb82b1c9f922ac2748083467117d775dbf428f40a26e17eb9b2",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		hash, err := nftmeta.ARC3MetadataHash(test.JSON)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.Expected, hex.EncodeToString(hash[:]); expected != actual {
+			t.Errorf("For test #%d, the actual metadata hash is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
+
+func TestIntegritySHA256(t *testing.T) {
+
+	tests := []struct{
+		Content string
+		Expected string
+	}{
+		{
+			Content: "",
+			Expected: "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		},
+		{
+			Content: "hello",
+			Expected: "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := nftmeta.IntegritySHA256(strings.NewReader(test.Content))
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, the actual integrity is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
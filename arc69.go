@@ -0,0 +1,148 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// algorandMaxNoteLength is the maximum length (in bytes) of the note field of an Algorand transaction.
+const algorandMaxNoteLength = 1024
+
+// MarshalARC69 returns the Algorand ARC-69 metadata for 'metadata' — i.e., what goes in the note field of the (latest) asset-config transaction.
+//
+// For example:
+//
+//	{"standard":"arc69","description":"...","external_url":"...","media_url":"...","properties":{"Background":"Blue"}}
+//
+// The "description" is used; the "external_link" becomes the "external_url"; the "image" becomes the "media_url";
+// and each attribute becomes a "properties" entry of trait-type to value.
+// Nothing else of 'metadata' has an ARC-69 equivalent.
+//
+// MarshalARC69 returns an error if the result would not fit in a (1024 byte) note field.
+func MarshalARC69(metadata MetaData) ([]byte, error) {
+
+	var buffer [256]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, `{"standard":"arc69"`...)
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"description",  metadata.description},
+		{"external_url", metadata.externalLink},
+		{"media_url",    metadata.image},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		p = append(p, ',')
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	if 0 < len(metadata.attributes) {
+		var seen = map[string]struct{}{}
+
+		p = append(p, `,"properties":{`...)
+		for index, attribute := range metadata.attributes {
+			traitType, something := attribute.traitType.Get()
+			if !something {
+				return nil, errTraitTypeNothing
+			}
+			if _, found := seen[traitType]; found {
+				return nil, erorr.Errorf("nftmeta: duplicate trait-type %q cannot be put into ARC-69 properties", traitType)
+			}
+			seen[traitType] = struct{}{}
+
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			bytes, err := json.Marshal(traitType)
+			if nil != err {
+				return nil, erorr.Errorf("nftmeta: problem json-marshaling %T: %w", traitType, err)
+			}
+			p = append(p, bytes...)
+			p = append(p, ':')
+
+			p, err = appendAttributeValue(p, attribute.value)
+			if nil != err {
+				return nil, err
+			}
+		}
+		p = append(p, '}')
+	}
+
+	p = append(p, '}')
+
+	if algorandMaxNoteLength < len(p) {
+		return nil, erorr.Errorf("nftmeta: ARC-69 metadata is %d bytes long, but a note field can be at most %d bytes", len(p), algorandMaxNoteLength)
+	}
+
+	return p, nil
+}
+
+// UnmarshalARC69 returns the MetaData for the Algorand ARC-69 metadata 'note'.
+//
+// Each "properties" entry whose value is a string or a number becomes an attribute (ordered by trait-type); other "properties" entries are skipped.
+// An "attributes" array (which some ARC-69 metadata also has) is appended to those attributes.
+func UnmarshalARC69(note []byte) (MetaData, error) {
+	var raw struct {
+		Standard    *string                    `json:"standard"`
+		Description *string                    `json:"description"`
+		ExternalURL *string                    `json:"external_url"`
+		MediaURL    *string                    `json:"media_url"`
+		Properties  map[string]json.RawMessage `json:"properties"`
+		Attributes  []Attribute                `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(bytes.TrimSpace(note), &raw); nil != err {
+		return MetaData{}, erorr.Errorf("nftmeta: problem json-unmarshaling arc-69 metadata: %w", err)
+	}
+
+	if nil == raw.Standard || "arc69" != *raw.Standard {
+		return MetaData{}, errARC69NotARC69
+	}
+
+	var metadata MetaData
+
+	metadata.description  = optionalString(raw.Description)
+	metadata.externalLink = optionalString(raw.ExternalURL)
+	metadata.image        = optionalString(raw.MediaURL)
+
+	{
+		var names []string
+		for name := range raw.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value, err := unmarshalAttributeValue(raw.Properties[name])
+			if nil != err {
+				continue
+			}
+
+			metadata.attributes = append(metadata.attributes, Attribute{
+				traitType: opt.Something(name),
+				value:     value,
+			})
+		}
+	}
+
+	metadata.attributes = append(metadata.attributes, raw.Attributes...)
+
+	return metadata, nil
+}
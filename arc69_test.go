@@ -0,0 +1,92 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMarshalARC69(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("not in ARC-69")
+	metadata.SetDescription("Lake Tahoe in winter")
+	metadata.SetExternalLink("https://example.com/tahoe")
+	metadata.SetImage("ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+	metadata.AppendAttribute(nftmeta.AttributeString("Season", "Winter"))
+	metadata.AppendAttribute(nftmeta.AttributeInt64("Edition", 3))
+
+	expected := []byte(`{"standard":"arc69","description":"Lake Tahoe in winter","external_url":"https://example.com/tahoe","media_url":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","properties":{"Season":"Winter","Edition":3}}`)
+
+	actual, err := nftmeta.MarshalARC69(metadata)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual ARC-69 note is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+		return
+	}
+
+	{
+		parsed, err := nftmeta.UnmarshalARC69(actual)
+		if nil != err {
+			t.Fatalf("Did not expect an error when unmarshaling but actually got one: (%T) %s", err, err)
+		}
+
+		expected := []byte(`{"description":"Lake Tahoe in winter","external_link":"https://example.com/tahoe","image":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","attributes":[{"trait_type":"Edition","value":3},{"trait_type":"Season","value":"Winter"}]}`)
+
+		actual, err := json.Marshal(parsed)
+		if nil != err {
+			t.Fatalf("Did not expect an error when marshaling but actually got one: (%T) %s", err, err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("The actual unmarshaled metadata is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+}
+
+func TestMarshalARC69_tooLong(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetDescription(strings.Repeat("x", 1024))
+
+	if _, err := nftmeta.MarshalARC69(metadata); nil == err {
+		t.Errorf("Expected an error for an ARC-69 note longer than 1024 bytes, but did not actually get one.")
+	}
+}
+
+func TestUnmarshalARC69_fail(t *testing.T) {
+
+	tests := []struct{
+		Note string
+	}{
+		{
+			Note: `{}`,
+		},
+		{
+			Note: `{"standard":"arc3"}`,
+		},
+		{
+			Note: `not json`,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		if _, err := nftmeta.UnmarshalARC69([]byte(test.Note)); nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("NOTE: %s", test.Note)
+			continue
+		}
+	}
+}
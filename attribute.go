@@ -1,8 +1,10 @@
 package nftmeta
 
 import (
+	"bytes"
 	"encoding/json"
 	"math/big"
+	"sort"
 	"strconv"
 
 	"sourcecode.social/reiver/go-erorr"
@@ -131,47 +133,264 @@ func (receiver Attribute) MarshalJSON() ([]byte, error) {
 	{
 		p = append(p, `"value":`...)
 
-		value := receiver.value
-		if nil == value {
-			p = append(p, `nil`...)
-		} else {
-			switch casted := value.(type) {
-			case json.Marshaler:
-				bytes, err := casted.MarshalJSON()
-				if nil != err {
-					return nil, err
-				}
-				p = append(p, bytes...)
-			case string:
-				bytes, err := json.Marshal(casted)
-				if nil != err {
-					return nil, err
-				}
-				p = append(p, bytes...)
-			case int64:
-				p = append(p, strconv.FormatInt(casted, 10)...)
-			case uint64:
-				p = append(p, strconv.FormatUint(casted, 10)...)
-			case float64:
-				p = append(p, strconv.FormatFloat(casted, 'f', -1, 64)...)
-//			case *big.Int:
-//				if nil == casted {
-//					p = append(p, `null`...)
-//				} else {
-//					p = append(p, casted.String()...)
-//				}
-			case *big.Float:
-				if nil == casted {
-					p = append(p, `null`...)
-				} else {
-					p = append(p, casted.Text('f', -1)...)
-				}
-			default:
-				return nil, erorr.Errorf("nftmeta: cannot json-marshal something of type %T", value)
+		var err error
+		p, err = appendAttributeValue(p, receiver.value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+// appendAttributeValue appends the JSON of an attribute's value to 'p'.
+func appendAttributeValue(p []byte, value interface{}) ([]byte, error) {
+	if nil == value {
+		p = append(p, `nil`...)
+	} else {
+		switch casted := value.(type) {
+		case json.Marshaler:
+			bytes, err := casted.MarshalJSON()
+			if nil != err {
+				return nil, err
 			}
+			p = append(p, bytes...)
+		case string:
+			bytes, err := json.Marshal(casted)
+			if nil != err {
+				return nil, err
+			}
+			p = append(p, bytes...)
+		case int64:
+			p = append(p, strconv.FormatInt(casted, 10)...)
+		case uint64:
+			p = append(p, strconv.FormatUint(casted, 10)...)
+		case float64:
+			p = append(p, strconv.FormatFloat(casted, 'f', -1, 64)...)
+//		case *big.Int:
+//			if nil == casted {
+//				p = append(p, `null`...)
+//			} else {
+//				p = append(p, casted.String()...)
+//			}
+		case *big.Float:
+			if nil == casted {
+				p = append(p, `null`...)
+			} else {
+				p = append(p, casted.Text('f', -1)...)
+			}
+		default:
+			return nil, erorr.Errorf("nftmeta: cannot json-marshal something of type %T", value)
+		}
+	}
+
+	return p, nil
+}
+
+// DisplayType returns the "display_type" of the attribute, if there is one.
+func (receiver Attribute) DisplayType() opt.Optional[string] {
+	return receiver.displayType
+}
+
+// TraitType returns the "trait_type" of the attribute, if there is one.
+func (receiver Attribute) TraitType() opt.Optional[string] {
+	return receiver.traitType
+}
+
+// Value returns the "value" of the attribute.
+//
+// The returned value will be one of: string, int64, uint64, float64, *big.Int, or *big.Float.
+func (receiver Attribute) Value() interface{} {
+	return receiver.value
+}
+
+func (receiver *Attribute) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		DisplayType *string         `json:"display_type"`
+		TraitType   *string         `json:"trait_type"`
+		Value       json.RawMessage `json:"value"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling attribute: %w", err)
+	}
+
+	if nil == raw.TraitType {
+		return errTraitTypeNothing
+	}
+
+	value, err := unmarshalAttributeValue(raw.Value)
+	if nil != err {
+		return err
+	}
+
+	var attribute Attribute
+	if nil != raw.DisplayType {
+		attribute.displayType = opt.Something(*raw.DisplayType)
+	}
+	attribute.traitType = opt.Something(*raw.TraitType)
+	attribute.value = value
+
+	*receiver = attribute
+	return nil
+}
+
+// unmarshalAttributeValue turns the JSON of an attribute's "value" into one of the Go types that Attribute.MarshalJSON knows how to marshal.
+func unmarshalAttributeValue(data json.RawMessage) (interface{}, error) {
+	if len(data) <= 0 {
+		return nil, errAttributeValueMissing
+	}
+
+	var decoded interface{}
+	{
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&decoded); nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-unmarshaling attribute value: %w", err)
+		}
+	}
+
+	switch casted := decoded.(type) {
+	case string:
+		return casted, nil
+	case json.Number:
+		return parseAttributeNumber(casted.String())
+	default:
+		return nil, erorr.Errorf("nftmeta: cannot use JSON %s as an attribute value", data)
+	}
+}
+
+// parseAttributeNumber parses a JSON number into an int64, uint64, *big.Int, or float64 — in that order of preference.
+func parseAttributeNumber(str string) (interface{}, error) {
+	if i64, err := strconv.ParseInt(str, 10, 64); nil == err {
+		return i64, nil
+	}
+	if u64, err := strconv.ParseUint(str, 10, 64); nil == err {
+		return u64, nil
+	}
+	if bigint, ok := big.NewInt(0).SetString(str, 10); ok {
+		return bigint, nil
+	}
+	f64, err := strconv.ParseFloat(str, 64)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem parsing %q as an attribute number: %w", str, err)
+	}
+	return f64, nil
+}
+
+// attributeValueBigFloat returns the numeric value of an attribute's value as a *big.Float.
+// If the value is not numeric, then it returns false.
+func attributeValueBigFloat(value interface{}) (*big.Float, bool) {
+	switch casted := value.(type) {
+	case int64:
+		return big.NewFloat(0).SetInt64(casted), true
+	case uint64:
+		return big.NewFloat(0).SetUint64(casted), true
+	case float64:
+		return big.NewFloat(casted), true
+	case *big.Int:
+		if nil == casted {
+			return nil, false
 		}
+		return big.NewFloat(0).SetInt(casted), true
+	case *big.Float:
+		if nil == casted {
+			return nil, false
+		}
+		return big.NewFloat(0).Set(casted), true
+	default:
+		return nil, false
+	}
+}
+
+// unmarshalAttributes turns the JSON of "attributes" into attributes.
+//
+// It accepts either the array form — [{"trait_type":"Background","value":"Blue"}, ...] —
+// or the map form — {"Background":"Blue", ...} — whose attributes are ordered by trait-type (so that the result is deterministic).
+func unmarshalAttributes(data json.RawMessage) ([]Attribute, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) <= 0 {
+		return nil, nil
 	}
 
+	if '{' != data[0] {
+		var attributes []Attribute
+		if err := json.Unmarshal(data, &attributes); nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-unmarshaling attributes: %w", err)
+		}
+		return attributes, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-unmarshaling attributes: %w", err)
+	}
+
+	var traitTypes []string
+	for traitType := range m {
+		traitTypes = append(traitTypes, traitType)
+	}
+	sort.Strings(traitTypes)
+
+	var attributes []Attribute
+	for _, traitType := range traitTypes {
+		value, err := unmarshalAttributeValue(m[traitType])
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem with attribute %q: %w", traitType, err)
+		}
+
+		attributes = append(attributes, Attribute{
+			traitType: opt.Something(traitType),
+			value:     value,
+		})
+	}
+
+	return attributes, nil
+}
+
+// appendAttributeMap appends the map form of attributes, named 'name' — i.e., "<name>":{"<trait-type>":<value>, ...} — to 'p'.
+//
+// It returns an error if two attributes have the same trait-type.
+func appendAttributeMap(p []byte, name string, attributes []Attribute) ([]byte, error) {
+	var seen = map[string]struct{}{}
+
+	p = append(p, '"')
+	p = append(p, name...)
+	p = append(p, `":{`...)
+	for index, attribute := range attributes {
+		traitType, something := attribute.traitType.Get()
+		if !something {
+			return nil, errTraitTypeNothing
+		}
+		if _, found := seen[traitType]; found {
+			return nil, erorr.Errorf("nftmeta: duplicate trait-type %q cannot be put into the map form of attributes", traitType)
+		}
+		seen[traitType] = struct{}{}
+
+		if 0 < index {
+			p = append(p, ',')
+		}
+
+		bytes, err := json.Marshal(traitType)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling %T: %w", traitType, err)
+		}
+		p = append(p, bytes...)
+		p = append(p, ':')
+
+		p, err = appendAttributeValue(p, attribute.value)
+		if nil != err {
+			return nil, err
+		}
+	}
 	p = append(p, '}')
 
 	return p, nil
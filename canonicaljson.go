@@ -0,0 +1,290 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// MarshalCanonicalJSON is like MarshalJSON, but returns the canonical form of the JSON — as the JSON Canonicalization Scheme (RFC 8785) defines it.
+// So anyone (with any JCS implementation) gets exactly the same bytes from the same metadata — which is what is needed to hash it reproducibly.
+//
+// In short: there is no whitespace, the names of each object are sorted (by their UTF-16 code units), strings are only escaped where they have to be, and numbers are formatted the way ECMAScript formats them.
+func (receiver MetaData) MarshalCanonicalJSON() ([]byte, error) {
+	data, err := receiver.MarshalJSON()
+	if nil != err {
+		return nil, err
+	}
+
+	return canonicalJSON(data)
+}
+
+// canonicalJSON returns the JCS (RFC 8785) form of the JSON 'data'.
+//
+// As JCS requires (of its input, which must be I-JSON, RFC 7493), it returns an error if an object has duplicate names, if there is invalid UTF-8, or if a string has an unpaired surrogate escape (such as "\ud800").
+// Otherwise, different JSON — which different readers could read differently — would have the same canonical form.
+func canonicalJSON(data []byte) ([]byte, error) {
+	if err := checkCanonicalJSONStrings(data); nil != err {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := decodeCanonicalJSON(decoder)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-unmarshaling for canonicalization: %w", err)
+	}
+	if _, err := decoder.Token(); io.EOF != err {
+		return nil, errCanonicalJSONTrailingData
+	}
+
+	return appendCanonicalJSON(nil, value)
+}
+
+// decodeCanonicalJSON decodes the next JSON value from 'decoder' (which must UseNumber) — token by token, so that it can return an error if an object has duplicate names, rather than keeping the last of them.
+func decodeCanonicalJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if nil != err {
+		return nil, err
+	}
+
+	delim, isDelim := token.(json.Delim)
+	if !isDelim {
+		return token, nil
+	}
+
+	switch delim {
+	case '[':
+		var array = []any{}
+		for decoder.More() {
+			element, err := decodeCanonicalJSON(decoder)
+			if nil != err {
+				return nil, err
+			}
+			array = append(array, element)
+		}
+		if _, err := decoder.Token(); nil != err {
+			return nil, err
+		}
+		return array, nil
+	case '{':
+		var object = map[string]any{}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if nil != err {
+				return nil, err
+			}
+			name, _ := token.(string)
+			if _, found := object[name]; found {
+				return nil, erorr.Errorf("nftmeta: JSON object has more than one %q", name)
+			}
+
+			value, err := decodeCanonicalJSON(decoder)
+			if nil != err {
+				return nil, err
+			}
+			object[name] = value
+		}
+		if _, err := decoder.Token(); nil != err {
+			return nil, err
+		}
+		return object, nil
+	default:
+		return nil, erorr.Errorf("nftmeta: unexpected JSON %q", delim)
+	}
+}
+
+// checkCanonicalJSONStrings returns an error if the JSON 'data' is not valid UTF-8, or if any of its strings has an unpaired surrogate escape — both of which encoding/json would (silently) replace with U+FFFD.
+//
+// It only looks at the strings of 'data' — whether the rest of it is valid JSON is left to the decoder.
+func checkCanonicalJSONStrings(data []byte) error {
+	if !utf8.Valid(data) {
+		return errCanonicalJSONBadUTF8
+	}
+
+	isHex := func(b byte) bool {
+		return ('0' <= b && b <= '9') || ('a' <= b && b <= 'f') || ('A' <= b && b <= 'F')
+	}
+	// escapedRune returns the rune of the "\uXXXX" escape at the beginning of 'p' — or -1 if 'p' does not begin with one.
+	escapedRune := func(p []byte) rune {
+		if len(p) < 6 || '\\' != p[0] || 'u' != p[1] {
+			return -1
+		}
+		for _, b := range p[2:6] {
+			if !isHex(b) {
+				return -1
+			}
+		}
+		r, _ := strconv.ParseUint(string(p[2:6]), 16, 16)
+		return rune(r)
+	}
+
+	var inString bool
+	for index := 0; index < len(data); index++ {
+		switch b := data[index]; {
+		case '"' == b:
+			inString = !inString
+		case inString && '\\' == b:
+			r := escapedRune(data[index:])
+			switch {
+			case utf16.IsSurrogate(r) && r < 0xDC00:
+				if next := escapedRune(data[index+6:]); next < 0xDC00 || 0xDFFF < next {
+					return erorr.Errorf("nftmeta: JSON string has an unpaired surrogate escape %s", data[index:index+6])
+				}
+				// Skip the (high-surrogate) escape and the (low-surrogate) escape.
+				index += 11
+			case utf16.IsSurrogate(r):
+				return erorr.Errorf("nftmeta: JSON string has an unpaired surrogate escape %s", data[index:index+6])
+			default:
+				// Skip the escaped character, so that an escaped '"' does not end the string.
+				index++
+			}
+		}
+	}
+
+	return nil
+}
+
+func appendCanonicalJSON(p []byte, value any) ([]byte, error) {
+	switch casted := value.(type) {
+	case nil:
+		return append(p, "null"...), nil
+	case bool:
+		if casted {
+			return append(p, "true"...), nil
+		}
+		return append(p, "false"...), nil
+	case json.Number:
+		f, err := strconv.ParseFloat(casted.String(), 64)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: cannot canonicalize JSON number %s: %w", casted, err)
+		}
+		number, err := canonicalJSONNumber(f)
+		if nil != err {
+			return nil, err
+		}
+		return append(p, number...), nil
+	case string:
+		return appendCanonicalJSONString(p, casted), nil
+	case []any:
+		p = append(p, '[')
+		for index, element := range casted {
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			var err error
+			p, err = appendCanonicalJSON(p, element)
+			if nil != err {
+				return nil, err
+			}
+		}
+		return append(p, ']'), nil
+	case map[string]any:
+		var names []string
+		for name := range casted {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return lessUTF16(names[i], names[j])
+		})
+
+		p = append(p, '{')
+		for index, name := range names {
+			if 0 < index {
+				p = append(p, ',')
+			}
+			p = appendCanonicalJSONString(p, name)
+			p = append(p, ':')
+
+			var err error
+			p, err = appendCanonicalJSON(p, casted[name])
+			if nil != err {
+				return nil, err
+			}
+		}
+		return append(p, '}'), nil
+	default:
+		return nil, erorr.Errorf("nftmeta: cannot canonicalize JSON value of type %T", value)
+	}
+}
+
+// canonicalJSONNumber formats 'f' the way ECMAScript's Number.prototype.toString does (which is what JCS uses).
+func canonicalJSONNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", erorr.Errorf("nftmeta: JSON cannot have the number %v", f)
+	}
+	if 0 == f {
+		// Including negative zero.
+		return "0", nil
+	}
+
+	var sign string
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	if 1e-6 <= f && f < 1e21 {
+		return sign + strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// Go gives exponents (at least) 2 digits (e.g., "1e-07"), but ECMAScript does not (e.g., "1e-7").
+	str := strconv.FormatFloat(f, 'e', -1, 64)
+	if index := strings.IndexByte(str, 'e'); 0 <= index && index+2 < len(str) && '0' == str[index+2] {
+		str = str[:index+2] + str[index+3:]
+	}
+	return sign + str, nil
+}
+
+// appendCanonicalJSONString appends the JCS form of the string 'str' — which only escapes '"', '\\', and the control characters.
+func appendCanonicalJSONString(p []byte, str string) []byte {
+	const hexDigits = "0123456789abcdef"
+
+	p = append(p, '"')
+	for _, r := range str {
+		switch {
+		case '"' == r:
+			p = append(p, `\"`...)
+		case '\\' == r:
+			p = append(p, `\\`...)
+		case '\b' == r:
+			p = append(p, `\b`...)
+		case '\f' == r:
+			p = append(p, `\f`...)
+		case '\n' == r:
+			p = append(p, `\n`...)
+		case '\r' == r:
+			p = append(p, `\r`...)
+		case '\t' == r:
+			p = append(p, `\t`...)
+		case r < 0x20:
+			p = append(p, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xF])
+		default:
+			p = utf8.AppendRune(p, r)
+		}
+	}
+	return append(p, '"')
+}
+
+// lessUTF16 returns whether 'a' comes before 'b', comparing their UTF-16 code units (which is how JCS sorts names).
+func lessUTF16(a string, b string) bool {
+	a16 := utf16.Encode([]rune(a))
+	b16 := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(a16) && i < len(b16); i++ {
+		if a16[i] != b16[i] {
+			return a16[i] < b16[i]
+		}
+	}
+	return len(a16) < len(b16)
+}
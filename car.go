@@ -0,0 +1,729 @@
+package nftmeta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+const cborTagCID = 42
+
+// carMaxSectionSize is the largest (header or block) section of a CAR file that is read — which is bigger than any IPFS block (and so any block made here) can be.
+const carMaxSectionSize = 4 << 20
+
+// carMaxFileSize is the largest (UnixFS) file that is read from a CAR file.
+// Since the DAG of a file can link to the same block any number of times, a small CAR file could otherwise hold an (exponentially) huge file.
+const carMaxFileSize = 16 << 20
+
+// carMaxFileNodes is the most nodes of the DAG of a (UnixFS) file that are walked, when reading it from a CAR file — for the same reason as carMaxFileSize, since those nodes could be empty.
+const carMaxFileNodes = 1 << 16
+
+// carMaxDirectoryNodes is the most nodes (of the directory, and of all of its files together) that are walked, when reading a (UnixFS) directory from a CAR file.
+const carMaxDirectoryNodes = 1 << 20
+
+// carMaxDirectoryEntries is the most entries of a (UnixFS) directory that are read from a CAR file.
+const carMaxDirectoryEntries = 1 << 20
+
+// carMaxDirectorySize is the most content (of all of its files together) of a (UnixFS) directory that is read from a CAR file.
+// Since any number of entries can link to the same file, this is much less than carMaxDirectoryEntries times carMaxFileSize.
+const carMaxDirectorySize = 256 << 20
+
+// WriteCARv0 writes a CARv1 file, holding the JSON of the metadata (see MarshalJSON) as `ipfs add` would add it, to 'writer'.
+// The root of the CAR file is the CIDv0 of the JSON, which is also returned.
+//
+// See IPFSCIDv0.
+func (receiver MetaData) WriteCARv0(writer io.Writer) (string, error) {
+	return receiver.writeCAR(writer, unixfsOptions{cidVersion: 0})
+}
+
+// WriteCARv1 is like WriteCARv0, but for the CIDv1 of the JSON.
+//
+// See IPFSCIDv1.
+func (receiver MetaData) WriteCARv1(writer io.Writer, rawLeaves bool) (string, error) {
+	return receiver.writeCAR(writer, unixfsOptions{cidVersion: 1, rawLeaves: rawLeaves})
+}
+
+func (receiver MetaData) writeCAR(writer io.Writer, options unixfsOptions) (string, error) {
+	data, err := receiver.MarshalJSON()
+	if nil != err {
+		return "", err
+	}
+
+	return writeUnixFSCAR(writer, func(emit func(ipfsBlock)) (unixfsNode, error) {
+		return unixfsFile(data, options, emit), nil
+	})
+}
+
+// WriteCARv0 writes a CARv1 file, holding the directory as `ipfs add -r` would add it, to 'writer'.
+// The root of the CAR file is the CIDv0 of the directory, which is also returned.
+//
+// See CIDv0.
+func (receiver CollectionDirectory) WriteCARv0(writer io.Writer) (string, error) {
+	return receiver.writeCAR(writer, unixfsOptions{cidVersion: 0})
+}
+
+// WriteCARv1 is like WriteCARv0, but for the CIDv1 of the directory.
+//
+// See CIDv1.
+func (receiver CollectionDirectory) WriteCARv1(writer io.Writer, rawLeaves bool) (string, error) {
+	return receiver.writeCAR(writer, unixfsOptions{cidVersion: 1, rawLeaves: rawLeaves})
+}
+
+func (receiver CollectionDirectory) writeCAR(writer io.Writer, options unixfsOptions) (string, error) {
+	files, err := receiver.Files()
+	if nil != err {
+		return "", err
+	}
+
+	return writeUnixFSCAR(writer, func(emit func(ipfsBlock)) (unixfsNode, error) {
+		return unixfsDirectoryOfFiles(files, options, emit)
+	})
+}
+
+// writeUnixFSCAR writes a CARv1 file of the UnixFS DAG that 'build' builds, and returns its root CID.
+//
+// The root block is written first, and each block is written once (even if it is in the DAG more than once).
+func writeUnixFSCAR(writer io.Writer, build func(emit func(ipfsBlock)) (unixfsNode, error)) (string, error) {
+	if nil == writer {
+		return "", errNilWriter
+	}
+
+	var blocks []ipfsBlock
+	root, err := build(func(block ipfsBlock) {
+		blocks = append(blocks, block)
+	})
+	if nil != err {
+		return "", err
+	}
+
+	var p []byte
+
+	// The header is the DAG-CBOR of {"roots":[<root>],"version":1}.
+	{
+		var header []byte
+		header = cborAppendHead(header, cborMajorMap, 2)
+		header = cborAppendHead(header, cborMajorText, uint64(len("roots")))
+		header = append(header, "roots"...)
+		header = cborAppendHead(header, cborMajorArray, 1)
+		header = cborAppendHead(header, cborMajorTag, cborTagCID)
+		header = cborAppendHead(header, cborMajorBytes, uint64(1+len(root.cid.bytes())))
+		header = append(header, 0x00) // the multibase prefix for "identity" (i.e., binary).
+		header = append(header, root.cid.bytes()...)
+		header = cborAppendHead(header, cborMajorText, uint64(len("version")))
+		header = append(header, "version"...)
+		header = cborAppendHead(header, cborMajorUnsigned, 1)
+
+		p = binary.AppendUvarint(p, uint64(len(header)))
+		p = append(p, header...)
+	}
+
+	// The blocks were emitted children-before-parents, so they are written in reverse to put the root first.
+	var written = map[string]struct{}{}
+	for index := len(blocks) - 1; 0 <= index; index-- {
+		block := blocks[index]
+
+		key := string(block.cid.bytes())
+		if _, found := written[key]; found {
+			continue
+		}
+		written[key] = struct{}{}
+
+		p = binary.AppendUvarint(p, uint64(len(key)+len(block.data)))
+		p = append(p, key...)
+		p = append(p, block.data...)
+	}
+
+	if _, err := writer.Write(p); nil != err {
+		return "", erorr.Errorf("nftmeta: problem writing CAR: %w", err)
+	}
+
+	return root.cid.String(), nil
+}
+
+// ReadMetaDataCAR reads a CARv1 file (such as what MetaData's WriteCARv0 and WriteCARv1 write) from 'reader', whose root is a UnixFS file of NFT metadata JSON.
+// It returns the metadata, and the root CID.
+//
+// Each block is checked against its CID.
+func ReadMetaDataCAR(reader io.Reader) (MetaData, string, error) {
+	car, err := readCAR(reader)
+	if nil != err {
+		return MetaData{}, "", err
+	}
+
+	var budget = carBudget{nodes: carMaxFileNodes, size: carMaxFileSize}
+
+	data, err := car.unixfsFileContent(car.root, &budget)
+	if nil != err {
+		return MetaData{}, "", err
+	}
+
+	var metadata MetaData
+	if err := metadata.UnmarshalJSON(data); nil != err {
+		return MetaData{}, "", err
+	}
+
+	return metadata, car.root.String(), nil
+}
+
+// ReadCollectionDirectoryCAR reads a CARv1 file (such as what CollectionDirectory's WriteCARv0 and WriteCARv1 write) from 'reader', whose root is a UnixFS directory of NFT metadata JSON files.
+// It returns the metadata of each file — keyed by file name — and the root CID.
+//
+// Each block is checked against its CID. The directory may be HAMT-sharded.
+//
+// It returns an error if the directory has duplicate file names, if it has more than carMaxDirectoryEntries files, or if (all of) its files together are bigger than carMaxDirectorySize.
+func ReadCollectionDirectoryCAR(reader io.Reader) (map[string]MetaData, string, error) {
+	car, err := readCAR(reader)
+	if nil != err {
+		return nil, "", err
+	}
+
+	var budget = carBudget{nodes: carMaxDirectoryNodes, entries: carMaxDirectoryEntries, size: carMaxDirectorySize}
+
+	entries, err := car.unixfsDirectoryEntries(car.root, &budget)
+	if nil != err {
+		return nil, "", err
+	}
+
+	var collection = map[string]MetaData{}
+	for _, entry := range entries {
+		data, err := car.unixfsFileContent(entry.cid, &budget)
+		if nil != err {
+			return nil, "", erorr.Errorf("nftmeta: problem reading file %q from CAR: %w", entry.name, err)
+		}
+
+		var metadata MetaData
+		if err := metadata.UnmarshalJSON(data); nil != err {
+			return nil, "", erorr.Errorf("nftmeta: problem json-unmarshaling file %q from CAR: %w", entry.name, err)
+		}
+
+		collection[entry.name] = metadata
+	}
+
+	return collection, car.root.String(), nil
+}
+
+// carBudget is what is left of the limits on what is read from a CAR file.
+//
+// One budget is shared by everything read for a single call (such as all the files of a directory) — since the DAG can link to the same blocks any number of times, limits on each file alone would not bound the whole.
+type carBudget struct {
+	nodes   int // How many more nodes can be walked.
+	entries int // How many more directory entries can be read.
+	size    int // How many more bytes of file content can be read.
+}
+
+// spendNode spends a node of the budget — or returns an error if there are none left.
+func (receiver *carBudget) spendNode() error {
+	if receiver.nodes <= 0 {
+		return erorr.Errorf("nftmeta: CAR has too many nodes to read")
+	}
+	receiver.nodes--
+	return nil
+}
+
+// spendEntry spends a directory entry of the budget — or returns an error if there are none left.
+func (receiver *carBudget) spendEntry() error {
+	if receiver.entries <= 0 {
+		return erorr.Errorf("nftmeta: CAR directory has more than %d entries", carMaxDirectoryEntries)
+	}
+	receiver.entries--
+	return nil
+}
+
+// spendSize spends 'size' bytes of file content of the budget — or returns an error if there are not that many left.
+func (receiver *carBudget) spendSize(size int) error {
+	if receiver.size < size {
+		return erorr.Errorf("nftmeta: CAR has too much file content to read")
+	}
+	receiver.size -= size
+	return nil
+}
+
+// carFile is a CARv1 file that has been read.
+type carFile struct {
+	root   cid
+	blocks map[string][]byte // keyed by the binary CID.
+}
+
+// readCAR reads a CARv1 file with a single root, checking each block against its CID.
+func readCAR(reader io.Reader) (carFile, error) {
+	if nil == reader {
+		return carFile{}, errNilReader
+	}
+
+	var bufferedReader = bufio.NewReader(reader)
+
+	var car = carFile{blocks: map[string][]byte{}}
+
+	{
+		header, err := readCARSection(bufferedReader)
+		if nil != err {
+			return carFile{}, erorr.Errorf("nftmeta: problem reading CAR header: %w", err)
+		}
+		if nil == header {
+			return carFile{}, errCARHeaderMissing
+		}
+
+		car.root, err = parseCARHeader(header)
+		if nil != err {
+			return carFile{}, err
+		}
+	}
+
+	for {
+		section, err := readCARSection(bufferedReader)
+		if nil != err {
+			return carFile{}, erorr.Errorf("nftmeta: problem reading CAR block: %w", err)
+		}
+		if nil == section {
+			break
+		}
+
+		blockCID, data, err := parseCARBlock(section)
+		if nil != err {
+			return carFile{}, err
+		}
+
+		car.blocks[string(blockCID.bytes())] = data
+	}
+
+	return car, nil
+}
+
+// readCARSection reads a section — i.e., a varint length followed by that many bytes.
+// It returns nil (and no error) at the end of the CAR file.
+func readCARSection(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if io.EOF == err {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, err
+	}
+	if 0 == length || carMaxSectionSize < length {
+		return nil, erorr.Errorf("nftmeta: CAR section length %d is not between 1 and %d", length, carMaxSectionSize)
+	}
+
+	section := make([]byte, length)
+	if _, err := io.ReadFull(reader, section); nil != err {
+		return nil, err
+	}
+	return section, nil
+}
+
+// parseCARHeader parses the DAG-CBOR header of a CARv1 file, and returns its (only) root.
+func parseCARHeader(header []byte) (cid, error) {
+	var decoder = cborDecoder{data: header}
+
+	major, count, indefinite, err := decoder.head()
+	if nil != err {
+		return cid{}, err
+	}
+	if cborMajorMap != major || indefinite {
+		return cid{}, errCARBadHeader
+	}
+
+	var roots []cid
+	var version uint64
+	for i := uint64(0); i < count; i++ {
+		major, length, indefinite, err := decoder.head()
+		if nil != err {
+			return cid{}, err
+		}
+		if cborMajorText != major || indefinite {
+			return cid{}, errCARBadHeader
+		}
+		key, err := decoder.bytes(length, false)
+		if nil != err {
+			return cid{}, err
+		}
+
+		switch string(key) {
+		case "version":
+			major, argument, _, err := decoder.head()
+			if nil != err {
+				return cid{}, err
+			}
+			if cborMajorUnsigned != major {
+				return cid{}, errCARBadHeader
+			}
+			version = argument
+		case "roots":
+			major, length, indefinite, err := decoder.head()
+			if nil != err {
+				return cid{}, err
+			}
+			if cborMajorArray != major || indefinite {
+				return cid{}, errCARBadHeader
+			}
+
+			for j := uint64(0); j < length; j++ {
+				major, tag, _, err := decoder.head()
+				if nil != err {
+					return cid{}, err
+				}
+				if cborMajorTag != major || cborTagCID != tag {
+					return cid{}, errCARBadHeader
+				}
+
+				major, length, indefinite, err := decoder.head()
+				if nil != err {
+					return cid{}, err
+				}
+				if cborMajorBytes != major || indefinite {
+					return cid{}, errCARBadHeader
+				}
+				p, err := decoder.bytes(length, false)
+				if nil != err {
+					return cid{}, err
+				}
+				if len(p) < 1 || 0x00 != p[0] {
+					return cid{}, errCARBadHeader
+				}
+
+				root, err := cidFromBytes(p[1:])
+				if nil != err {
+					return cid{}, erorr.Errorf("nftmeta: CAR header has a bad root: %w", err)
+				}
+				roots = append(roots, root)
+			}
+		default:
+			return cid{}, erorr.Errorf("nftmeta: CAR header has unexpected key %q", key)
+		}
+	}
+
+	if 1 != version {
+		return cid{}, erorr.Errorf("nftmeta: CAR version is %d rather than 1", version)
+	}
+	if 1 != len(roots) {
+		return cid{}, erorr.Errorf("nftmeta: CAR has %d roots rather than 1", len(roots))
+	}
+
+	return roots[0], nil
+}
+
+// parseCARBlock parses a block section of a CARv1 file — i.e., a binary CID followed by the data of the block — checking the data against the CID.
+func parseCARBlock(section []byte) (cid, []byte, error) {
+	var length int
+	if 2 <= len(section) && multihashSHA2_256 == section[0] && 32 == section[1] {
+		length = 34
+	} else {
+		// A CIDv1 is version, codec, multihash-code, multihash-length, and then the digest.
+		rest := section
+		var n uint64
+		for i := 0; i < 4; i++ {
+			var err error
+			n, rest, err = readUvarint(rest)
+			if nil != err {
+				return cid{}, nil, err
+			}
+		}
+		if uint64(len(rest)) < n {
+			return cid{}, nil, errCARBadBlock
+		}
+		length = len(section) - len(rest) + int(n)
+	}
+	if len(section) < length {
+		return cid{}, nil, errCARBadBlock
+	}
+
+	blockCID, err := cidFromBytes(section[:length])
+	if nil != err {
+		return cid{}, nil, err
+	}
+	data := section[length:]
+
+	expected, err := blockCID.sha256Digest()
+	if nil != err {
+		return cid{}, nil, erorr.Errorf("nftmeta: cannot check CAR block %s: %w", blockCID, err)
+	}
+	if actual := sha256.Sum256(data); !bytes.Equal(expected, actual[:]) {
+		return cid{}, nil, erorr.Errorf("nftmeta: CAR block %s does not match its CID", blockCID)
+	}
+
+	return blockCID, data, nil
+}
+
+func (receiver carFile) block(c cid) ([]byte, error) {
+	data, found := receiver.blocks[string(c.bytes())]
+	if !found {
+		return nil, erorr.Errorf("nftmeta: CAR is missing block %s", c)
+	}
+	return data, nil
+}
+
+// unixfsFileContent returns the content of the UnixFS file whose root is 'c'.
+// It returns an error if the file is bigger than carMaxFileSize, if its DAG has more than carMaxFileNodes nodes, or if it goes over 'budget'.
+func (receiver carFile) unixfsFileContent(c cid, budget *carBudget) ([]byte, error) {
+	var nodes int
+	return receiver.appendUnixFSFileContent(nil, c, &nodes, budget)
+}
+
+// appendUnixFSFileContent appends the content of the UnixFS file (or part of a file) whose root is 'c' to 'content'.
+// 'nodes' is how many nodes (of this file) have been walked so far.
+func (receiver carFile) appendUnixFSFileContent(content []byte, c cid, nodes *int, budget *carBudget) ([]byte, error) {
+	*nodes++
+	if carMaxFileNodes < *nodes {
+		return nil, erorr.Errorf("nftmeta: CAR file has more than %d nodes", carMaxFileNodes)
+	}
+	if err := budget.spendNode(); nil != err {
+		return nil, err
+	}
+
+	data, err := receiver.block(c)
+	if nil != err {
+		return nil, err
+	}
+
+	if multicodecRaw == c.codec {
+		return appendCARFileContent(content, data, budget)
+	}
+	if multicodecDagPB != c.codec {
+		return nil, erorr.Errorf("nftmeta: CAR block %s is neither raw nor dag-pb", c)
+	}
+
+	node, err := parseDAGPBNode(data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem parsing CAR block %s: %w", c, err)
+	}
+	unixfsData, err := parseUnixFSData(node.data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem parsing CAR block %s: %w", c, err)
+	}
+	if unixfsTypeFile != unixfsData.dataType && unixfsTypeRaw != unixfsData.dataType {
+		return nil, erorr.Errorf("nftmeta: CAR block %s is a UnixFS type-%d node rather than a file", c, unixfsData.dataType)
+	}
+
+	if carMaxFileSize < unixfsData.filesize {
+		return nil, erorr.Errorf("nftmeta: CAR file is %d bytes, which is bigger than %d bytes", unixfsData.filesize, carMaxFileSize)
+	}
+
+	content, err = appendCARFileContent(content, unixfsData.data, budget)
+	if nil != err {
+		return nil, err
+	}
+	for _, link := range node.links {
+		content, err = receiver.appendUnixFSFileContent(content, link.cid, nodes, budget)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// appendCARFileContent appends 'data' to 'content' — unless that would make it bigger than carMaxFileSize, or go over 'budget'.
+func appendCARFileContent(content []byte, data []byte, budget *carBudget) ([]byte, error) {
+	if carMaxFileSize-len(content) < len(data) {
+		return nil, erorr.Errorf("nftmeta: CAR file is bigger than %d bytes", carMaxFileSize)
+	}
+	if err := budget.spendSize(len(data)); nil != err {
+		return nil, err
+	}
+	return append(content, data...), nil
+}
+
+// unixfsDirectoryEntries returns the entries of the UnixFS directory (which may be HAMT-sharded) whose root is 'c'.
+//
+// It returns an error if two entries have the same name, if a HAMT shard is linked to more than once, or if it goes over 'budget'.
+func (receiver carFile) unixfsDirectoryEntries(c cid, budget *carBudget) ([]dagPBLink, error) {
+	var names   = map[string]struct{}{}
+	var visited = map[string]struct{}{}
+
+	return receiver.appendUnixFSDirectoryEntries(nil, c, names, visited, budget)
+}
+
+// appendUnixFSDirectoryEntries appends the entries of the UnixFS directory (or HAMT shard) whose root is 'c' to 'entries'.
+// 'names' are the names of the entries so far, and 'visited' are the (binary) CIDs of the directory nodes walked so far.
+func (receiver carFile) appendUnixFSDirectoryEntries(entries []dagPBLink, c cid, names map[string]struct{}, visited map[string]struct{}, budget *carBudget) ([]dagPBLink, error) {
+	if _, found := visited[string(c.bytes())]; found {
+		return nil, erorr.Errorf("nftmeta: CAR directory links to block %s more than once", c)
+	}
+	visited[string(c.bytes())] = struct{}{}
+
+	if err := budget.spendNode(); nil != err {
+		return nil, err
+	}
+
+	data, err := receiver.block(c)
+	if nil != err {
+		return nil, err
+	}
+	if multicodecDagPB != c.codec {
+		return nil, erorr.Errorf("nftmeta: CAR block %s is not dag-pb, so is not a directory", c)
+	}
+
+	node, err := parseDAGPBNode(data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem parsing CAR block %s: %w", c, err)
+	}
+	unixfsData, err := parseUnixFSData(node.data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem parsing CAR block %s: %w", c, err)
+	}
+
+	appendEntry := func(entry dagPBLink) error {
+		if _, found := names[entry.name]; found {
+			return erorr.Errorf("nftmeta: CAR directory has more than one entry named %q", entry.name)
+		}
+		if err := budget.spendEntry(); nil != err {
+			return err
+		}
+
+		names[entry.name] = struct{}{}
+		entries = append(entries, entry)
+		return nil
+	}
+
+	switch unixfsData.dataType {
+	case unixfsTypeDirectory:
+		for _, link := range node.links {
+			if err := appendEntry(link); nil != err {
+				return nil, err
+			}
+		}
+		return entries, nil
+	case unixfsTypeHAMTShard:
+		if unixfsData.fanout < 2 || math.MaxInt32 < unixfsData.fanout {
+			return nil, erorr.Errorf("nftmeta: CAR block %s is a HAMT shard with a bad fanout %d", c, unixfsData.fanout)
+		}
+
+		// The link names of a HAMT shard begin with the index, as (upper-case, zero-padded) hexadecimal.
+		var prefixLength int
+		for n := unixfsData.fanout - 1; 0 < n; n >>= 4 {
+			prefixLength++
+		}
+
+		for _, link := range node.links {
+			switch {
+			case len(link.name) < prefixLength:
+				return nil, erorr.Errorf("nftmeta: CAR block %s is a HAMT shard with a bad link name %q", c, link.name)
+			case len(link.name) == prefixLength:
+				entries, err = receiver.appendUnixFSDirectoryEntries(entries, link.cid, names, visited, budget)
+				if nil != err {
+					return nil, err
+				}
+			default:
+				link.name = link.name[prefixLength:]
+				if err := appendEntry(link); nil != err {
+					return nil, err
+				}
+			}
+		}
+		return entries, nil
+	default:
+		return nil, erorr.Errorf("nftmeta: CAR block %s is a UnixFS type-%d node rather than a directory", c, unixfsData.dataType)
+	}
+}
+
+// dagPBNodeParsed is a parsed dag-pb PBNode.
+type dagPBNodeParsed struct {
+	links []dagPBLink
+	data  []byte
+}
+
+func parseDAGPBNode(p []byte) (dagPBNodeParsed, error) {
+	var node dagPBNodeParsed
+
+	err := parseProtobuf(p, func(field uint64, _ uint64, value []byte) error {
+		switch field {
+		case 1:
+			node.data = value
+		case 2:
+			var link dagPBLink
+			err := parseProtobuf(value, func(field uint64, number uint64, value []byte) error {
+				switch field {
+				case 1:
+					var err error
+					link.cid, err = cidFromBytes(value)
+					return err
+				case 2:
+					link.name = string(value)
+				case 3:
+					link.tsize = number
+				}
+				return nil
+			})
+			if nil != err {
+				return err
+			}
+			node.links = append(node.links, link)
+		}
+		return nil
+	})
+
+	return node, err
+}
+
+// unixfsDataParsed is parsed UnixFS data (i.e., the data of a dag-pb node of a UnixFS DAG).
+type unixfsDataParsed struct {
+	dataType uint64
+	data     []byte
+	filesize uint64
+	fanout   uint64
+}
+
+func parseUnixFSData(p []byte) (unixfsDataParsed, error) {
+	var parsed unixfsDataParsed
+
+	err := parseProtobuf(p, func(field uint64, number uint64, value []byte) error {
+		switch field {
+		case 1:
+			parsed.dataType = number
+		case 2:
+			parsed.data = value
+		case 3:
+			parsed.filesize = number
+		case 6:
+			parsed.fanout = number
+		}
+		return nil
+	})
+
+	return parsed, err
+}
+
+// parseProtobuf calls 'fn' with each field of the protobuf message 'p' — with the value of a varint field as 'number', and the value of a length-delimited field as 'value'.
+func parseProtobuf(p []byte, fn func(field uint64, number uint64, value []byte) error) error {
+	for 0 < len(p) {
+		key, rest, err := readUvarint(p)
+		if nil != err {
+			return err
+		}
+		p = rest
+
+		field := key >> 3
+		switch key & 7 {
+		case 0:
+			number, rest, err := readUvarint(p)
+			if nil != err {
+				return err
+			}
+			p = rest
+
+			if err := fn(field, number, nil); nil != err {
+				return err
+			}
+		case 2:
+			length, rest, err := readUvarint(p)
+			if nil != err {
+				return err
+			}
+			if uint64(len(rest)) < length {
+				return errProtobufUnexpectedEnd
+			}
+			p = rest[length:]
+
+			if err := fn(field, 0, rest[:length]); nil != err {
+				return err
+			}
+		default:
+			return erorr.Errorf("nftmeta: unsupported protobuf wire-type %d", key&7)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,522 @@
+package nftmeta_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMetaData_WriteCARv0(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("x")
+
+	var buffer bytes.Buffer
+	root, err := metadata.WriteCARv0(&buffer)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := "QmSJMNQavSyZn8eea5zvg8sD8M85iTwajjLXMrXEuZU2PN", root; expected != actual {
+		t.Errorf("The actual root is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	// The header {"roots":[<root>],"version":1}, and then the one block — the UnixFS file holding {"name":"x"}.
+	expected := "" +
+		"38" +
+		"a2" +
+		"65" + hex.EncodeToString([]byte("roots")) +
+		"81" + "d82a" + "5823" + "00" + "12203ad7fae75716dc962d01ae3b1fd68e2ca34f639c30a7a010bd9244232f9a902d" +
+		"67" + hex.EncodeToString([]byte("version")) + "01" +
+		"36" +
+		"12203ad7fae75716dc962d01ae3b1fd68e2ca34f639c30a7a010bd9244232f9a902d" +
+		"0a12" + "0802" + "120c" + hex.EncodeToString([]byte(`{"name":"x"}`)) + "180c"
+
+	if actual := hex.EncodeToString(buffer.Bytes()); expected != actual {
+		t.Errorf("The actual CAR is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+}
+
+func TestMetaData_CAR_roundTrip(t *testing.T) {
+
+	var small nftmeta.MetaData
+	small.SetName("Dave Starbelly")
+	small.SetDescription("Friendly OpenSea Creature that enjoys long swims in the ocean.")
+
+	// This is bigger than a chunk, so its file has more than one block.
+	var big nftmeta.MetaData
+	big.SetName("Big")
+	big.SetDescription(strings.Repeat("0123456789", 60000))
+
+	tests := []struct{
+		MetaData nftmeta.MetaData
+	}{
+		{
+			MetaData: small,
+		},
+		{
+			MetaData: big,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		expectedJSON, err := test.MetaData.MarshalJSON()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		for _, write := range []struct{
+			name      string
+			writeCAR  func(*bytes.Buffer) (string, error)
+			cid       func() (string, error)
+		}{
+			{
+				name:     "CIDv0",
+				writeCAR: func(buffer *bytes.Buffer) (string, error) { return test.MetaData.WriteCARv0(buffer) },
+				cid:      test.MetaData.CIDv0,
+			},
+			{
+				name:     "CIDv1 with raw leaves",
+				writeCAR: func(buffer *bytes.Buffer) (string, error) { return test.MetaData.WriteCARv1(buffer, true) },
+				cid:      func() (string, error) { return test.MetaData.CIDv1(true) },
+			},
+			{
+				name:     "CIDv1",
+				writeCAR: func(buffer *bytes.Buffer) (string, error) { return test.MetaData.WriteCARv1(buffer, false) },
+				cid:      func() (string, error) { return test.MetaData.CIDv1(false) },
+			},
+		}{
+			var buffer bytes.Buffer
+			root, err := write.writeCAR(&buffer)
+			if nil != err {
+				t.Errorf("For test #%d (%s), did not expect an error but actually got one.", testNumber, write.name)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			expectedRoot, err := write.cid()
+			if nil != err {
+				t.Errorf("For test #%d (%s), did not expect an error but actually got one.", testNumber, write.name)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+			if expectedRoot != root {
+				t.Errorf("For test #%d (%s), the actual root is not what was expected.", testNumber, write.name)
+				t.Logf("EXPECTED: %q", expectedRoot)
+				t.Logf("ACTUAL:   %q", root)
+				continue
+			}
+
+			metadata, readRoot, err := nftmeta.ReadMetaDataCAR(&buffer)
+			if nil != err {
+				t.Errorf("For test #%d (%s), did not expect an error but actually got one.", testNumber, write.name)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+			if root != readRoot {
+				t.Errorf("For test #%d (%s), the actual read root is not what was expected.", testNumber, write.name)
+				t.Logf("EXPECTED: %q", root)
+				t.Logf("ACTUAL:   %q", readRoot)
+				continue
+			}
+
+			actualJSON, err := metadata.MarshalJSON()
+			if nil != err {
+				t.Errorf("For test #%d (%s), did not expect an error but actually got one.", testNumber, write.name)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+			if !bytes.Equal(expectedJSON, actualJSON) {
+				t.Errorf("For test #%d (%s), the actual metadata is not what was expected.", testNumber, write.name)
+				continue
+			}
+		}
+	}
+}
+
+func TestCollectionDirectory_CAR_roundTrip(t *testing.T) {
+
+	tests := []struct{
+		Count int
+	}{
+		{
+			Count: 3,
+		},
+		{
+			// With 64-hex-digit file names, this is big enough to be HAMT-sharded.
+			Count: 3000,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var collection nftmeta.CollectionDirectory
+		collection.SetFileNaming(nftmeta.TokenFileNamingHexJSON)
+
+		for i := 0; i < test.Count; i++ {
+			var metadata nftmeta.MetaData
+			metadata.SetName("Token #" + big.NewInt(int64(i)).String())
+
+			if err := collection.Add(big.NewInt(int64(i)), metadata); nil != err {
+				t.Fatalf("For test #%d, did not expect an error but actually got one: (%T) %s", testNumber, err, err)
+			}
+		}
+
+		var buffer bytes.Buffer
+		root, err := collection.WriteCARv1(&buffer, true)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, err := collection.CIDv1(true); nil != err || expected != root {
+			t.Errorf("For test #%d, the actual root is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", root)
+			continue
+		}
+
+		// The CAR file is the same each time.
+		{
+			var again bytes.Buffer
+			if _, err := collection.WriteCARv1(&again, true); nil != err {
+				t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(buffer.Bytes(), again.Bytes()) {
+				t.Errorf("For test #%d, the CAR file is not the same when written again.", testNumber)
+				continue
+			}
+		}
+
+		files, readRoot, err := nftmeta.ReadCollectionDirectoryCAR(&buffer)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+		if root != readRoot {
+			t.Errorf("For test #%d, the actual read root is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", root)
+			t.Logf("ACTUAL:   %q", readRoot)
+			continue
+		}
+
+		if expected, actual := test.Count, len(files); expected != actual {
+			t.Errorf("For test #%d, the actual number of files is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			continue
+		}
+
+		{
+			name, _ := nftmeta.TokenFileNamingHexJSON.FileName(big.NewInt(2))
+
+			metadata, found := files[name]
+			if !found {
+				t.Errorf("For test #%d, expected file %q but did not actually get it.", testNumber, name)
+				continue
+			}
+
+			if expected, actual := "Token #2", metadata.Name().GetElse(""); expected != actual {
+				t.Errorf("For test #%d, the actual name is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+	}
+}
+
+func TestReadMetaDataCAR_corrupt(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("x")
+
+	var buffer bytes.Buffer
+	if _, err := metadata.WriteCARv0(&buffer); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	good := buffer.Bytes()
+
+	tests := []struct{
+		Data []byte
+	}{
+		{
+			Data: nil,
+		},
+		{
+			// Truncated.
+			Data: good[:len(good)-1],
+		},
+		{
+			// A block that does not match its CID.
+			Data: bytes.Replace(good, []byte(`"x"`), []byte(`"y"`), 1),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, _, err := nftmeta.ReadMetaDataCAR(bytes.NewReader(test.Data))
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			continue
+		}
+	}
+}
+
+// carTestProtobufBytes appends the length-delimited protobuf field 'field' (with the value 'value') to 'p'.
+func carTestProtobufBytes(p []byte, field uint64, value []byte) []byte {
+	p = binary.AppendUvarint(p, field<<3|2)
+	p = binary.AppendUvarint(p, uint64(len(value)))
+	return append(p, value...)
+}
+
+// carTestProtobufVarint appends the varint protobuf field 'field' (with the value 'value') to 'p'.
+func carTestProtobufVarint(p []byte, field uint64, value uint64) []byte {
+	p = binary.AppendUvarint(p, field<<3)
+	return binary.AppendUvarint(p, value)
+}
+
+// carTestCIDv0 returns the (binary) CIDv0 of the dag-pb block 'block'.
+func carTestCIDv0(block []byte) []byte {
+	digest := sha256.Sum256(block)
+	return append([]byte{0x12, 0x20}, digest[:]...)
+}
+
+// carTestLink returns a dag-pb link to the block 'block', named 'name'.
+func carTestLink(name string, block []byte) []byte {
+	var link []byte
+	link = carTestProtobufBytes(link, 1, carTestCIDv0(block))
+	link = carTestProtobufBytes(link, 2, []byte(name))
+	return link
+}
+
+// carTestCAR returns a CARv1 file of the (dag-pb) blocks 'blocks' — whose root is the last of them.
+func carTestCAR(blocks [][]byte) []byte {
+	section := func(p []byte, data ...[]byte) []byte {
+		var length int
+		for _, datum := range data {
+			length += len(datum)
+		}
+		p = binary.AppendUvarint(p, uint64(length))
+		for _, datum := range data {
+			p = append(p, datum...)
+		}
+		return p
+	}
+
+	var header []byte
+	header = append(header, 0xa2, 0x65)
+	header = append(header, "roots"...)
+	header = append(header, 0x81, 0xd8, 0x2a, 0x58, 0x23, 0x00)
+	header = append(header, carTestCIDv0(blocks[len(blocks)-1])...)
+	header = append(header, 0x67)
+	header = append(header, "version"...)
+	header = append(header, 0x01)
+
+	var car []byte
+	car = section(car, header)
+	for index := len(blocks) - 1; 0 <= index; index-- {
+		car = section(car, carTestCIDv0(blocks[index]), blocks[index])
+	}
+	return car
+}
+
+// carTestFileBlocks returns the blocks of a UnixFS file — made of 'depth' levels of nodes that each link to the node below twice, over a leaf with the content 'leaf' — so that the file is 2^depth copies of 'leaf'.
+// The file size of the leaf is 'leafFileSize'. The root of the file is the last block.
+func carTestFileBlocks(leaf []byte, leafFileSize uint64, depth int) [][]byte {
+	var unixfsData []byte
+	unixfsData = carTestProtobufVarint(unixfsData, 1, 2) // file
+	unixfsData = carTestProtobufBytes(unixfsData, 2, leaf)
+	unixfsData = carTestProtobufVarint(unixfsData, 3, leafFileSize)
+
+	var blocks [][]byte
+	block := carTestProtobufBytes(nil, 1, unixfsData)
+	blocks = append(blocks, block)
+
+	for level := 0; level < depth; level++ {
+		link := carTestProtobufBytes(nil, 1, carTestCIDv0(block))
+
+		block = nil
+		block = carTestProtobufBytes(block, 2, link)
+		block = carTestProtobufBytes(block, 2, link)
+		block = carTestProtobufBytes(block, 1, carTestProtobufVarint(nil, 1, 2))
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// carTestFile returns a CARv1 file whose root is the UnixFS file of carTestFileBlocks.
+func carTestFile(leaf []byte, leafFileSize uint64, depth int) []byte {
+	return carTestCAR(carTestFileBlocks(leaf, leafFileSize, depth))
+}
+
+// carTestDirectoryNode returns a UnixFS directory node (of the type 'dataType' — 1 for a directory, 5 for a HAMT shard) with the links 'links'.
+func carTestDirectoryNode(dataType uint64, links ...[]byte) []byte {
+	var unixfsData []byte
+	unixfsData = carTestProtobufVarint(unixfsData, 1, dataType)
+	if 5 == dataType {
+		unixfsData = carTestProtobufVarint(unixfsData, 6, 256)
+	}
+
+	var block []byte
+	for _, link := range links {
+		block = carTestProtobufBytes(block, 2, link)
+	}
+	return carTestProtobufBytes(block, 1, unixfsData)
+}
+
+func TestReadMetaDataCAR_expansion(t *testing.T) {
+
+	{
+		metadata, _, err := nftmeta.ReadMetaDataCAR(bytes.NewReader(carTestFile([]byte(`{"name":"x"}`), 12, 0)))
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+		if expected, actual := "x", metadata.Name().GetElse(""); expected != actual {
+			t.Errorf("The actual name is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+
+	tests := []struct{
+		Data []byte
+	}{
+		{
+			// 2^40 KiB of content.
+			Data: carTestFile(bytes.Repeat([]byte(" "), 1024), 1024, 40),
+		},
+		{
+			// 2^40 (empty) nodes.
+			Data: carTestFile(nil, 0, 40),
+		},
+		{
+			// A file that says it is 1 TiB.
+			Data: carTestFile([]byte(" "), 1<<40, 0),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, _, err := nftmeta.ReadMetaDataCAR(bytes.NewReader(test.Data))
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			continue
+		}
+	}
+}
+
+func TestReadCollectionDirectoryCAR_expansion(t *testing.T) {
+
+	// A (more than) 1 MiB file — which is {"name":"x"} followed by 1 MiB of spaces, so is valid JSON.
+	var fileBlocks = carTestFileBlocks(bytes.Repeat([]byte(" "), 1024), 1024, 10)
+	var file []byte
+	{
+		var unixfsData []byte
+		unixfsData = carTestProtobufVarint(unixfsData, 1, 2) // file
+		unixfsData = carTestProtobufBytes(unixfsData, 2, []byte(`{"name":"x"}`))
+
+		file = carTestProtobufBytes(file, 2, carTestProtobufBytes(nil, 1, carTestCIDv0(fileBlocks[len(fileBlocks)-1])))
+		file = carTestProtobufBytes(file, 1, unixfsData)
+		fileBlocks = append(fileBlocks, file)
+	}
+
+	// A directory whose entries are all the 1 MiB file.
+	directory := func(names ...string) []byte {
+		var links [][]byte
+		for _, name := range names {
+			links = append(links, carTestLink(name, file))
+		}
+
+		var blocks [][]byte
+		blocks = append(blocks, fileBlocks...)
+		blocks = append(blocks, carTestDirectoryNode(1, links...))
+		return carTestCAR(blocks)
+	}
+
+	{
+		collection, _, err := nftmeta.ReadCollectionDirectoryCAR(bytes.NewReader(directory("1", "2")))
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+		if expected, actual := 2, len(collection); expected != actual {
+			t.Errorf("The actual number of files is not what was expected.")
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+		}
+	}
+
+	// HAMT shards (with a fanout of 256) — where each link of the 2 upper levels is to the same shard below it, over a shard of 256 entries — which is 2^24 entries.
+	var hamt [][]byte
+	{
+		hamt = append(hamt, fileBlocks...)
+
+		var links [][]byte
+		for index := 0; index < 256; index++ {
+			links = append(links, carTestLink(fmt.Sprintf("%02X%d", index, index), file))
+		}
+		shard := carTestDirectoryNode(5, links...)
+		hamt = append(hamt, shard)
+
+		for level := 0; level < 2; level++ {
+			var links [][]byte
+			for index := 0; index < 256; index++ {
+				links = append(links, carTestLink(fmt.Sprintf("%02X", index), shard))
+			}
+			shard = carTestDirectoryNode(5, links...)
+			hamt = append(hamt, shard)
+		}
+	}
+
+	var names []string
+	for index := 0; index < 300; index++ {
+		names = append(names, strconv.Itoa(index))
+	}
+
+	tests := []struct{
+		Data []byte
+	}{
+		{
+			Data: carTestCAR(hamt),
+		},
+		{
+			// Two entries with the same name.
+			Data: directory("1", "2", "1"),
+		},
+		{
+			// 300 MiB of files — each of which alone is not too big.
+			Data: directory(names...),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, _, err := nftmeta.ReadCollectionDirectoryCAR(bytes.NewReader(test.Data))
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			continue
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package nftmeta
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// Multicodec codes (and multihash codes) that are used here.
+const (
+	multicodecRaw     = 0x55
+	multicodecDagPB   = 0x70
+	multihashSHA2_256 = 0x12
+)
+
+// cidBase32 is the (lower-case, unpadded) base32 that CIDv1 uses — with the multibase prefix "b".
+var cidBase32 = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode returns the base58btc (i.e., Bitcoin alphabet) encoding of 'data'.
+func base58Encode(data []byte) string {
+	var zeros int
+	for zeros < len(data) && 0 == data[zeros] {
+		zeros++
+	}
+
+	n := big.NewInt(0).SetBytes(data)
+	radix := big.NewInt(58)
+	remainder := big.NewInt(0)
+
+	var reversed []byte
+	for 0 < n.Sign() {
+		n.DivMod(n, radix, remainder)
+		reversed = append(reversed, base58Alphabet[remainder.Int64()])
+	}
+
+	var builder strings.Builder
+	for i := 0; i < zeros; i++ {
+		builder.WriteByte(base58Alphabet[0])
+	}
+	for i := len(reversed) - 1; 0 <= i; i-- {
+		builder.WriteByte(reversed[i])
+	}
+	return builder.String()
+}
+
+// base58Decode decodes base58btc (i.e., Bitcoin alphabet).
+func base58Decode(str string) ([]byte, error) {
+	var zeros int
+	for zeros < len(str) && base58Alphabet[0] == str[zeros] {
+		zeros++
+	}
+
+	n := big.NewInt(0)
+	radix := big.NewInt(58)
+	for _, r := range str {
+		index := strings.IndexRune(base58Alphabet, r)
+		if index < 0 {
+			return nil, erorr.Errorf("nftmeta: %q is not a base58 character", r)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(index)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// readUvarint reads an unsigned varint (as used by multiformats) from the front of 'p', and returns the rest.
+func readUvarint(p []byte) (uint64, []byte, error) {
+	value, n := binary.Uvarint(p)
+	if n <= 0 {
+		return 0, nil, errBadVarint
+	}
+	return value, p[n:], nil
+}
+
+// cid is a parsed CID (content identifier).
+type cid struct {
+	version   uint64
+	codec     uint64
+	multihash []byte
+}
+
+// sha256Digest returns the SHA2-256 digest of the CID, if its multihash is SHA2-256.
+func (receiver cid) sha256Digest() ([]byte, error) {
+	code, rest, err := readUvarint(receiver.multihash)
+	if nil != err {
+		return nil, err
+	}
+	length, digest, err := readUvarint(rest)
+	if nil != err {
+		return nil, err
+	}
+
+	if multihashSHA2_256 != code || 32 != length || 32 != len(digest) {
+		return nil, errCIDNotSHA256
+	}
+
+	return digest, nil
+}
+
+// bytes returns the binary form of the CID — which, for a CIDv0, is just its multihash.
+func (receiver cid) bytes() []byte {
+	if 0 == receiver.version {
+		return receiver.multihash
+	}
+
+	var p []byte
+	p = binary.AppendUvarint(p, receiver.version)
+	p = binary.AppendUvarint(p, receiver.codec)
+	return append(p, receiver.multihash...)
+}
+
+// String returns the canonical string form of the CID: base58btc for a CIDv0, and (multibase) base32 for a CIDv1.
+func (receiver cid) String() string {
+	if 0 == receiver.version {
+		return base58Encode(receiver.multihash)
+	}
+
+	return "b" + cidBase32.EncodeToString(receiver.bytes())
+}
+
+// sha256Multihash returns the sha2-256 multihash of 'digest'.
+func sha256Multihash(digest []byte) []byte {
+	var p []byte
+	p = binary.AppendUvarint(p, multihashSHA2_256)
+	p = binary.AppendUvarint(p, uint64(len(digest)))
+	return append(p, digest...)
+}
+
+// parseCID parses a CIDv0 (which is base58btc, beginning with "Qm") or a CIDv1 (which is base32, beginning with the multibase prefix "b").
+func parseCID(str string) (cid, error) {
+	if 46 == len(str) && strings.HasPrefix(str, "Qm") {
+		multihash, err := base58Decode(str)
+		if nil != err {
+			return cid{}, erorr.Errorf("nftmeta: %q is not a valid CIDv0: %w", str, err)
+		}
+
+		parsed := cid{version: 0, codec: multicodecDagPB, multihash: multihash}
+		if _, err := parsed.sha256Digest(); nil != err {
+			return cid{}, erorr.Errorf("nftmeta: %q is not a valid CIDv0: %w", str, err)
+		}
+		return parsed, nil
+	}
+
+	if !strings.HasPrefix(str, "b") {
+		return cid{}, erorr.Errorf("nftmeta: %q is not a CID this package understands (only CIDv0, and base32 CIDv1, are supported)", str)
+	}
+
+	p, err := cidBase32.DecodeString(strings.ToLower(str[1:]))
+	if nil != err {
+		return cid{}, erorr.Errorf("nftmeta: %q is not a valid CIDv1: %w", str, err)
+	}
+
+	version, p, err := readUvarint(p)
+	if nil != err {
+		return cid{}, erorr.Errorf("nftmeta: %q is not a valid CIDv1: %w", str, err)
+	}
+	if 1 != version {
+		return cid{}, erorr.Errorf("nftmeta: %q has unsupported CID version %d", str, version)
+	}
+
+	codec, p, err := readUvarint(p)
+	if nil != err {
+		return cid{}, erorr.Errorf("nftmeta: %q is not a valid CIDv1: %w", str, err)
+	}
+
+	return cid{version: version, codec: codec, multihash: p}, nil
+}
+
+// cidFromBytes parses the binary form of a CID (see bytes).
+func cidFromBytes(p []byte) (cid, error) {
+	// A CIDv0 is just a sha2-256 multihash.
+	if 34 == len(p) && multihashSHA2_256 == p[0] && 32 == p[1] {
+		return cid{version: 0, codec: multicodecDagPB, multihash: append([]byte(nil), p...)}, nil
+	}
+
+	version, rest, err := readUvarint(p)
+	if nil != err {
+		return cid{}, err
+	}
+	if 1 != version {
+		return cid{}, erorr.Errorf("nftmeta: unsupported CID version %d", version)
+	}
+
+	codec, multihash, err := readUvarint(rest)
+	if nil != err {
+		return cid{}, err
+	}
+
+	// The multihash must be exactly the rest of the bytes.
+	{
+		_, rest, err := readUvarint(multihash)
+		if nil != err {
+			return cid{}, err
+		}
+		length, digest, err := readUvarint(rest)
+		if nil != err {
+			return cid{}, err
+		}
+		if uint64(len(digest)) != length {
+			return cid{}, erorr.Errorf("nftmeta: CID multihash digest is %d bytes rather than %d", len(digest), length)
+		}
+	}
+
+	return cid{version: version, codec: codec, multihash: append([]byte(nil), multihash...)}, nil
+}
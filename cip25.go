@@ -0,0 +1,466 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// cip25Label is the Cardano transaction metadata label that CIP-25 NFT metadata sits under.
+const cip25Label = "721"
+
+// cardanoMaxStringLength is the maximum length (in bytes) of a string in Cardano transaction metadata.
+const cardanoMaxStringLength = 64
+
+// CIP25 builds (and parses) Cardano CIP-25 NFT transaction metadata — i.e., what goes under label 721.
+//
+// The CIP-25 metadata is keyed by policy-id and then by asset-name:
+//
+//	{
+//		"721": {
+//			"<policy_id>": {
+//				"<asset_name>": {
+//					"name": "...",
+//					"image": "...",
+//					...
+//				}
+//			},
+//			"version": "2.0"
+//		}
+//	}
+//
+// For version 1, the policy-id is hexadecimal text, and the asset-name is UTF-8 text (or hexadecimal text, if the asset-name is not valid UTF-8).
+// When parsing version 1, a key that is the hexadecimal of bytes that are not valid UTF-8 is taken to be those bytes — so a UTF-8 asset-name that looks like that (such as "cafe") cannot be written as version 1.
+// For version 2, the policy-id and the asset-name are bytes — which, in the (cardano-cli "no schema") JSON, are written as "0x"-prefixed hexadecimal.
+//
+// Strings longer than 64 bytes (which Cardano transaction metadata does not permit) are automatically split into arrays of strings.
+// When parsing, such arrays are reassembled.
+type CIP25 struct {
+	version uint
+	assets  []CIP25Asset
+}
+
+// CIP25Asset is a single asset in CIP-25 metadata.
+type CIP25Asset struct {
+	PolicyID  [28]byte
+	AssetName []byte
+	MetaData  MetaData
+}
+
+// SetVersion sets the CIP-25 version — which must be either 1 or 2.
+//
+// If the version is never set, then version 1 is used.
+func (receiver *CIP25) SetVersion(version uint) error {
+	switch version {
+	case 1, 2:
+		receiver.version = version
+		return nil
+	default:
+		return erorr.Errorf("nftmeta: unsupported CIP-25 version %d", version)
+	}
+}
+
+// Version returns the CIP-25 version.
+func (receiver CIP25) Version() uint {
+	if 0 == receiver.version {
+		return 1
+	}
+	return receiver.version
+}
+
+// Add adds the metadata for an asset.
+//
+// 'policyID' is the hexadecimal policy-id. 'assetName' is the (raw) asset-name, which can be at most 32 bytes.
+//
+// Of 'metadata', the "name", "image", "description", "animation_url", "external_link", and "attributes" are used.
+// The "attributes" become a map of trait-type to value.
+func (receiver *CIP25) Add(policyID string, assetName []byte, metadata MetaData) error {
+	var asset CIP25Asset
+
+	{
+		n, err := hex.Decode(asset.PolicyID[:], []byte(policyID))
+		if nil != err || len(asset.PolicyID) != n || 2*len(asset.PolicyID) != len(policyID) {
+			return erorr.Errorf("nftmeta: %q is not a valid (hexadecimal) policy-id", policyID)
+		}
+	}
+
+	if 32 < len(assetName) {
+		return erorr.Errorf("nftmeta: asset-name is %d bytes long, but can be at most 32 bytes", len(assetName))
+	}
+	asset.AssetName = append([]byte(nil), assetName...)
+
+	asset.MetaData = metadata
+
+	receiver.assets = append(receiver.assets, asset)
+	return nil
+}
+
+// Assets returns (a copy of) the assets.
+func (receiver CIP25) Assets() []CIP25Asset {
+	if len(receiver.assets) <= 0 {
+		return nil
+	}
+
+	assets := make([]CIP25Asset, len(receiver.assets))
+	copy(assets, receiver.assets)
+	return assets
+}
+
+// MetaData returns the metadata of an asset, if the asset is there.
+func (receiver CIP25) MetaData(policyID string, assetName []byte) (MetaData, bool) {
+	for _, asset := range receiver.assets {
+		if strings.EqualFold(hex.EncodeToString(asset.PolicyID[:]), policyID) && string(asset.AssetName) == string(assetName) {
+			return asset.MetaData, true
+		}
+	}
+	return MetaData{}, false
+}
+
+// MarshalJSON returns the CIP-25 metadata, including the 721 label.
+func (receiver CIP25) MarshalJSON() ([]byte, error) {
+	var version uint = receiver.Version()
+
+	var policies = map[string]interface{}{}
+
+	for _, asset := range receiver.assets {
+		var policyKey string
+		var assetKey string
+
+		switch version {
+		case 2:
+			policyKey = "0x" + hex.EncodeToString(asset.PolicyID[:])
+			assetKey  = "0x" + hex.EncodeToString(asset.AssetName)
+		default:
+			policyKey = hex.EncodeToString(asset.PolicyID[:])
+
+			var err error
+			assetKey, err = cip25V1AssetKey(asset.AssetName)
+			if nil != err {
+				return nil, err
+			}
+		}
+
+		value, err := cip25AssetValue(asset.MetaData)
+		if nil != err {
+			return nil, err
+		}
+
+		var assets map[string]interface{}
+		{
+			existing, found := policies[policyKey]
+			if found {
+				assets = existing.(map[string]interface{})
+			} else {
+				assets = map[string]interface{}{}
+				policies[policyKey] = assets
+			}
+		}
+
+		if _, found := assets[assetKey]; found {
+			return nil, erorr.Errorf("nftmeta: duplicate CIP-25 asset %q under policy-id %q", assetKey, policyKey)
+		}
+		assets[assetKey] = value
+	}
+
+	policies["version"] = strconv.FormatUint(uint64(version), 10) + ".0"
+
+	p, err := json.Marshal(map[string]interface{}{cip25Label: policies})
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-marshaling CIP-25 metadata: %w", err)
+	}
+
+	return p, nil
+}
+
+// cip25V1AssetKey returns the (version 1) key of the asset-name 'assetName' — which is the asset-name itself if it is valid UTF-8, and otherwise is its hexadecimal.
+//
+// A UTF-8 asset-name that is itself the hexadecimal of bytes that are not valid UTF-8 (such as "cafe") would be parsed back as those bytes, so it returns an error for it — such an asset-name needs version 2.
+func cip25V1AssetKey(assetName []byte) (string, error) {
+	if !utf8.Valid(assetName) {
+		return hex.EncodeToString(assetName), nil
+	}
+
+	if !bytes.Equal(assetName, cip25V1AssetName(string(assetName))) {
+		return "", erorr.Errorf("nftmeta: CIP-25 (version 1) asset-name %q would be parsed back as hexadecimal — use version 2 for it", assetName)
+	}
+	return string(assetName), nil
+}
+
+// cip25V1AssetName returns the asset-name of the (version 1) key 'assetKey' — which is the bytes it is the hexadecimal of, if those bytes are not valid UTF-8, and otherwise is the key itself.
+//
+// It is the inverse of cip25V1AssetKey.
+func cip25V1AssetName(assetKey string) []byte {
+	decoded, err := hex.DecodeString(assetKey)
+	if nil == err && !utf8.Valid(decoded) {
+		return decoded
+	}
+	return []byte(assetKey)
+}
+
+// cip25AssetValue returns the CIP-25 (per-asset) metadata for 'metadata', with long strings chunked.
+func cip25AssetValue(metadata MetaData) (map[string]interface{}, error) {
+	var value = map[string]interface{}{}
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"name",          metadata.name},
+		{"image",         metadata.image},
+		{"description",   metadata.description},
+		{"animation_url", metadata.animationURL},
+		{"external_link", metadata.externalLink},
+	}{
+		str, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		value[field.name] = chunkString(str, cardanoMaxStringLength)
+	}
+
+	if 0 < len(metadata.attributes) {
+		var attributes = map[string]interface{}{}
+
+		for _, attribute := range metadata.attributes {
+			traitType, something := attribute.traitType.Get()
+			if !something {
+				return nil, errTraitTypeNothing
+			}
+			if _, found := attributes[traitType]; found {
+				return nil, erorr.Errorf("nftmeta: duplicate trait-type %q cannot be put into CIP-25 metadata", traitType)
+			}
+
+			switch casted := attribute.value.(type) {
+			case string:
+				attributes[traitType] = chunkString(casted, cardanoMaxStringLength)
+			case int64, uint64, *big.Int:
+				attributes[traitType] = casted
+			default:
+				// Cardano transaction metadata has no floating-point numbers, so anything else goes in as text.
+				p, err := appendAttributeValue(nil, casted)
+				if nil != err {
+					return nil, err
+				}
+				attributes[traitType] = chunkString(string(p), cardanoMaxStringLength)
+			}
+		}
+
+		value["attributes"] = attributes
+	}
+
+	return value, nil
+}
+
+// chunkString returns 'str' itself if it is at most 'max' bytes long.
+// Otherwise it returns 'str' split into pieces that are each at most 'max' bytes long — never splitting a UTF-8 character.
+func chunkString(str string, max int) interface{} {
+	if len(str) <= max {
+		return str
+	}
+
+	var chunks []string
+	for max < len(str) {
+		var end int = max
+		for 0 < end && !utf8.RuneStart(str[end]) {
+			end--
+		}
+		if end <= 0 {
+			end = max
+		}
+
+		chunks = append(chunks, str[:end])
+		str = str[end:]
+	}
+	if 0 < len(str) {
+		chunks = append(chunks, str)
+	}
+
+	return chunks
+}
+
+// UnmarshalJSON parses CIP-25 metadata. It accepts the metadata with, or without, the 721 label around it.
+func (receiver *CIP25) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var policies map[string]json.RawMessage
+	{
+		var labeled map[string]json.RawMessage
+		if err := json.Unmarshal(data, &labeled); nil != err {
+			return erorr.Errorf("nftmeta: problem json-unmarshaling CIP-25 metadata: %w", err)
+		}
+
+		if inner, found := labeled[cip25Label]; found {
+			if err := json.Unmarshal(inner, &policies); nil != err {
+				return erorr.Errorf("nftmeta: problem json-unmarshaling CIP-25 metadata: %w", err)
+			}
+		} else {
+			policies = labeled
+		}
+	}
+
+	var cip25 CIP25
+
+	if rawVersion, found := policies["version"]; found {
+		var version string
+		if err := json.Unmarshal(rawVersion, &version); nil != err {
+			return erorr.Errorf("nftmeta: problem json-unmarshaling CIP-25 version: %w", err)
+		}
+
+		switch version {
+		case "1.0", "1":
+			cip25.version = 1
+		case "2.0", "2":
+			cip25.version = 2
+		default:
+			return erorr.Errorf("nftmeta: unsupported CIP-25 version %q", version)
+		}
+	}
+
+	var policyKeys []string
+	for key := range policies {
+		if "version" == key {
+			continue
+		}
+		policyKeys = append(policyKeys, key)
+	}
+	sort.Strings(policyKeys)
+
+	for _, policyKey := range policyKeys {
+		var assets map[string]map[string]interface{}
+		{
+			decoder := json.NewDecoder(bytes.NewReader(policies[policyKey]))
+			decoder.UseNumber()
+
+			if err := decoder.Decode(&assets); nil != err {
+				return erorr.Errorf("nftmeta: problem json-unmarshaling CIP-25 assets of policy-id %q: %w", policyKey, err)
+			}
+		}
+
+		var assetKeys []string
+		for key := range assets {
+			assetKeys = append(assetKeys, key)
+		}
+		sort.Strings(assetKeys)
+
+		for _, assetKey := range assetKeys {
+			var assetName []byte
+			if 2 == cip25.Version() {
+				decoded, err := hex.DecodeString(strings.TrimPrefix(assetKey, "0x"))
+				if nil != err {
+					return erorr.Errorf("nftmeta: CIP-25 (version 2) asset-name %q is not hexadecimal: %w", assetKey, err)
+				}
+				assetName = decoded
+			} else {
+				assetName = cip25V1AssetName(assetKey)
+			}
+
+			metadata, err := cip25MetaData(assets[assetKey])
+			if nil != err {
+				return err
+			}
+
+			if err := cip25.Add(strings.TrimPrefix(policyKey, "0x"), assetName, metadata); nil != err {
+				return err
+			}
+		}
+	}
+
+	*receiver = cip25
+	return nil
+}
+
+// cip25MetaData returns the MetaData for the CIP-25 (per-asset) metadata 'value', reassembling any chunked strings.
+func cip25MetaData(value map[string]interface{}) (MetaData, error) {
+	var metadata MetaData
+
+	for _, field := range []struct{
+		name  string
+		value *opt.Optional[string]
+	}{
+		{"name",          &metadata.name},
+		{"image",         &metadata.image},
+		{"description",   &metadata.description},
+		{"animation_url", &metadata.animationURL},
+		{"external_link", &metadata.externalLink},
+	}{
+		raw, found := value[field.name]
+		if !found {
+			continue
+		}
+
+		str, err := unchunkString(raw)
+		if nil != err {
+			return MetaData{}, erorr.Errorf("nftmeta: problem with CIP-25 %q: %w", field.name, err)
+		}
+
+		*field.value = opt.Something(str)
+	}
+
+	if raw, found := value["attributes"]; found {
+		attributes, ok := raw.(map[string]interface{})
+		if !ok {
+			return MetaData{}, erorr.Errorf("nftmeta: CIP-25 \"attributes\" is a %T rather than a map", raw)
+		}
+
+		var traitTypes []string
+		for traitType := range attributes {
+			traitTypes = append(traitTypes, traitType)
+		}
+		sort.Strings(traitTypes)
+
+		for _, traitType := range traitTypes {
+			var attribute Attribute
+			attribute.traitType = opt.Something(traitType)
+
+			switch casted := attributes[traitType].(type) {
+			case json.Number:
+				number, err := parseAttributeNumber(casted.String())
+				if nil != err {
+					return MetaData{}, err
+				}
+				attribute.value = number
+			default:
+				str, err := unchunkString(casted)
+				if nil != err {
+					return MetaData{}, erorr.Errorf("nftmeta: problem with CIP-25 attribute %q: %w", traitType, err)
+				}
+				attribute.value = str
+			}
+
+			metadata.attributes = append(metadata.attributes, attribute)
+		}
+	}
+
+	return metadata, nil
+}
+
+// unchunkString returns the string of 'value' — which is either a string or an array of strings (that are concatenated).
+func unchunkString(value interface{}) (string, error) {
+	switch casted := value.(type) {
+	case string:
+		return casted, nil
+	case []interface{}:
+		var builder strings.Builder
+		for _, chunk := range casted {
+			str, ok := chunk.(string)
+			if !ok {
+				return "", erorr.Errorf("nftmeta: chunk is a %T rather than a string", chunk)
+			}
+			builder.WriteString(str)
+		}
+		return builder.String(), nil
+	default:
+		return "", erorr.Errorf("nftmeta: %T is neither a string nor an array of strings", value)
+	}
+}
@@ -0,0 +1,284 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestCIP25_MarshalJSON(t *testing.T) {
+
+	const policyID = "8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a"
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("Tahoe #1")
+	metadata.SetImage("ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/this-is-a-rather-long-file-name.png")
+	metadata.SetDescription("A photo of Lake Tahoe ☃ in winter.")
+	metadata.AppendAttribute(nftmeta.AttributeString("Season", "Winter"))
+	metadata.AppendAttribute(nftmeta.AttributeInt64("Edition", 3))
+
+	tests := []struct{
+		Version uint
+		Expected []byte
+	}{
+		{
+			Version: 1,
+			Expected: []byte(`{"721":{"8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a":{"Tahoe1":{"attributes":{"Edition":3,"Season":"Winter"},"description":"A photo of Lake Tahoe ☃ in winter.","image":["ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbz","di/this-is-a-rather-long-file-name.png"],"name":"Tahoe #1"}},"version":"1.0"}}`),
+		},
+		{
+			Version: 2,
+			Expected: []byte(`{"721":{"0x8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a":{"0x5461686f6531":{"attributes":{"Edition":3,"Season":"Winter"},"description":"A photo of Lake Tahoe ☃ in winter.","image":["ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbz","di/this-is-a-rather-long-file-name.png"],"name":"Tahoe #1"}},"version":"2.0"}}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var cip25 nftmeta.CIP25
+		if err := cip25.SetVersion(test.Version); nil != err {
+			t.Errorf("For test #%d, did not expect an error when setting the version but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+		if err := cip25.Add(policyID, []byte("Tahoe1"), metadata); nil != err {
+			t.Errorf("For test #%d, did not expect an error when adding but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		actual, err := json.Marshal(cip25)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		{
+			expected := test.Expected
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %s", actual)
+				continue
+			}
+		}
+
+		{
+			var parsed nftmeta.CIP25
+
+			if err := json.Unmarshal(actual, &parsed); nil != err {
+				t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected, actual := test.Version, parsed.Version(); expected != actual {
+				t.Errorf("For test #%d, the actual parsed version is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %d", expected)
+				t.Logf("ACTUAL:   %d", actual)
+				continue
+			}
+
+			got, found := parsed.MetaData(policyID, []byte("Tahoe1"))
+			if !found {
+				t.Errorf("For test #%d, expected the asset to be found, but it was not.", testNumber)
+				continue
+			}
+
+			expected := []byte(`{"description":"A photo of Lake Tahoe ☃ in winter.","image":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/this-is-a-rather-long-file-name.png","name":"Tahoe #1","attributes":[{"trait_type":"Edition","value":3},{"trait_type":"Season","value":"Winter"}]}`)
+
+			actual, err := json.Marshal(got)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual reassembled metadata is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %s", actual)
+				continue
+			}
+		}
+	}
+}
+
+func TestCIP25_MarshalJSON_chunkingUTF8(t *testing.T) {
+
+	// 21 three-byte characters is 63 bytes; so the 22nd character would straddle the 64-byte boundary.
+	var description string
+	for i := 0; i < 30; i++ {
+		description += "☃"
+	}
+
+	var metadata nftmeta.MetaData
+	metadata.SetDescription(description)
+
+	var cip25 nftmeta.CIP25
+	if err := cip25.Add("8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a", []byte("a"), metadata); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	p, err := json.Marshal(cip25)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	var decoded struct {
+		Label map[string]json.RawMessage `json:"721"`
+	}
+	if err := json.Unmarshal(p, &decoded); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	var assets map[string]map[string][]string
+	if err := json.Unmarshal(decoded.Label["8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a"], &assets); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	chunks := assets["a"]["description"]
+	if 2 != len(chunks) {
+		t.Fatalf("Expected 2 chunks but actually got %d: %q", len(chunks), chunks)
+	}
+	if expected, actual := 63, len(chunks[0]); expected != actual {
+		t.Errorf("The actual length of the first chunk is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	if expected, actual := description, chunks[0]+chunks[1]; expected != actual {
+		t.Errorf("The chunks do not reassemble into the original string.")
+	}
+}
+
+func TestCIP25_UnmarshalJSON_v1AssetName(t *testing.T) {
+
+	const policyID = "8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a"
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("Tahoe #1")
+
+	tests := []struct{
+		AssetName   []byte
+		ExpectedKey string
+	}{
+		{
+			AssetName:   []byte("Tahoe1"),
+			ExpectedKey: "Tahoe1",
+		},
+		{
+			// Valid UTF-8 that is also valid hexadecimal — but of bytes that are valid UTF-8 too.
+			AssetName:   []byte("5461"),
+			ExpectedKey: "5461",
+		},
+		{
+			AssetName:   []byte{0xff, 0x00, 0x01},
+			ExpectedKey: "ff0001",
+		},
+		{
+			AssetName:   []byte{0xca, 0xfe},
+			ExpectedKey: "cafe",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var cip25 nftmeta.CIP25
+		if err := cip25.Add(policyID, test.AssetName, metadata); nil != err {
+			t.Errorf("For test #%d, did not expect an error when adding but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		p, err := json.Marshal(cip25)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		{
+			expected := []byte(`{"721":{"` + policyID + `":{"` + test.ExpectedKey + `":{"name":"Tahoe #1"}},"version":"1.0"}}`)
+			actual := p
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %s", actual)
+				continue
+			}
+		}
+
+		var parsed nftmeta.CIP25
+		if err := json.Unmarshal(p, &parsed); nil != err {
+			t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		assets := parsed.Assets()
+		if expected, actual := 1, len(assets); expected != actual {
+			t.Errorf("For test #%d, the actual number of assets is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			continue
+		}
+
+		if expected, actual := test.AssetName, assets[0].AssetName; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual asset-name is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %x", expected)
+			t.Logf("ACTUAL:   %x", actual)
+			continue
+		}
+	}
+}
+
+func TestCIP25_MarshalJSON_v1AssetNameAmbiguous(t *testing.T) {
+
+	const policyID = "8b0a5b2ce8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8ab5b8a4bb8a"
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("Cafe")
+
+	tests := []struct{
+		Version     uint
+		ExpectError bool
+	}{
+		{
+			Version:     1,
+			ExpectError: true,
+		},
+		{
+			Version:     2,
+			ExpectError: false,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var cip25 nftmeta.CIP25
+		if err := cip25.SetVersion(test.Version); nil != err {
+			t.Errorf("For test #%d, did not expect an error when setting the version but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+		// "cafe" is valid UTF-8, but it is also the hexadecimal of bytes that are not.
+		if err := cip25.Add(policyID, []byte("cafe"), metadata); nil != err {
+			t.Errorf("For test #%d, did not expect an error when adding but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		_, err := json.Marshal(cip25)
+		if expected, actual := test.ExpectError, nil != err; expected != actual {
+			t.Errorf("For test #%d, whether there was an error is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			t.Logf("ERROR: %v", err)
+			continue
+		}
+	}
+}
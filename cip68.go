@@ -0,0 +1,259 @@
+package nftmeta
+
+import (
+	"math/big"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// CIP68Datum represents the inline datum of a Cardano CIP-68 reference NFT:
+//
+//	Constr 0 [metadata, version, extra]
+//
+// It is encoded as Plutus data CBOR. The metadata is a map with bytestring keys; (text) values are UTF-8 bytestrings.
+//
+// Of the MetaData, the "name", "image", "description", "animation_url", "external_link", and "attributes" are used.
+// The "attributes" become a map of trait-type to value.
+type CIP68Datum struct {
+	metadata MetaData
+	version  opt.Optional[uint64]
+	extra    interface{}
+}
+
+// defaultCIP68Version is the CIP-68 version used when none is set.
+const defaultCIP68Version = 1
+
+func (receiver CIP68Datum) MetaData() MetaData {
+	return receiver.metadata
+}
+
+func (receiver *CIP68Datum) SetMetaData(metadata MetaData) {
+	receiver.metadata = metadata
+}
+
+// Version returns the CIP-68 version. If none was set, then it returns 1.
+func (receiver CIP68Datum) Version() uint64 {
+	value, something := receiver.version.Get()
+	if !something {
+		return defaultCIP68Version
+	}
+	return value
+}
+
+func (receiver *CIP68Datum) SetVersion(version uint64) {
+	receiver.version = opt.Something(version)
+}
+
+// MarshalCBOR returns the Plutus data CBOR encoding of the datum.
+//
+// If the datum was decoded, then its "extra" is carried over as-is. Otherwise "extra" is unit (i.e., Constr 0 []).
+func (receiver CIP68Datum) MarshalCBOR() ([]byte, error) {
+	metadata, err := cip68PlutusMap(receiver.metadata)
+	if nil != err {
+		return nil, err
+	}
+
+	var extra interface{} = receiver.extra
+	if nil == extra {
+		extra = plutusConstr{}
+	}
+
+	datum := plutusConstr{
+		index: 0,
+		fields: []interface{}{
+			metadata,
+			big.NewInt(0).SetUint64(receiver.Version()),
+			extra,
+		},
+	}
+
+	return appendPlutusData(nil, datum)
+}
+
+// UnmarshalCBOR decodes the Plutus data CBOR encoding of the datum.
+func (receiver *CIP68Datum) UnmarshalCBOR(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	decoded, err := decodePlutusData(data)
+	if nil != err {
+		return err
+	}
+
+	constr, ok := decoded.(plutusConstr)
+	if !ok || 0 != constr.index || 3 != len(constr.fields) {
+		return errCIP68BadDatum
+	}
+
+	var datum CIP68Datum
+
+	{
+		m, ok := constr.fields[0].(plutusMap)
+		if !ok {
+			return erorr.Errorf("nftmeta: CIP-68 metadata is a %T rather than a map", constr.fields[0])
+		}
+
+		datum.metadata, err = cip68MetaData(m)
+		if nil != err {
+			return err
+		}
+	}
+
+	{
+		version, ok := constr.fields[1].(*big.Int)
+		if !ok || !version.IsUint64() {
+			return errCIP68BadVersion
+		}
+		datum.version = opt.Something(version.Uint64())
+	}
+
+	datum.extra = constr.fields[2]
+
+	*receiver = datum
+	return nil
+}
+
+// cip68PlutusMap returns the CIP-68 metadata map for 'metadata'.
+func cip68PlutusMap(metadata MetaData) (plutusMap, error) {
+	var m plutusMap = plutusMap{}
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"name",          metadata.name},
+		{"image",         metadata.image},
+		{"description",   metadata.description},
+		{"animation_url", metadata.animationURL},
+		{"external_link", metadata.externalLink},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		m = append(m, plutusMapEntry{key: []byte(field.name), value: []byte(value)})
+	}
+
+	if 0 < len(metadata.attributes) {
+		var attributes plutusMap
+		var seen = map[string]struct{}{}
+
+		for _, attribute := range metadata.attributes {
+			traitType, something := attribute.traitType.Get()
+			if !something {
+				return nil, errTraitTypeNothing
+			}
+			if _, found := seen[traitType]; found {
+				return nil, erorr.Errorf("nftmeta: duplicate trait-type %q cannot be put into CIP-68 metadata", traitType)
+			}
+			seen[traitType] = struct{}{}
+
+			var value interface{}
+			switch casted := attribute.value.(type) {
+			case string:
+				value = []byte(casted)
+			case int64:
+				value = big.NewInt(casted)
+			case uint64:
+				value = big.NewInt(0).SetUint64(casted)
+			case *big.Int:
+				value = casted
+			default:
+				// Plutus data has no floating-point numbers, so anything else goes in as text.
+				p, err := appendAttributeValue(nil, casted)
+				if nil != err {
+					return nil, err
+				}
+				value = p
+			}
+
+			attributes = append(attributes, plutusMapEntry{key: []byte(traitType), value: value})
+		}
+
+		m = append(m, plutusMapEntry{key: []byte("attributes"), value: attributes})
+	}
+
+	return m, nil
+}
+
+// cip68MetaData returns the MetaData for the CIP-68 metadata map 'm'.
+func cip68MetaData(m plutusMap) (MetaData, error) {
+	var metadata MetaData
+
+	var fields = map[string]*opt.Optional[string]{
+		"name":          &metadata.name,
+		"image":         &metadata.image,
+		"description":   &metadata.description,
+		"animation_url": &metadata.animationURL,
+		"external_link": &metadata.externalLink,
+	}
+
+	for _, entry := range m {
+		key, ok := entry.key.([]byte)
+		if !ok {
+			return MetaData{}, erorr.Errorf("nftmeta: CIP-68 metadata key is a %T rather than a bytestring", entry.key)
+		}
+
+		if "attributes" == string(key) {
+			attributes, ok := entry.value.(plutusMap)
+			if !ok {
+				return MetaData{}, erorr.Errorf("nftmeta: CIP-68 \"attributes\" is a %T rather than a map", entry.value)
+			}
+
+			var err error
+			metadata.attributes, err = cip68Attributes(attributes)
+			if nil != err {
+				return MetaData{}, err
+			}
+			continue
+		}
+
+		field, found := fields[string(key)]
+		if !found {
+			continue
+		}
+
+		value, ok := entry.value.([]byte)
+		if !ok {
+			return MetaData{}, erorr.Errorf("nftmeta: CIP-68 %q is a %T rather than a bytestring", key, entry.value)
+		}
+
+		*field = opt.Something(string(value))
+	}
+
+	return metadata, nil
+}
+
+func cip68Attributes(m plutusMap) ([]Attribute, error) {
+	var attributes []Attribute
+
+	for _, entry := range m {
+		key, ok := entry.key.([]byte)
+		if !ok {
+			return nil, erorr.Errorf("nftmeta: CIP-68 attribute key is a %T rather than a bytestring", entry.key)
+		}
+
+		var attribute Attribute
+		attribute.traitType = opt.Something(string(key))
+
+		switch casted := entry.value.(type) {
+		case []byte:
+			attribute.value = string(casted)
+		case *big.Int:
+			value, err := parseAttributeNumber(casted.String())
+			if nil != err {
+				return nil, err
+			}
+			attribute.value = value
+		default:
+			return nil, erorr.Errorf("nftmeta: CIP-68 attribute %q is a %T rather than a bytestring or integer", key, entry.value)
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes, nil
+}
@@ -0,0 +1,185 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestCIP68Datum_MarshalCBOR(t *testing.T) {
+
+	tests := []struct{
+		MetaData nftmeta.MetaData
+		Version uint64
+		Expected string
+	}{
+		{
+			MetaData: nftmeta.MetaData{},
+			Version: 1,
+			// Constr 0 [{}, 1, Constr 0 []]
+			Expected: "d8799f" + "a0" + "01" + "d87980" + "ff",
+		},
+		{
+			MetaData: func()nftmeta.MetaData{
+				var metadata nftmeta.MetaData
+				metadata.SetName("Hello")
+
+				return metadata
+			}(),
+			Version: 1,
+			// Constr 0 [{"name":"Hello"}, 1, Constr 0 []]
+			Expected: "d8799f" + "a1" + "446e616d65" + "4548656c6c6f" + "01" + "d87980" + "ff",
+		},
+		{
+			MetaData: func()nftmeta.MetaData{
+				var metadata nftmeta.MetaData
+				metadata.SetName("A")
+				metadata.SetImage("ipfs://x")
+				metadata.AppendAttribute(nftmeta.AttributeInt64("n", -1))
+				metadata.AppendAttribute(nftmeta.AttributeUint64("m", 1000))
+				metadata.AppendAttribute(nftmeta.AttributeBigInt("b", big.NewInt(0).Lsh(big.NewInt(1), 64)))
+
+				return metadata
+			}(),
+			Version: 2,
+			Expected: "d8799f" +
+				"a3" +
+					"446e616d65" + "4141" +
+					"45696d616765" + "48697066733a2f2f78" +
+					"4a61747472696275746573" + "a3" +
+						"416e" + "20" +
+						"416d" + "1903e8" +
+						"4162" + "c249010000000000000000" +
+				"02" +
+				"d87980" +
+			"ff",
+		},
+		{
+			MetaData: func()nftmeta.MetaData{
+				var metadata nftmeta.MetaData
+				metadata.SetDescription(strings.Repeat("x", 70))
+
+				return metadata
+			}(),
+			Version: 1,
+			// A bytestring longer than 64 bytes is split into 64-byte chunks.
+			Expected: "d8799f" +
+				"a1" +
+					"4b6465736372697074696f6e" +
+					"5f" + "5840" + strings.Repeat("78", 64) + "46" + strings.Repeat("78", 6) + "ff" +
+				"01" +
+				"d87980" +
+			"ff",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var datum nftmeta.CIP68Datum
+		datum.SetMetaData(test.MetaData)
+		datum.SetVersion(test.Version)
+
+		actual, err := datum.MarshalCBOR()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		{
+			expected := test.Expected
+
+			if expected != hex.EncodeToString(actual) {
+				t.Errorf("For test #%d, the actual CBOR is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %x", actual)
+				continue
+			}
+		}
+
+		{
+			var decoded nftmeta.CIP68Datum
+
+			if err := decoded.UnmarshalCBOR(actual); nil != err {
+				t.Errorf("For test #%d, did not expect an error when decoding but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected, actual := test.Version, decoded.Version(); expected != actual {
+				t.Errorf("For test #%d, the actual decoded version is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %d", expected)
+				t.Logf("ACTUAL:   %d", actual)
+				continue
+			}
+
+			expected, err := json.Marshal(test.MetaData)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			actual, err := json.Marshal(decoded.MetaData())
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual decoded metadata is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %s", expected)
+				t.Logf("ACTUAL:   %s", actual)
+				continue
+			}
+		}
+	}
+}
+
+func TestCIP68Datum_UnmarshalCBOR_fail(t *testing.T) {
+
+	tests := []struct{
+		CBOR string
+	}{
+		{
+			CBOR: "",
+		},
+		{
+			CBOR: "d87980", // Constr 0 []
+		},
+		{
+			CBOR: "d87a9fa001d87980ff", // Constr 1 [{}, 1, Constr 0 []]
+		},
+		{
+			CBOR: "d8799fa020d87980ff", // Constr 0 [{}, -1, Constr 0 []]
+		},
+		{
+			CBOR: "d8799fa001d87980", // missing break
+		},
+		{
+			CBOR: "d8799fa001d87980ff00", // trailing bytes
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		data, err := hex.DecodeString(test.CBOR)
+		if nil != err {
+			t.Fatalf("For test #%d, bad test hex: %s", testNumber, err)
+		}
+
+		var datum nftmeta.CIP68Datum
+		if err := datum.UnmarshalCBOR(data); nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("CBOR: %s", test.CBOR)
+			continue
+		}
+	}
+}
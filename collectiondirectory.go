@@ -0,0 +1,161 @@
+package nftmeta
+
+import (
+	"fmt"
+	"math/big"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// TokenFileNaming is how the file of (the metadata of) a token is named, within the directory of a collection.
+type TokenFileNaming int
+
+const (
+	// TokenFileNamingDecimal names the file of a token its token-id in decimal — for example, "0", "1", "2", ….
+	TokenFileNamingDecimal TokenFileNaming = iota
+
+	// TokenFileNamingDecimalJSON names the file of a token its token-id in decimal, with ".json" — for example, "0.json", "1.json", "2.json", ….
+	TokenFileNamingDecimalJSON
+
+	// TokenFileNamingHex names the file of a token the way ERC-1155 substitutes "{id}" — its token-id as 64 lower-case hexadecimal digits.
+	// For example, token-id 1 is "0000000000000000000000000000000000000000000000000000000000000001".
+	TokenFileNamingHex
+
+	// TokenFileNamingHexJSON is like TokenFileNamingHex, but with ".json".
+	TokenFileNamingHexJSON
+)
+
+// FileName returns the name of the file of the token 'tokenID'.
+func (receiver TokenFileNaming) FileName(tokenID *big.Int) (string, error) {
+	if nil == tokenID {
+		return "", errNilTokenID
+	}
+	if tokenID.Sign() < 0 {
+		return "", erorr.Errorf("nftmeta: token-id %s is negative", tokenID)
+	}
+
+	switch receiver {
+	case TokenFileNamingDecimal:
+		return tokenID.String(), nil
+	case TokenFileNamingDecimalJSON:
+		return tokenID.String() + ".json", nil
+	case TokenFileNamingHex, TokenFileNamingHexJSON:
+		if 256 < tokenID.BitLen() {
+			return "", erorr.Errorf("nftmeta: token-id %s does not fit into 256 bits", tokenID)
+		}
+
+		name := fmt.Sprintf("%064x", tokenID)
+		if TokenFileNamingHexJSON == receiver {
+			name += ".json"
+		}
+		return name, nil
+	default:
+		return "", erorr.Errorf("nftmeta: unknown token file naming %d", int(receiver))
+	}
+}
+
+// CollectionDirectory is the directory of the metadata of (the tokens of) a collection — which, once added to IPFS, gives the base-URI of the collection:
+//
+//	ipfs://<cid>/
+//
+// Each token's metadata is a file in the directory, named according to the TokenFileNaming.
+//
+// The CIDs are computed the same way `ipfs add -r` does it, so they can be known before uploading.
+type CollectionDirectory struct {
+	fileNaming TokenFileNaming
+	tokens     map[string]collectionDirectoryToken
+}
+
+type collectionDirectoryToken struct {
+	tokenID  *big.Int
+	metadata MetaData
+}
+
+// FileNaming returns how the files of the tokens are named.
+func (receiver CollectionDirectory) FileNaming() TokenFileNaming {
+	return receiver.fileNaming
+}
+
+// SetFileNaming sets how the files of the tokens are named.
+func (receiver *CollectionDirectory) SetFileNaming(value TokenFileNaming) {
+	receiver.fileNaming = value
+}
+
+// Len returns the number of tokens.
+func (receiver CollectionDirectory) Len() int {
+	return len(receiver.tokens)
+}
+
+// Add adds the metadata of the token 'tokenID'.
+//
+// It returns an error if the collection already has the token.
+func (receiver *CollectionDirectory) Add(tokenID *big.Int, metadata MetaData) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+	if nil == tokenID {
+		return errNilTokenID
+	}
+	if tokenID.Sign() < 0 {
+		return erorr.Errorf("nftmeta: token-id %s is negative", tokenID)
+	}
+
+	key := tokenID.String()
+	if _, found := receiver.tokens[key]; found {
+		return erorr.Errorf("nftmeta: collection already has token-id %s", key)
+	}
+
+	if nil == receiver.tokens {
+		receiver.tokens = map[string]collectionDirectoryToken{}
+	}
+	receiver.tokens[key] = collectionDirectoryToken{
+		tokenID:  big.NewInt(0).Set(tokenID),
+		metadata: metadata,
+	}
+	return nil
+}
+
+// Files returns the files of the directory — keyed by file name — each of which is the JSON of the metadata of a token (see MarshalJSON).
+func (receiver CollectionDirectory) Files() (map[string][]byte, error) {
+	var files = map[string][]byte{}
+
+	for _, token := range receiver.tokens {
+		name, err := receiver.fileNaming.FileName(token.tokenID)
+		if nil != err {
+			return nil, err
+		}
+
+		data, err := token.metadata.MarshalJSON()
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling the metadata of token-id %s: %w", token.tokenID, err)
+		}
+
+		files[name] = data
+	}
+
+	return files, nil
+}
+
+// CIDv0 returns the CIDv0 that the directory has, once added to IPFS (with `ipfs add -r`).
+//
+// See IPFSDirectoryCIDv0.
+func (receiver CollectionDirectory) CIDv0() (string, error) {
+	files, err := receiver.Files()
+	if nil != err {
+		return "", err
+	}
+
+	return IPFSDirectoryCIDv0(files)
+}
+
+// CIDv1 returns the CIDv1 that the directory has, once added to IPFS (with `ipfs add -r --cid-version=1`).
+//
+// See IPFSDirectoryCIDv1.
+func (receiver CollectionDirectory) CIDv1(rawLeaves bool) (string, error) {
+	files, err := receiver.Files()
+	if nil != err {
+		return "", err
+	}
+
+	return IPFSDirectoryCIDv1(files, rawLeaves)
+}
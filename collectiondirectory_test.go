@@ -0,0 +1,161 @@
+package nftmeta_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestTokenFileNaming_FileName(t *testing.T) {
+
+	tests := []struct{
+		FileNaming nftmeta.TokenFileNaming
+		TokenID *big.Int
+		Expected string
+	}{
+		{
+			FileNaming: nftmeta.TokenFileNamingDecimal,
+			TokenID:    big.NewInt(0),
+			Expected:   "0",
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingDecimal,
+			TokenID:    big.NewInt(9999),
+			Expected:   "9999",
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingDecimalJSON,
+			TokenID:    big.NewInt(42),
+			Expected:   "42.json",
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingHex,
+			TokenID:    big.NewInt(314592),
+			Expected:   "000000000000000000000000000000000000000000000000000000000004cce0",
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingHexJSON,
+			TokenID:    big.NewInt(1),
+			Expected:   "0000000000000000000000000000000000000000000000000000000000000001.json",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := test.FileNaming.FileName(test.TokenID)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, the actual file name is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestTokenFileNaming_FileName_bad(t *testing.T) {
+
+	tooBig := big.NewInt(0).Lsh(big.NewInt(1), 256)
+
+	tests := []struct{
+		FileNaming nftmeta.TokenFileNaming
+		TokenID *big.Int
+	}{
+		{
+			FileNaming: nftmeta.TokenFileNamingDecimal,
+			TokenID:    nil,
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingDecimal,
+			TokenID:    big.NewInt(-1),
+		},
+		{
+			FileNaming: nftmeta.TokenFileNamingHex,
+			TokenID:    tooBig,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := test.FileNaming.FileName(test.TokenID)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			continue
+		}
+	}
+}
+
+func TestCollectionDirectory(t *testing.T) {
+
+	var collection nftmeta.CollectionDirectory
+	collection.SetFileNaming(nftmeta.TokenFileNamingDecimalJSON)
+
+	var files = map[string][]byte{}
+
+	for i := int64(0); i < 3; i++ {
+		var metadata nftmeta.MetaData
+		metadata.SetName("Token #" + big.NewInt(i).String())
+
+		if err := collection.Add(big.NewInt(i), metadata); nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		data, err := metadata.MarshalJSON()
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+		files[big.NewInt(i).String()+".json"] = data
+	}
+
+	if expected, actual := 3, collection.Len(); expected != actual {
+		t.Errorf("The actual number of tokens is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+
+	if err := collection.Add(big.NewInt(1), nftmeta.MetaData{}); nil == err {
+		t.Errorf("Expected an error adding a token-id a second time, but did not actually get one.")
+	}
+
+	{
+		expected, err := nftmeta.IPFSDirectoryCIDv0(files)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		actual, err := collection.CIDv0()
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if expected != actual {
+			t.Errorf("The actual CIDv0 is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+
+	{
+		expected, err := nftmeta.IPFSDirectoryCIDv1(files, true)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		actual, err := collection.CIDv1(true)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if expected != actual {
+			t.Errorf("The actual CIDv1 is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+}
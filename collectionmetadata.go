@@ -0,0 +1,242 @@
+package nftmeta
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// maxSellerFeeBasisPoints is 100% expressed in basis-points.
+const maxSellerFeeBasisPoints = 10000
+
+// CollectionMetaData represents collection-level NFT metadata — i.e., what the `contractURI()` of an NFT contract points to.
+//
+// It is what OpenSea (and other marketplaces) use for the collection as a whole, rather than for a single token.
+type CollectionMetaData struct {
+	bannerImage          opt.Optional[string]
+	description          opt.Optional[string]
+	externalLink         opt.Optional[string]
+	featuredImage        opt.Optional[string]
+	feeRecipient         opt.Optional[Address]
+	image                opt.Optional[string]
+	name                 opt.Optional[string]
+	sellerFeeBasisPoints opt.Optional[uint64]
+	collaborators      []Address
+}
+
+func (receiver CollectionMetaData) MarshalJSON() ([]byte, error) {
+
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	var feeRecipient opt.Optional[string]
+	if value, something := receiver.feeRecipient.Get(); something {
+		feeRecipient = opt.Something(value.String())
+	}
+
+	p = append(p, '{')
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"banner_image",   receiver.bannerImage},
+		{"description",    receiver.description},
+		{"external_link",  receiver.externalLink},
+		{"featured_image", receiver.featuredImage},
+		{"fee_recipient",  feeRecipient},
+		{"image",          receiver.image},
+		{"name",           receiver.name},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	{
+		value, something := receiver.sellerFeeBasisPoints.Get()
+		if something {
+			if maxSellerFeeBasisPoints < value {
+				return nil, errSellerFeeBasisPointsTooBig
+			}
+
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			p = append(p, `"seller_fee_basis_points":`...)
+			p = strconv.AppendUint(p, value, 10)
+		}
+	}
+
+	if 0 < len(receiver.collaborators) {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		bytes, err := json.Marshal(receiver.collaborators)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling collaborators: %w", err)
+		}
+
+		p = append(p, `"collaborators":`...)
+		p = append(p, bytes...)
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func (receiver *CollectionMetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		BannerImage          *string   `json:"banner_image"`
+		Description          *string   `json:"description"`
+		ExternalLink         *string   `json:"external_link"`
+		FeaturedImage        *string   `json:"featured_image"`
+		FeeRecipient         *Address  `json:"fee_recipient"`
+		Image                *string   `json:"image"`
+		Name                 *string   `json:"name"`
+		SellerFeeBasisPoints *uint64   `json:"seller_fee_basis_points"`
+		Collaborators        []Address `json:"collaborators"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling collection metadata: %w", err)
+	}
+
+	var metadata CollectionMetaData
+
+	metadata.bannerImage   = optionalString(raw.BannerImage)
+	metadata.description   = optionalString(raw.Description)
+	metadata.externalLink  = optionalString(raw.ExternalLink)
+	metadata.featuredImage = optionalString(raw.FeaturedImage)
+	metadata.image         = optionalString(raw.Image)
+	metadata.name          = optionalString(raw.Name)
+
+	if nil != raw.FeeRecipient {
+		metadata.SetFeeRecipient(*raw.FeeRecipient)
+	}
+
+	if nil != raw.SellerFeeBasisPoints {
+		if err := metadata.SetSellerFeeBasisPoints(*raw.SellerFeeBasisPoints); nil != err {
+			return err
+		}
+	}
+
+	metadata.collaborators = raw.Collaborators
+
+	*receiver = metadata
+	return nil
+}
+
+func (receiver CollectionMetaData) BannerImage() opt.Optional[string] {
+	return receiver.bannerImage
+}
+
+func (receiver CollectionMetaData) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver CollectionMetaData) ExternalLink() opt.Optional[string] {
+	return receiver.externalLink
+}
+
+func (receiver CollectionMetaData) FeaturedImage() opt.Optional[string] {
+	return receiver.featuredImage
+}
+
+func (receiver CollectionMetaData) FeeRecipient() opt.Optional[Address] {
+	return receiver.feeRecipient
+}
+
+func (receiver CollectionMetaData) Image() opt.Optional[string] {
+	return receiver.image
+}
+
+func (receiver CollectionMetaData) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver CollectionMetaData) SellerFeeBasisPoints() opt.Optional[uint64] {
+	return receiver.sellerFeeBasisPoints
+}
+
+// Collaborators returns (a copy of) the collaborators.
+func (receiver CollectionMetaData) Collaborators() []Address {
+	if len(receiver.collaborators) <= 0 {
+		return nil
+	}
+
+	collaborators := make([]Address, len(receiver.collaborators))
+	copy(collaborators, receiver.collaborators)
+	return collaborators
+}
+
+func (receiver *CollectionMetaData) SetBannerImage(value string) {
+	receiver.bannerImage = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetExternalLink(value string) {
+	receiver.externalLink = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetFeaturedImage(value string) {
+	receiver.featuredImage = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetFeeRecipient(value Address) {
+	receiver.feeRecipient = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetImage(value string) {
+	receiver.image = opt.Something(value)
+}
+
+func (receiver *CollectionMetaData) SetName(value string) {
+	receiver.name = opt.Something(value)
+}
+
+// SetSellerFeeBasisPoints sets the "seller_fee_basis_points".
+//
+// 1 basis-point is 0.01%. So, for example, 250 is 2.5%.
+//
+// SetSellerFeeBasisPoints returns an error if 'value' is greater than 10000 (i.e., 100%).
+func (receiver *CollectionMetaData) SetSellerFeeBasisPoints(value uint64) error {
+	if maxSellerFeeBasisPoints < value {
+		return errSellerFeeBasisPointsTooBig
+	}
+
+	receiver.sellerFeeBasisPoints = opt.Something(value)
+	return nil
+}
+
+func (receiver *CollectionMetaData) AppendCollaborator(value Address) {
+	receiver.collaborators = append(receiver.collaborators, value)
+}
@@ -0,0 +1,142 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestCollectionMetaData_MarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		CollectionMetaData nftmeta.CollectionMetaData
+		Expected []byte
+	}{
+		{
+			CollectionMetaData: nftmeta.CollectionMetaData{},
+			Expected: []byte(`{}`),
+		},
+		{
+			CollectionMetaData: func()nftmeta.CollectionMetaData{
+				var metadata nftmeta.CollectionMetaData
+				metadata.SetName("OpenSea Creatures")
+
+				return metadata
+			}(),
+			Expected: []byte(`{"name":"OpenSea Creatures"}`),
+		},
+		{
+			CollectionMetaData: func()nftmeta.CollectionMetaData{
+				var metadata nftmeta.CollectionMetaData
+				metadata.SetName("OpenSea Creatures")
+				metadata.SetDescription("OpenSea Creatures are adorable aquatic beings primarily for demonstrating what can be done using the OpenSea platform.")
+				metadata.SetImage("https://external-link-url.com/image.png")
+				metadata.SetBannerImage("https://external-link-url.com/banner-image.png")
+				metadata.SetFeaturedImage("https://external-link-url.com/featured-image.png")
+				metadata.SetExternalLink("https://external-link-url.com")
+				metadata.AppendCollaborator(nftmeta.Address{})
+				if err := metadata.SetSellerFeeBasisPoints(100); nil != err {
+					panic(err)
+				}
+				{
+					address, err := nftmeta.ParseAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+					if nil != err {
+						panic(err)
+					}
+					metadata.SetFeeRecipient(address)
+				}
+
+				return metadata
+			}(),
+			Expected: []byte(`{"banner_image":"https://external-link-url.com/banner-image.png","description":"OpenSea Creatures are adorable aquatic beings primarily for demonstrating what can be done using the OpenSea platform.","external_link":"https://external-link-url.com","featured_image":"https://external-link-url.com/featured-image.png","fee_recipient":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed","image":"https://external-link-url.com/image.png","name":"OpenSea Creatures","seller_fee_basis_points":100,"collaborators":["0x0000000000000000000000000000000000000000"]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := json.Marshal(test.CollectionMetaData)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("COLLECTION-METADATA: %#v", test.CollectionMetaData)
+			continue
+		}
+
+		{
+			expected := test.Expected
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				t.Logf("COLLECTION-METADATA: %#v", test.CollectionMetaData)
+				continue
+			}
+		}
+
+		{
+			var metadata nftmeta.CollectionMetaData
+
+			if err := json.Unmarshal(actual, &metadata); nil != err {
+				t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			again, err := json.Marshal(metadata)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(actual, again) {
+				t.Errorf("For test #%d, the re-marshaled json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", actual)
+				t.Logf("ACTUAL:   %q", again)
+				continue
+			}
+		}
+	}
+}
+
+func TestCollectionMetaData_UnmarshalJSON_fail(t *testing.T) {
+
+	tests := []struct{
+		JSON []byte
+	}{
+		{
+			JSON: []byte(`{"seller_fee_basis_points":10001}`),
+		},
+		{
+			JSON: []byte(`{"seller_fee_basis_points":-1}`),
+		},
+		{
+			JSON: []byte(`{"fee_recipient":"0xA97F337c39cccE66adfeCB2BF99C1DdC54C2D72"}`),
+		},
+		{
+			JSON: []byte(`{"fee_recipient":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"}`),
+		},
+		{
+			JSON: []byte(`{"fee_recipient":"0xG97F337c39cccE66adfeCB2BF99C1DdC54C2D721"}`),
+		},
+		{
+			JSON: []byte(`{"collaborators":["vitalik.eth"]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var metadata nftmeta.CollectionMetaData
+
+		err := json.Unmarshal(test.JSON, &metadata)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("JSON:\n%s", test.JSON)
+			continue
+		}
+	}
+}
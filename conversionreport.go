@@ -0,0 +1,130 @@
+package nftmeta
+
+import (
+	"strings"
+)
+
+// ConversionNoteKind says what happened to a field during a conversion.
+type ConversionNoteKind int
+
+const (
+	// ConversionDropped means the field has no equivalent in the other format, and was not carried over.
+	ConversionDropped ConversionNoteKind = iota
+
+	// ConversionLossy means the field was carried over, but not exactly — so converting back would not give the original.
+	ConversionLossy
+)
+
+func (receiver ConversionNoteKind) String() string {
+	switch receiver {
+	case ConversionDropped:
+		return "dropped"
+	case ConversionLossy:
+		return "lossy"
+	default:
+		return "unknown"
+	}
+}
+
+// ConversionNote is a single entry of a ConversionReport.
+//
+// Field is named as it is in the JSON of Format. For example, if Format is FormatMetaplex, then Field might be "seller_fee_basis_points".
+// When decoding into MetaData, Format is the format decoded from; when encoding from MetaData, Format is FormatERC721 (i.e., the field is a field of MetaData).
+type ConversionNote struct {
+	Format Format
+	Field  string
+	Kind   ConversionNoteKind
+	Reason string
+}
+
+func (receiver ConversionNote) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(receiver.Format.String())
+	builder.WriteByte(' ')
+	builder.WriteString(receiver.Field)
+	builder.WriteString(": ")
+	builder.WriteString(receiver.Kind.String())
+	if "" != receiver.Reason {
+		builder.WriteString(": ")
+		builder.WriteString(receiver.Reason)
+	}
+
+	return builder.String()
+}
+
+// ConversionReport reports which fields were dropped, or were carried over lossily, by a conversion.
+//
+// See Decode, Encode, and Convert.
+type ConversionReport struct {
+	From  Format
+	To    Format
+	notes []ConversionNote
+}
+
+// AddDropped adds a note that the 'field' (of 'format') was dropped.
+//
+// It is meant for the DecodeFunc and EncodeFunc of formats registered with RegisterFormat.
+func (receiver *ConversionReport) AddDropped(format Format, field string, reason string) {
+	receiver.notes = append(receiver.notes, ConversionNote{Format: format, Field: field, Kind: ConversionDropped, Reason: reason})
+}
+
+// AddLossy adds a note that the 'field' (of 'format') was carried over lossily.
+//
+// It is meant for the DecodeFunc and EncodeFunc of formats registered with RegisterFormat.
+func (receiver *ConversionReport) AddLossy(format Format, field string, reason string) {
+	receiver.notes = append(receiver.notes, ConversionNote{Format: format, Field: field, Kind: ConversionLossy, Reason: reason})
+}
+
+// Dropped returns the notes about fields that were dropped.
+func (receiver ConversionReport) Dropped() []ConversionNote {
+	return receiver.notesOfKind(ConversionDropped)
+}
+
+// Lossy returns the notes about fields that were carried over lossily.
+func (receiver ConversionReport) Lossy() []ConversionNote {
+	return receiver.notesOfKind(ConversionLossy)
+}
+
+// Notes returns (a copy of) all the notes, in the order they were added.
+func (receiver ConversionReport) Notes() []ConversionNote {
+	if len(receiver.notes) <= 0 {
+		return nil
+	}
+
+	notes := make([]ConversionNote, len(receiver.notes))
+	copy(notes, receiver.notes)
+	return notes
+}
+
+// IsLossless returns true if nothing was dropped, and nothing was carried over lossily.
+func (receiver ConversionReport) IsLossless() bool {
+	return len(receiver.notes) <= 0
+}
+
+func (receiver ConversionReport) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(receiver.From.String())
+	builder.WriteString(" -> ")
+	builder.WriteString(receiver.To.String())
+	if receiver.IsLossless() {
+		builder.WriteString(": lossless")
+	}
+	for _, note := range receiver.notes {
+		builder.WriteString("\n\t")
+		builder.WriteString(note.String())
+	}
+
+	return builder.String()
+}
+
+func (receiver ConversionReport) notesOfKind(kind ConversionNoteKind) []ConversionNote {
+	var notes []ConversionNote
+	for _, note := range receiver.notes {
+		if kind == note.Kind {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
@@ -0,0 +1,576 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// DecodeFunc decodes the JSON 'data' of a format into MetaData.
+// It adds to 'report' a note for each field it drops or carries over lossily.
+type DecodeFunc func(data []byte, report *ConversionReport) (MetaData, error)
+
+// EncodeFunc encodes 'metadata' into the JSON of a format.
+// It adds to 'report' a note for each field it drops or carries over lossily.
+type EncodeFunc func(metadata MetaData, report *ConversionReport) ([]byte, error)
+
+type formatCodec struct {
+	decode DecodeFunc
+	encode EncodeFunc
+}
+
+var (
+	formatCodecsMutex sync.RWMutex
+	formatCodecs = map[Format]formatCodec{
+		FormatARC3:     {decodeARC3,     encodeARC3},
+		FormatARC69:    {decodeARC69,    encodeARC69},
+		FormatCIP25:    {decodeCIP25,    encodeCIP25},
+		FormatERC1155:  {decodeERC1155,  encodeERC1155},
+		FormatERC721:   {decodeERC721,   encodeERC721},
+		FormatMetaplex: {decodeMetaplex, encodeMetaplex},
+		FormatTZIP21:   {decodeTZIP21,   encodeTZIP21},
+		FormatXLS24d:   {decodeXLS24d,   encodeXLS24d},
+	}
+)
+
+// RegisterFormat registers (or replaces) how 'format' is decoded into, and encoded from, MetaData — so that Decode, Encode, and Convert can be used with it.
+func RegisterFormat(format Format, decode DecodeFunc, encode EncodeFunc) {
+	formatCodecsMutex.Lock()
+	defer formatCodecsMutex.Unlock()
+
+	formatCodecs[format] = formatCodec{decode: decode, encode: encode}
+}
+
+// Formats returns the formats that Decode, Encode, and Convert can be used with.
+func Formats() []Format {
+	formatCodecsMutex.RLock()
+	defer formatCodecsMutex.RUnlock()
+
+	var formats []Format
+	for format := range formatCodecs {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		return formats[i] < formats[j]
+	})
+
+	return formats
+}
+
+func lookupFormat(format Format) (formatCodec, error) {
+	formatCodecsMutex.RLock()
+	defer formatCodecsMutex.RUnlock()
+
+	codec, found := formatCodecs[format]
+	if !found {
+		return formatCodec{}, erorr.Errorf("nftmeta: unknown format %q", format)
+	}
+
+	return codec, nil
+}
+
+// Decode decodes the JSON 'data' of 'format' into MetaData.
+//
+// The report says which fields of 'data' were dropped (because MetaData has no equivalent) or were carried over lossily.
+//
+// For FormatCIP25, 'data' can be either the whole CIP-25 metadata (with or without the 721 label) holding exactly one asset,
+// or just the metadata of one asset (i.e., what goes under <policy_id>.<asset_name>).
+func Decode(format Format, data []byte) (MetaData, ConversionReport, error) {
+	var report = ConversionReport{From: format, To: FormatERC721}
+
+	codec, err := lookupFormat(format)
+	if nil != err {
+		return MetaData{}, report, err
+	}
+
+	metadata, err := codec.decode(data, &report)
+	if nil != err {
+		return MetaData{}, report, err
+	}
+
+	return metadata, report, nil
+}
+
+// Encode encodes 'metadata' into the JSON of 'format'.
+//
+// The report says which fields of 'metadata' were dropped (because 'format' has no equivalent) or were carried over lossily.
+//
+// For FormatCIP25, the result is the metadata of one asset (i.e., what goes under <policy_id>.<asset_name>) — since MetaData has no policy-id or asset-name. (See CIP25 for the whole thing.)
+func Encode(format Format, metadata MetaData) ([]byte, ConversionReport, error) {
+	var report = ConversionReport{From: FormatERC721, To: format}
+
+	codec, err := lookupFormat(format)
+	if nil != err {
+		return nil, report, err
+	}
+
+	p, err := codec.encode(metadata, &report)
+	if nil != err {
+		return nil, report, err
+	}
+
+	return p, report, nil
+}
+
+// Convert converts the JSON 'data' of format 'from' into the JSON of format 'to' — by way of MetaData.
+//
+// The report holds the notes of both the decoding (whose fields are named as in 'from') and the encoding (whose fields are named as in MetaData).
+func Convert(from Format, to Format, data []byte) ([]byte, ConversionReport, error) {
+	var report = ConversionReport{From: from, To: to}
+
+	metadata, decodeReport, err := Decode(from, data)
+	report.notes = append(report.notes, decodeReport.notes...)
+	if nil != err {
+		return nil, report, err
+	}
+
+	p, encodeReport, err := Encode(to, metadata)
+	report.notes = append(report.notes, encodeReport.notes...)
+	if nil != err {
+		return nil, report, err
+	}
+
+	return p, report, nil
+}
+
+// reportUnmappedKeys adds a dropped note for each top-level key of the JSON object 'data' that is not one of 'mapped'.
+func reportUnmappedKeys(report *ConversionReport, format Format, data []byte, mapped ...string) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling %s metadata: %w", format, err)
+	}
+
+	var keys []string
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	reportUnmappedNames(report, format, "", keys, mapped...)
+	return nil
+}
+
+func reportUnmappedNames(report *ConversionReport, format Format, prefix string, names []string, mapped ...string) {
+	sort.Strings(names)
+
+	for _, name := range names {
+		if containsString(mapped, name) {
+			continue
+		}
+
+		report.AddDropped(format, prefix+name, "no MetaData equivalent")
+	}
+}
+
+// reportDroppedMetaData adds a dropped note for each of the fields (of 'metadata') named 'names' that is something.
+func reportDroppedMetaData(report *ConversionReport, metadata MetaData, format Format, names ...string) {
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"animation_url",    metadata.animationURL},
+		{"background_color", metadata.backgroundColor},
+		{"description",      metadata.description},
+		{"external_link",    metadata.externalLink},
+		{"image",            metadata.image},
+		{"image_data",       metadata.imageData},
+		{"name",             metadata.name},
+		{"youtube_url",      metadata.youtubeURL},
+	}{
+		if !containsString(names, field.name) || field.value.IsNothing() {
+			continue
+		}
+
+		report.AddDropped(FormatERC721, field.name, "no "+format.String()+" equivalent")
+	}
+}
+
+// reportDroppedDisplayTypes adds a lossy note for each attribute (of 'metadata') that has a display-type — for formats whose attributes have no display-type.
+func reportDroppedDisplayTypes(report *ConversionReport, metadata MetaData, format Format) {
+	for index, attribute := range metadata.attributes {
+		if attribute.displayType.IsNothing() {
+			continue
+		}
+
+		report.AddLossy(FormatERC721, "attributes["+strconv.Itoa(index)+"].display_type", "no "+format.String()+" equivalent")
+	}
+}
+
+// reportAttributesAsMap adds a lossy note (if there are attributes) that the attributes became a map of trait-type to value.
+func reportAttributesAsMap(report *ConversionReport, metadata MetaData, format Format, into string) {
+	if len(metadata.attributes) <= 0 {
+		return
+	}
+
+	report.AddLossy(FormatERC721, "attributes", "became "+format.String()+" "+strconv.Quote(into)+" (a map of trait-type to value), which does not keep their order")
+	reportDroppedDisplayTypes(report, metadata, format)
+}
+
+// propertiesAttributes returns the attributes for the "properties" entries whose value is a string or number (ordered by name);
+// and adds a dropped note for each other "properties" entry.
+func propertiesAttributes(report *ConversionReport, format Format, properties map[string]json.RawMessage) []Attribute {
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var attributes []Attribute
+	for _, name := range names {
+		value, err := unmarshalAttributeValue(properties[name])
+		if nil != err {
+			report.AddDropped(format, "properties."+name, "not a string or number, so cannot be an attribute")
+			continue
+		}
+
+		attributes = append(attributes, Attribute{
+			traitType: opt.Something(name),
+			value:     value,
+		})
+	}
+
+	return attributes
+}
+
+func decodeERC721(data []byte, report *ConversionReport) (MetaData, error) {
+	var metadata MetaData
+	if err := metadata.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatERC721, data,
+		"animation_url", "background_color", "description", "external_link", "image", "image_data", "name", "youtube_url", "attributes",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	return metadata, nil
+}
+
+func encodeERC721(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	return metadata.MarshalJSON()
+}
+
+// decodeERC1155 decodes ERC-1155 metadata — which is ERC-721 metadata plus "decimals", "properties", and "localization".
+// If there are no "attributes", then the "properties" entries whose value is a string or number become the attributes.
+func decodeERC1155(data []byte, report *ConversionReport) (MetaData, error) {
+	var metadata MetaData
+	if err := metadata.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	var mapped = []string{"animation_url", "background_color", "description", "external_link", "image", "image_data", "name", "youtube_url", "attributes"}
+
+	if len(metadata.attributes) <= 0 {
+		var raw struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &raw); nil != err {
+			return MetaData{}, erorr.Errorf("nftmeta: problem json-unmarshaling erc-1155 metadata: %w", err)
+		}
+
+		if 0 < len(raw.Properties) {
+			metadata.attributes = propertiesAttributes(report, FormatERC1155, raw.Properties)
+			mapped = append(mapped, "properties")
+		}
+	}
+
+	if err := reportUnmappedKeys(report, FormatERC1155, data, mapped...); nil != err {
+		return MetaData{}, err
+	}
+
+	return metadata, nil
+}
+
+// encodeERC1155 encodes ERC-1155 metadata — which is ERC-721 metadata, but with the attributes as the "properties" (a map of trait-type to value), as the ERC-1155 metadata JSON schema has them.
+// (MetaData has nothing to go into the "decimals" or "localization".)
+func encodeERC1155(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportAttributesAsMap(report, metadata, FormatERC1155, "properties")
+
+	attributes := metadata.attributes
+	metadata.attributes = nil
+
+	p, err := metadata.MarshalJSON()
+	if nil != err {
+		return nil, err
+	}
+	if len(attributes) <= 0 {
+		return p, nil
+	}
+
+	// Replace the closing '}' with the "properties".
+	p = p[:len(p)-1]
+	if 1 < len(p) {
+		p = append(p, ',')
+	}
+	p, err = appendAttributeMap(p, "properties", attributes)
+	if nil != err {
+		return nil, err
+	}
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func decodeMetaplex(data []byte, report *ConversionReport) (MetaData, error) {
+	var metaplex MetaplexMetaData
+	if err := metaplex.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatMetaplex, data,
+		"animation_url", "description", "external_url", "image", "name", "attributes",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	return metaplex.MetaData(), nil
+}
+
+func encodeMetaplex(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatMetaplex, "background_color", "image_data", "youtube_url")
+
+	return MetaplexMetaDataFrom(metadata).MarshalJSON()
+}
+
+func decodeTZIP21(data []byte, report *ConversionReport) (MetaData, error) {
+	var tzip21 TZIP21MetaData
+	if err := tzip21.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatTZIP21, data,
+		"artifactUri", "description", "displayUri", "externalUri", "name", "attributes",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	return tzip21.MetaData(), nil
+}
+
+func encodeTZIP21(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatTZIP21, "background_color", "image_data", "youtube_url")
+
+	return TZIP21MetaDataFrom(metadata).MarshalJSON()
+}
+
+func decodeARC3(data []byte, report *ConversionReport) (MetaData, error) {
+	var arc3 ARC3MetaData
+	if err := arc3.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatARC3, data,
+		"animation_url", "background_color", "description", "external_url", "image", "name", "properties",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	// The "properties" entries that did not become attributes.
+	{
+		var names []string
+		for name := range arc3.properties {
+			names = append(names, name)
+		}
+		reportUnmappedNames(report, FormatARC3, "properties.", names)
+	}
+
+	return arc3.MetaData(), nil
+}
+
+func encodeARC3(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatARC3, "image_data", "youtube_url")
+	reportAttributesAsMap(report, metadata, FormatARC3, "properties")
+
+	return ARC3MetaDataFrom(metadata).MarshalJSON()
+}
+
+func decodeARC69(data []byte, report *ConversionReport) (MetaData, error) {
+	metadata, err := UnmarshalARC69(data)
+	if nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatARC69, data,
+		"standard", "description", "external_url", "media_url", "properties", "attributes",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	// The "properties" entries that did not become attributes.
+	{
+		var raw struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(data), &raw); nil != err {
+			return MetaData{}, erorr.Errorf("nftmeta: problem json-unmarshaling arc-69 metadata: %w", err)
+		}
+
+		var skipped []string
+		for name, value := range raw.Properties {
+			if _, err := unmarshalAttributeValue(value); nil != err {
+				skipped = append(skipped, name)
+			}
+		}
+		reportUnmappedNames(report, FormatARC69, "properties.", skipped)
+	}
+
+	return metadata, nil
+}
+
+func encodeARC69(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatARC69, "animation_url", "background_color", "image_data", "name", "youtube_url")
+	reportAttributesAsMap(report, metadata, FormatARC69, "properties")
+
+	return MarshalARC69(metadata)
+}
+
+func decodeXLS24d(data []byte, report *ConversionReport) (MetaData, error) {
+	var xls24d XLS24dMetaData
+	if err := xls24d.UnmarshalJSON(data); nil != err {
+		return MetaData{}, err
+	}
+
+	if err := reportUnmappedKeys(report, FormatXLS24d, data,
+		"animation", "description", "image", "name", "attributes",
+	); nil != err {
+		return MetaData{}, err
+	}
+
+	for index, attribute := range xls24d.attributes {
+		if attribute.description.IsNothing() {
+			continue
+		}
+
+		report.AddLossy(FormatXLS24d, "attributes["+strconv.Itoa(index)+"].description", "no MetaData equivalent")
+	}
+
+	return xls24d.MetaData(), nil
+}
+
+func encodeXLS24d(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatXLS24d, "background_color", "external_link", "image_data", "youtube_url")
+
+	return XLS24dMetaDataFrom(metadata).MarshalJSON()
+}
+
+// decodeCIP25 decodes either the whole CIP-25 metadata (with or without the 721 label) holding exactly one asset,
+// or just the metadata of one asset.
+func decodeCIP25(data []byte, report *ConversionReport) (MetaData, error) {
+	var object map[string]interface{}
+	{
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); nil != err {
+			return MetaData{}, erorr.Errorf("nftmeta: problem json-unmarshaling cip-25 metadata: %w", err)
+		}
+	}
+
+	if label, found := object[cip25Label]; found {
+		casted, ok := label.(map[string]interface{})
+		if !ok {
+			return MetaData{}, erorr.Errorf("nftmeta: CIP-25 %q is a %T rather than a map", cip25Label, label)
+		}
+		object = casted
+	}
+
+	var asset map[string]interface{} = object
+
+	if isCIP25Policies(object) {
+		var assets []map[string]interface{}
+
+		for key, policy := range object {
+			if "version" == key {
+				continue
+			}
+
+			casted, ok := policy.(map[string]interface{})
+			if !ok {
+				return MetaData{}, erorr.Errorf("nftmeta: CIP-25 policy %q is a %T rather than a map", key, policy)
+			}
+
+			for name, value := range casted {
+				casted, ok := value.(map[string]interface{})
+				if !ok {
+					return MetaData{}, erorr.Errorf("nftmeta: CIP-25 asset %q is a %T rather than a map", name, value)
+				}
+				assets = append(assets, casted)
+			}
+		}
+
+		if 1 != len(assets) {
+			return MetaData{}, erorr.Errorf("nftmeta: CIP-25 metadata holds %d assets rather than 1 (use CIP25 for more than one)", len(assets))
+		}
+
+		asset = assets[0]
+	}
+
+	metadata, err := cip25MetaData(asset)
+	if nil != err {
+		return MetaData{}, err
+	}
+
+	{
+		var keys []string
+		for key := range asset {
+			keys = append(keys, key)
+		}
+		reportUnmappedNames(report, FormatCIP25, "", keys, "name", "image", "description", "animation_url", "external_link", "attributes")
+	}
+
+	return metadata, nil
+}
+
+// isCIP25Policies returns true if (other than a "version") every key of 'object' is a policy-id.
+func isCIP25Policies(object map[string]interface{}) bool {
+	var found bool
+
+	for key := range object {
+		if "version" == key {
+			continue
+		}
+
+		str := strings.TrimPrefix(key, "0x")
+		if 56 != len(str) {
+			return false
+		}
+		if _, err := hex.DecodeString(str); nil != err {
+			return false
+		}
+		found = true
+	}
+
+	return found
+}
+
+func encodeCIP25(metadata MetaData, report *ConversionReport) ([]byte, error) {
+	reportDroppedMetaData(report, metadata, FormatCIP25, "background_color", "image_data", "youtube_url")
+	reportAttributesAsMap(report, metadata, FormatCIP25, "attributes")
+
+	for index, attribute := range metadata.attributes {
+		switch attribute.value.(type) {
+		case string, int64, uint64, *big.Int:
+		default:
+			report.AddLossy(FormatERC721, "attributes["+strconv.Itoa(index)+"].value", "CIP-25 has no fractional numbers, so it became text")
+		}
+	}
+
+	value, err := cip25AssetValue(metadata)
+	if nil != err {
+		return nil, err
+	}
+
+	p, err := json.Marshal(value)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-marshaling cip-25 metadata: %w", err)
+	}
+
+	return p, nil
+}
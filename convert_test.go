@@ -0,0 +1,181 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"reflect"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestConvert(t *testing.T) {
+
+	tests := []struct{
+		From nftmeta.Format
+		To   nftmeta.Format
+		Data []byte
+		Expected []byte
+		ExpectedNotes []string
+	}{
+		{
+			From: nftmeta.FormatMetaplex,
+			To:   nftmeta.FormatERC721,
+			Data: []byte(`{"name":"Solflare X NFT","symbol":"SFX","seller_fee_basis_points":500,"image":"https://www.arweave.net/abcd5678?ext=png","external_url":"https://solflare.com","attributes":[{"trait_type":"web","value":"yes"}],"properties":{"category":"image"}}`),
+			Expected: []byte(`{"external_link":"https://solflare.com","image":"https://www.arweave.net/abcd5678?ext=png","name":"Solflare X NFT","attributes":[{"trait_type":"web","value":"yes"}]}`),
+			ExpectedNotes: []string{
+				"metaplex properties: dropped: no MetaData equivalent",
+				"metaplex seller_fee_basis_points: dropped: no MetaData equivalent",
+				"metaplex symbol: dropped: no MetaData equivalent",
+			},
+		},
+		{
+			From: nftmeta.FormatERC721,
+			To:   nftmeta.FormatARC69,
+			Data: []byte(`{"name":"Tahoe","description":"Lake Tahoe","image":"ipfs://x","attributes":[{"display_type":"number","trait_type":"Edition","value":3}]}`),
+			Expected: []byte(`{"standard":"arc69","description":"Lake Tahoe","media_url":"ipfs://x","properties":{"Edition":3}}`),
+			ExpectedNotes: []string{
+				"erc721 name: dropped: no arc69 equivalent",
+				`erc721 attributes: lossy: became arc69 "properties" (a map of trait-type to value), which does not keep their order`,
+				"erc721 attributes[0].display_type: lossy: no arc69 equivalent",
+			},
+		},
+		{
+			From: nftmeta.FormatERC721,
+			To:   nftmeta.FormatTZIP21,
+			Data: []byte(`{"animation_url":"ipfs://anim","external_link":"https://example.com","image":"ipfs://img","name":"Tez","attributes":[{"display_type":"number","trait_type":"Edition","value":3}]}`),
+			Expected: []byte(`{"artifactUri":"ipfs://anim","displayUri":"ipfs://img","externalUri":"https://example.com","name":"Tez","attributes":[{"name":"Edition","value":3,"type":"number"}]}`),
+		},
+		{
+			From: nftmeta.FormatTZIP21,
+			To:   nftmeta.FormatERC721,
+			Data: []byte(`{"artifactUri":"ipfs://img","displayUri":"ipfs://img","thumbnailUri":"ipfs://thumb","name":"Tez","decimals":0}`),
+			Expected: []byte(`{"image":"ipfs://img","name":"Tez"}`),
+			ExpectedNotes: []string{
+				"tzip21 decimals: dropped: no MetaData equivalent",
+				"tzip21 thumbnailUri: dropped: no MetaData equivalent",
+			},
+		},
+		{
+			From: nftmeta.FormatCIP25,
+			To:   nftmeta.FormatERC721,
+			Data: []byte(`{"721":{"d5e6bf0500378d4f0da4e8dde6becec7621cd8cbf5cbb9b87013d4cc":{"SpaceBud0":{"name":"SpaceBud #0","image":["ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbz","di"],"mediaType":"image/png","attributes":{"Type":"Cat"}}},"version":"1.0"}}`),
+			Expected: []byte(`{"image":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","name":"SpaceBud #0","attributes":[{"trait_type":"Type","value":"Cat"}]}`),
+			ExpectedNotes: []string{
+				"cip25 mediaType: dropped: no MetaData equivalent",
+			},
+		},
+		{
+			From: nftmeta.FormatXLS24d,
+			To:   nftmeta.FormatERC1155,
+			Data: []byte(`{"nftType":"art.v0","name":"Pirate","attributes":[{"trait_type":"Hat","value":"Tricorn","description":"A hat"}]}`),
+			Expected: []byte(`{"name":"Pirate","properties":{"Hat":"Tricorn"}}`),
+			ExpectedNotes: []string{
+				"xls24d nftType: dropped: no MetaData equivalent",
+				"xls24d attributes[0].description: lossy: no MetaData equivalent",
+				`erc721 attributes: lossy: became erc1155 "properties" (a map of trait-type to value), which does not keep their order`,
+			},
+		},
+		{
+			// The "properties" stay the "properties".
+			From: nftmeta.FormatERC1155,
+			To:   nftmeta.FormatERC1155,
+			Data: []byte(`{"name":"Sword","decimals":0,"image":"https://example.com/sword.png","properties":{"damage":7,"rarity":"rare"}}`),
+			Expected: []byte(`{"image":"https://example.com/sword.png","name":"Sword","properties":{"damage":7,"rarity":"rare"}}`),
+			ExpectedNotes: []string{
+				"erc1155 decimals: dropped: no MetaData equivalent",
+				`erc721 attributes: lossy: became erc1155 "properties" (a map of trait-type to value), which does not keep their order`,
+			},
+		},
+		{
+			From: nftmeta.FormatERC1155,
+			To:   nftmeta.FormatARC3,
+			Data: []byte(`{"name":"Sword","decimals":0,"image":"https://example.com/sword.png","properties":{"damage":7,"rich":{"a":1}}}`),
+			Expected: []byte(`{"image":"https://example.com/sword.png","name":"Sword","properties":{"damage":7}}`),
+			ExpectedNotes: []string{
+				"erc1155 properties.rich: dropped: not a string or number, so cannot be an attribute",
+				"erc1155 decimals: dropped: no MetaData equivalent",
+				`erc721 attributes: lossy: became arc3 "properties" (a map of trait-type to value), which does not keep their order`,
+			},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, report, err := nftmeta.Convert(test.From, test.To, test.Data)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual converted JSON is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+
+		var notes []string
+		for _, note := range report.Notes() {
+			notes = append(notes, note.String())
+		}
+
+		if expected, actual := test.ExpectedNotes, notes; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual conversion notes are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %#v", expected)
+			t.Logf("ACTUAL:   %#v", actual)
+			continue
+		}
+
+		if expected, actual := len(test.ExpectedNotes) <= 0, report.IsLossless(); expected != actual {
+			t.Errorf("For test #%d, the actual is-lossless is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			continue
+		}
+	}
+}
+
+func TestConvert_unknownFormat(t *testing.T) {
+
+	if _, _, err := nftmeta.Convert(nftmeta.FormatERC721, nftmeta.Format("no-such-format"), []byte(`{}`)); nil == err {
+		t.Errorf("Expected an error for an unknown format, but did not actually get one.")
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+
+	const format nftmeta.Format = "test-name-only"
+
+	nftmeta.RegisterFormat(format,
+		func(data []byte, report *nftmeta.ConversionReport) (nftmeta.MetaData, error) {
+			var metadata nftmeta.MetaData
+			metadata.SetName(string(data))
+			return metadata, nil
+		},
+		func(metadata nftmeta.MetaData, report *nftmeta.ConversionReport) ([]byte, error) {
+			if metadata.Image().IsSomething() {
+				report.AddDropped(nftmeta.FormatERC721, "image", "no "+string(format)+" equivalent")
+			}
+			return []byte(metadata.Name().GetElse("")), nil
+		},
+	)
+
+	actual, report, err := nftmeta.Convert(nftmeta.FormatERC721, format, []byte(`{"name":"Hello","image":"ipfs://x"}`))
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected := []byte(`Hello`); !bytes.Equal(expected, actual) {
+		t.Errorf("The actual converted data is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+
+	if expected, actual := 1, len(report.Dropped()); expected != actual {
+		t.Errorf("The actual number of dropped notes is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+}
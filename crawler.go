@@ -0,0 +1,350 @@
+package nftmeta
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// These are what a Crawler uses when its field is zero.
+const (
+	defaultCrawlerWorkers        = 8
+	defaultCrawlerMaxRetries     = 5
+	defaultCrawlerInitialBackoff = 1 * time.Second
+	defaultCrawlerMaxBackoff     = 1 * time.Minute
+)
+
+// Crawler resolves (see Resolver) the metadata of many tokens of a collection, concurrently.
+//
+// It retries — with exponential backoff — a token whose request got a 429 Too Many Requests or a 5xx response.
+// It can limit how often it makes requests to each host.
+// And it can record its progress in a checkpoint file, so that an interrupted crawl can be resumed.
+type Crawler struct {
+	// Resolver resolves the tokenURI of each token.
+	Resolver Resolver
+
+	// TokenURI returns the tokenURI of a token. See TokenURIFunc.
+	TokenURI func(tokenID *big.Int) (string, error)
+
+	// Workers is how many tokens are resolved at the same time.
+	// If it is 0, then 8 is used.
+	Workers int
+
+	// HostInterval is the least time between (the starts of) requests to the same host. If it is 0, requests are not rate-limited.
+	HostInterval time.Duration
+
+	// MaxRetries is the most times a token is retried. If it is 0, then 5 is used. If it is negative, tokens are not retried.
+	MaxRetries int
+
+	// InitialBackoff is how long to wait before the first retry; each retry after that waits twice as long as the one before, up to MaxBackoff.
+	// (If the response had a longer Retry-After, that is waited instead.)
+	// If they are 0, then 1 second and 1 minute are used.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// CheckpointFile is the path of the file in which the token-ids that have been resolved (and whose results have been received) are recorded.
+	// A token-id that is in it already is skipped — which is how an interrupted crawl is resumed.
+	// If it is "", there is no checkpoint file.
+	CheckpointFile string
+}
+
+// CrawlResult is the result of crawling a single token — either its MetaData, or an error.
+type CrawlResult struct {
+	TokenID  *big.Int
+	MetaData MetaData
+	Err      error
+}
+
+// TokenURIFunc returns a function (for Crawler.TokenURI) that gives the tokenURI of a token as 'baseURI' followed by the token's file name (see TokenFileNaming).
+//
+// If 'baseURI' has the ERC-1155 "{id}" in it, then "{id}" is replaced with the token's file name instead.
+func TokenURIFunc(baseURI string, naming TokenFileNaming) func(*big.Int) (string, error) {
+	return func(tokenID *big.Int) (string, error) {
+		name, err := naming.FileName(tokenID)
+		if nil != err {
+			return "", err
+		}
+
+		if strings.Contains(baseURI, "{id}") {
+			return strings.ReplaceAll(baseURI, "{id}", name), nil
+		}
+		return baseURI + name, nil
+	}
+}
+
+// Crawl resolves the metadata of each of the tokens 'tokenIDs', and sends the result of each (in whatever order they finish) to the returned channel — which is closed once they are all done (or 'ctx' is done).
+//
+// Tokens that the checkpoint file says have already been resolved are skipped, and have no result.
+// A token is only recorded in the checkpoint file once its result has been received from the channel — so a token whose result was never received (because 'ctx' was done first) is crawled again when the crawl is resumed.
+// If there is a problem recording it, a second result for that token, with only that error, is sent.
+func (receiver Crawler) Crawl(ctx context.Context, tokenIDs []*big.Int) (<-chan CrawlResult, error) {
+	if nil == receiver.TokenURI {
+		return nil, errCrawlerNoTokenURI
+	}
+
+	var checkpoint *crawlerCheckpoint
+	if "" != receiver.CheckpointFile {
+		var err error
+		checkpoint, err = openCrawlerCheckpoint(receiver.CheckpointFile)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	resolver := receiver.Resolver
+	if 0 < receiver.HostInterval {
+		client := http.Client{}
+		if nil != resolver.HTTPClient {
+			client = *resolver.HTTPClient
+		}
+
+		transport := client.Transport
+		if nil == transport {
+			transport = http.DefaultTransport
+		}
+		client.Transport = &hostRateLimiter{transport: transport, interval: receiver.HostInterval, next: map[string]time.Time{}}
+
+		resolver.HTTPClient = &client
+	}
+
+	workers := receiver.Workers
+	if workers <= 0 {
+		workers = defaultCrawlerWorkers
+	}
+
+	var jobs = make(chan *big.Int)
+	var results = make(chan CrawlResult)
+
+	go func() {
+		defer close(jobs)
+
+		for _, tokenID := range tokenIDs {
+			if nil != checkpoint && checkpoint.has(tokenID) {
+				continue
+			}
+
+			select {
+			case jobs <- tokenID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for tokenID := range jobs {
+				result := CrawlResult{TokenID: tokenID}
+				result.MetaData, result.Err = receiver.crawlToken(ctx, resolver, tokenID)
+
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+
+				// It is only checkpointed once its result has been received — otherwise, if 'ctx' is done first, it would be skipped when the crawl is resumed, without its result ever having been received.
+				if nil == result.Err && nil != checkpoint {
+					if err := checkpoint.add(tokenID); nil != err {
+						select {
+						case results <- CrawlResult{TokenID: tokenID, Err: err}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		waitGroup.Wait()
+		if nil != checkpoint {
+			checkpoint.close()
+		}
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// crawlToken resolves the metadata of the token 'tokenID', retrying (with backoff) if it gets a 429 or a 5xx response.
+func (receiver Crawler) crawlToken(ctx context.Context, resolver Resolver, tokenID *big.Int) (MetaData, error) {
+	tokenURI, err := receiver.TokenURI(tokenID)
+	if nil != err {
+		return MetaData{}, err
+	}
+
+	maxRetries := receiver.MaxRetries
+	switch {
+	case 0 == maxRetries:
+		maxRetries = defaultCrawlerMaxRetries
+	case maxRetries < 0:
+		maxRetries = 0
+	}
+
+	backoff := receiver.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultCrawlerInitialBackoff
+	}
+	maxBackoff := receiver.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultCrawlerMaxBackoff
+	}
+
+	for retry := 0; ; retry++ {
+		metadata, err := resolver.Resolve(ctx, tokenURI)
+		if nil == err {
+			return metadata, nil
+		}
+
+		var statusError HTTPStatusError
+		if !errors.As(err, &statusError) || !isRetryableStatus(statusError.StatusCode) || maxRetries <= retry {
+			return MetaData{}, err
+		}
+
+		wait := backoff
+		if wait < statusError.RetryAfter {
+			wait = statusError.RetryAfter
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return MetaData{}, ctx.Err()
+		}
+
+		backoff *= 2
+		if maxBackoff < backoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// isRetryableStatus returns true for 429 Too Many Requests, and the 5xx status codes.
+func isRetryableStatus(statusCode int) bool {
+	return http.StatusTooManyRequests == statusCode || (500 <= statusCode && statusCode <= 599)
+}
+
+// hostRateLimiter is an http.RoundTripper that spaces out the requests to each host by (at least) 'interval'.
+type hostRateLimiter struct {
+	transport http.RoundTripper
+	interval  time.Duration
+
+	mutex sync.Mutex
+	next  map[string]time.Time
+}
+
+func (receiver *hostRateLimiter) RoundTrip(request *http.Request) (*http.Response, error) {
+	var wait time.Duration
+	{
+		receiver.mutex.Lock()
+
+		now := time.Now()
+		next := receiver.next[request.URL.Host]
+		if next.Before(now) {
+			next = now
+		}
+		wait = next.Sub(now)
+		receiver.next[request.URL.Host] = next.Add(receiver.interval)
+
+		receiver.mutex.Unlock()
+	}
+
+	if 0 < wait {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		}
+	}
+
+	return receiver.transport.RoundTrip(request)
+}
+
+// crawlerCheckpoint is a checkpoint file — which has the token-id (in decimal) of each resolved token, one per line.
+// It is only ever appended to, so an interruption can (at worst) leave a partial last line, which is ignored.
+type crawlerCheckpoint struct {
+	mutex sync.Mutex
+	file  *os.File
+	done  map[string]struct{}
+}
+
+func openCrawlerCheckpoint(path string) (*crawlerCheckpoint, error) {
+	var checkpoint = crawlerCheckpoint{done: map[string]struct{}{}}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem opening checkpoint file %q: %w", path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if nil != err {
+			// Whatever is left has no '\n' at its end — so it is a partial line (or nothing).
+			break
+		}
+
+		tokenID, ok := big.NewInt(0).SetString(strings.TrimSuffix(line, "\n"), 10)
+		if !ok {
+			continue
+		}
+		checkpoint.done[tokenID.String()] = struct{}{}
+	}
+
+	checkpoint.file = file
+	return &checkpoint, nil
+}
+
+func (receiver *crawlerCheckpoint) has(tokenID *big.Int) bool {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	_, found := receiver.done[tokenID.String()]
+	return found
+}
+
+func (receiver *crawlerCheckpoint) add(tokenID *big.Int) error {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	// A partial last line (from an interruption) would otherwise be joined onto this line.
+	if info, err := receiver.file.Stat(); nil == err && 0 < info.Size() {
+		var last [1]byte
+		if _, err := receiver.file.ReadAt(last[:], info.Size()-1); nil == err && '\n' != last[0] {
+			if _, err := receiver.file.WriteString("\n"); nil != err {
+				return erorr.Errorf("nftmeta: problem writing checkpoint file: %w", err)
+			}
+		}
+	}
+
+	if _, err := receiver.file.WriteString(tokenID.String() + "\n"); nil != err {
+		return erorr.Errorf("nftmeta: problem writing checkpoint file: %w", err)
+	}
+	receiver.done[tokenID.String()] = struct{}{}
+	return nil
+}
+
+func (receiver *crawlerCheckpoint) close() {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	receiver.file.Close()
+}
@@ -0,0 +1,349 @@
+package nftmeta_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestTokenURIFunc(t *testing.T) {
+
+	tests := []struct{
+		BaseURI  string
+		Naming   nftmeta.TokenFileNaming
+		TokenID  int64
+		Expected string
+	}{
+		{
+			BaseURI:  "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/",
+			Naming:   nftmeta.TokenFileNamingDecimal,
+			TokenID:  3,
+			Expected: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+		},
+		{
+			BaseURI:  "https://example.com/token/",
+			Naming:   nftmeta.TokenFileNamingDecimalJSON,
+			TokenID:  42,
+			Expected: "https://example.com/token/42.json",
+		},
+		{
+			BaseURI:  "https://example.com/api/{id}.json",
+			Naming:   nftmeta.TokenFileNamingHex,
+			TokenID:  314592,
+			Expected: "https://example.com/api/000000000000000000000000000000000000000000000000000000000004cce0.json",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := nftmeta.TokenURIFunc(test.BaseURI, test.Naming)(big.NewInt(test.TokenID))
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, the actual tokenURI is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+
+	var mutex sync.Mutex
+	var requests = map[string]int{}
+
+	// Token 2 is rate-limited (twice) before it works, token 3 gets a (retryable) 503 once, and token 4 does not exist.
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		tokenID := strings.TrimPrefix(request.URL.Path, "/token/")
+
+		mutex.Lock()
+		requests[tokenID]++
+		count := requests[tokenID]
+		mutex.Unlock()
+
+		switch {
+		case "2" == tokenID && count <= 2:
+			responseWriter.Header().Set("Retry-After", "0")
+			http.Error(responseWriter, "slow down", http.StatusTooManyRequests)
+			return
+		case "3" == tokenID && count <= 1:
+			http.Error(responseWriter, "try again", http.StatusServiceUnavailable)
+			return
+		case "4" == tokenID:
+			http.NotFound(responseWriter, request)
+			return
+		}
+
+		responseWriter.Write([]byte(`{"name":"Token #` + tokenID + `"}`))
+	}))
+	defer server.Close()
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint")
+
+	crawler := nftmeta.Crawler{
+		TokenURI:       nftmeta.TokenURIFunc(server.URL+"/token/", nftmeta.TokenFileNamingDecimal),
+		Workers:        3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+		CheckpointFile: checkpointFile,
+	}
+
+	var tokenIDs []*big.Int
+	for i := int64(0); i < 6; i++ {
+		tokenIDs = append(tokenIDs, big.NewInt(i))
+	}
+
+	results, err := crawler.Crawl(context.Background(), tokenIDs)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	var names = map[string]string{}
+	var failed []string
+	for result := range results {
+		if nil != result.Err {
+			failed = append(failed, result.TokenID.String())
+
+			var statusError nftmeta.HTTPStatusError
+			if !errors.As(result.Err, &statusError) || http.StatusNotFound != statusError.StatusCode {
+				t.Errorf("For token-id %s, expected a 404 error but actually got something else.", result.TokenID)
+				t.Logf("ERROR: (%T) %s", result.Err, result.Err)
+			}
+			continue
+		}
+		names[result.TokenID.String()] = result.MetaData.Name().GetElse("")
+	}
+
+	if expected, actual := 5, len(names); expected != actual {
+		t.Errorf("The actual number of resolved tokens is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	for tokenID, name := range names {
+		if expected, actual := "Token #"+tokenID, name; expected != actual {
+			t.Errorf("For token-id %s, the actual name is not what was expected.", tokenID)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+	if expected, actual := "4", strings.Join(failed, ","); expected != actual {
+		t.Errorf("The actual failed tokens are not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	mutex.Lock()
+	if expected, actual := 3, requests["2"]; expected != actual {
+		t.Errorf("The actual number of requests for token-id 2 is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	if expected, actual := 2, requests["3"]; expected != actual {
+		t.Errorf("The actual number of requests for token-id 3 is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	if expected, actual := 1, requests["4"]; expected != actual {
+		t.Errorf("The actual number of requests for token-id 4 is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	requests = map[string]int{}
+	mutex.Unlock()
+
+	// Resuming only crawls what did not get resolved before.
+	results, err = crawler.Crawl(context.Background(), tokenIDs)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	var resumed []string
+	for result := range results {
+		resumed = append(resumed, result.TokenID.String())
+	}
+
+	if expected, actual := "4", strings.Join(resumed, ","); expected != actual {
+		t.Errorf("The actual resumed tokens are not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+	mutex.Lock()
+	if expected, actual := 1, len(requests); expected != actual {
+		t.Errorf("The actual number of tokens requested on resume is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	mutex.Unlock()
+}
+
+func TestCrawler_Crawl_hostInterval(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Write([]byte(`{"name":"x"}`))
+	}))
+	defer server.Close()
+
+	crawler := nftmeta.Crawler{
+		TokenURI:     nftmeta.TokenURIFunc(server.URL+"/", nftmeta.TokenFileNamingDecimal),
+		Workers:      5,
+		HostInterval: 20 * time.Millisecond,
+	}
+
+	var tokenIDs []*big.Int
+	for i := int64(0); i < 5; i++ {
+		tokenIDs = append(tokenIDs, big.NewInt(i))
+	}
+
+	start := time.Now()
+
+	results, err := crawler.Crawl(context.Background(), tokenIDs)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	for result := range results {
+		if nil != result.Err {
+			t.Errorf("For token-id %s, did not expect an error but actually got one.", result.TokenID)
+			t.Logf("ERROR: (%T) %s", result.Err, result.Err)
+		}
+	}
+
+	// Even with 5 workers, 5 requests to the same host take (at least) 4 intervals.
+	if minimum, actual := 80*time.Millisecond, time.Since(start); actual < minimum {
+		t.Errorf("The crawl was faster than its rate-limit allows.")
+		t.Logf("MINIMUM: %s", minimum)
+		t.Logf("ACTUAL:  %s", actual)
+	}
+}
+
+func TestCrawler_Crawl_noTokenURI(t *testing.T) {
+
+	_, err := nftmeta.Crawler{}.Crawl(context.Background(), []*big.Int{big.NewInt(1)})
+	if nil == err {
+		t.Errorf("Expected an error but did not actually get one.")
+	}
+}
+
+func TestCrawler_Crawl_partialCheckpoint(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Write([]byte(`{"name":"x"}`))
+	}))
+	defer server.Close()
+
+	// An interrupted crawl can leave a partial last line — which must not count as done.
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint")
+	if err := os.WriteFile(checkpointFile, []byte("0\n1\n2"), 0644); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	crawler := nftmeta.Crawler{
+		TokenURI:       nftmeta.TokenURIFunc(server.URL+"/", nftmeta.TokenFileNamingDecimal),
+		CheckpointFile: checkpointFile,
+	}
+
+	for round, expected := range []string{"23", ""} {
+		results, err := crawler.Crawl(context.Background(), []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(23)})
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		var crawled []string
+		for result := range results {
+			crawled = append(crawled, result.TokenID.String())
+		}
+
+		if actual := strings.Join(crawled, ","); expected != actual {
+			t.Errorf("For round #%d, the actual crawled tokens are not what was expected.", round)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+}
+
+func TestCrawler_Crawl_cancelAndResume(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Write([]byte(`{"name":"x"}`))
+	}))
+	defer server.Close()
+
+	var tokenIDs []*big.Int
+	for i := int64(0); i < 50; i++ {
+		tokenIDs = append(tokenIDs, big.NewInt(i))
+	}
+
+	for testNumber, cancelAfter := range []int{1, 5, 10, 25, 40} {
+
+		crawler := nftmeta.Crawler{
+			TokenURI:       nftmeta.TokenURIFunc(server.URL+"/", nftmeta.TokenFileNamingDecimal),
+			CheckpointFile: filepath.Join(t.TempDir(), "checkpoint"),
+		}
+
+		var received = map[string]int{}
+
+		// The crawl is interrupted — but what it sends (even after it is interrupted) keeps being received.
+		{
+			ctx, cancel := context.WithCancel(context.Background())
+
+			results, err := crawler.Crawl(ctx, tokenIDs)
+			if nil != err {
+				cancel()
+				t.Fatalf("For test #%d, did not expect an error but actually got one: (%T) %s", testNumber, err, err)
+			}
+
+			var count int
+			for result := range results {
+				if nil != result.Err {
+					continue
+				}
+				received[result.TokenID.String()]++
+
+				count++
+				if cancelAfter == count {
+					cancel()
+				}
+			}
+			cancel()
+		}
+
+		// The crawl is resumed.
+		{
+			results, err := crawler.Crawl(context.Background(), tokenIDs)
+			if nil != err {
+				t.Fatalf("For test #%d, did not expect an error but actually got one: (%T) %s", testNumber, err, err)
+			}
+
+			for result := range results {
+				if nil != result.Err {
+					t.Errorf("For test #%d, did not expect an error (for token-id %s) but actually got one.", testNumber, result.TokenID)
+					t.Logf("ERROR: (%T) %s", result.Err, result.Err)
+					continue
+				}
+				received[result.TokenID.String()]++
+			}
+		}
+
+		for _, tokenID := range tokenIDs {
+			if expected, actual := 1, received[tokenID.String()]; expected != actual {
+				t.Errorf("For test #%d, the actual number of results received for token-id %s is not what was expected.", testNumber, tokenID)
+				t.Logf("EXPECTED: %d", expected)
+				t.Logf("ACTUAL:   %d", actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// Detection is what Detect found out about what format some NFT metadata JSON is in.
+//
+// Confidence is between 0 and 1. It combines the weights of the Evidence (each of which is a reason to think the JSON is in Format).
+type Detection struct {
+	Format     Format
+	Confidence float64
+	Evidence   []string
+}
+
+// detectionFormats are the formats that Detect considers — in the order that ties are broken in.
+var detectionFormats = []Format{
+	FormatERC721,
+	FormatERC1155,
+	FormatMetaplex,
+	FormatTZIP21,
+	FormatARC3,
+	FormatARC69,
+	FormatXLS24d,
+}
+
+// detectionClue is a reason (with a weight between 0 and 1) to think that some JSON is in a format.
+type detectionClue struct {
+	format   Format
+	weight   float64
+	evidence string
+}
+
+// Detect returns the most likely format of the NFT metadata JSON 'data' — one of:
+// FormatERC721, FormatERC1155, FormatMetaplex, FormatTZIP21, FormatARC3, FormatARC69, or FormatXLS24d.
+//
+// If nothing points to a particular format, then FormatERC721 is returned (with a low confidence).
+//
+// Detect returns an error if 'data' is not a JSON object.
+func Detect(data []byte) (Detection, error) {
+	detections, err := DetectAll(data)
+	if nil != err {
+		return Detection{}, err
+	}
+
+	return detections[0], nil
+}
+
+// DetectAll is like Detect, but returns a Detection for each format it considers — most likely first.
+func DetectAll(data []byte) ([]Detection, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimSpace(data), &object); nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-unmarshaling metadata to detect its format: %w", err)
+	}
+
+	var detections []Detection
+	{
+		var byFormat = map[Format]*Detection{}
+		for _, format := range detectionFormats {
+			detections = append(detections, Detection{Format: format})
+		}
+		for index := range detections {
+			byFormat[detections[index].Format] = &detections[index]
+		}
+
+		for _, clue := range detectionClues(object) {
+			detection := byFormat[clue.format]
+
+			// The weights combine like independent probabilities: 1 - (1-w1)(1-w2)...
+			detection.Confidence = 1 - (1-detection.Confidence)*(1-clue.weight)
+			detection.Evidence = append(detection.Evidence, clue.evidence)
+		}
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+
+	return detections, nil
+}
+
+// DetectAndDecode detects the format of the NFT metadata JSON 'data' (see Detect), and then decodes it into MetaData using the decoder of that format (see Decode).
+func DetectAndDecode(data []byte) (MetaData, Detection, ConversionReport, error) {
+	detection, err := Detect(data)
+	if nil != err {
+		return MetaData{}, Detection{}, ConversionReport{}, err
+	}
+
+	metadata, report, err := Decode(detection.Format, data)
+	if nil != err {
+		return MetaData{}, detection, report, err
+	}
+
+	return metadata, detection, report, nil
+}
+
+func detectionClues(object map[string]json.RawMessage) []detectionClue {
+	var clues []detectionClue
+
+	has := func(key string) bool {
+		_, found := object[key]
+		return found
+	}
+	clue := func(format Format, weight float64, evidence string) {
+		clues = append(clues, detectionClue{format: format, weight: weight, evidence: evidence})
+	}
+	present := func(key string) string {
+		return strconv.Quote(key) + " is present"
+	}
+
+	// ARC-69
+	{
+		var standard string
+		if raw, found := object["standard"]; found && nil == json.Unmarshal(raw, &standard) && "arc69" == standard {
+			clue(FormatARC69, 0.99, `"standard" is "arc69"`)
+		}
+		if has("media_url") {
+			clue(FormatARC69, 0.5, present("media_url"))
+		}
+	}
+
+	// ARC-3
+	for _, key := range []string{
+		"animation_url_integrity", "animation_url_mimetype",
+		"external_url_integrity", "external_url_mimetype",
+		"image_integrity", "image_mimetype",
+		"extra_metadata",
+	}{
+		if has(key) {
+			clue(FormatARC3, 0.8, present(key))
+		}
+	}
+
+	// Metaplex
+	{
+		if has("seller_fee_basis_points") {
+			clue(FormatMetaplex, 0.8, present("seller_fee_basis_points"))
+		}
+		if has("symbol") && !has("artifactUri") && !has("displayUri") {
+			clue(FormatMetaplex, 0.4, present("symbol"))
+		}
+
+		var properties map[string]json.RawMessage
+		if raw, found := object["properties"]; found && nil == json.Unmarshal(raw, &properties) {
+			for _, key := range []string{"files", "creators", "category"} {
+				if _, found := properties[key]; found {
+					clue(FormatMetaplex, 0.6, present("properties."+key))
+				}
+			}
+		}
+	}
+
+	// TZIP-21
+	for _, item := range []struct{
+		key    string
+		weight float64
+	}{
+		{"artifactUri",     0.8},
+		{"displayUri",      0.8},
+		{"thumbnailUri",    0.7},
+		{"isBooleanAmount", 0.7},
+		{"formats",         0.6},
+		{"royalties",       0.4},
+		{"tags",            0.3},
+		{"creators",        0.3},
+	}{
+		if has(item.key) {
+			clue(FormatTZIP21, item.weight, present(item.key))
+		}
+	}
+
+	// XLS-24d
+	for _, item := range []struct{
+		key    string
+		weight float64
+	}{
+		{"nftType",   0.9},
+		{"schema",    0.6},
+		{"animation", 0.5},
+		{"video",     0.4},
+		{"audio",     0.4},
+		{"file",      0.4},
+		{"collection", 0.2},
+	}{
+		if has(item.key) {
+			clue(FormatXLS24d, item.weight, present(item.key))
+		}
+	}
+
+	// ERC-1155
+	{
+		if has("localization") {
+			clue(FormatERC1155, 0.7, present("localization"))
+		}
+		if has("decimals") && !has("artifactUri") && !has("displayUri") {
+			clue(FormatERC1155, 0.4, present("decimals"))
+		}
+		if has("properties") && !has("seller_fee_basis_points") && !has("standard") {
+			clue(FormatERC1155, 0.3, present("properties"))
+		}
+
+		var keys []string
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var str string
+			if nil == json.Unmarshal(object[key], &str) && strings.Contains(str, "{id}") {
+				clue(FormatERC1155, 0.6, strconv.Quote(key)+` has the "{id}" substitution`)
+				break
+			}
+		}
+	}
+
+	// ERC-721
+	{
+		for _, item := range []struct{
+			key    string
+			weight float64
+		}{
+			{"external_link",    0.4},
+			{"youtube_url",      0.5},
+			{"image_data",       0.5},
+			{"background_color", 0.3},
+			{"animation_url",    0.2},
+		}{
+			if has(item.key) {
+				clue(FormatERC721, item.weight, present(item.key))
+			}
+		}
+
+		if has("name") || has("image") || has("description") {
+			clue(FormatERC721, 0.2, `has a "name", "image", or "description"`)
+		}
+	}
+
+	// The shape of the "attributes".
+	{
+		var attributes []map[string]json.RawMessage
+		if raw, found := object["attributes"]; found && nil == json.Unmarshal(raw, &attributes) && 0 < len(attributes) {
+			_, traitType := attributes[0]["trait_type"]
+			_, name := attributes[0]["name"]
+			_, description := attributes[0]["description"]
+
+			switch {
+			case traitType && description:
+				clue(FormatXLS24d, 0.5, `"attributes" have a "trait_type" and a "description"`)
+			case traitType:
+				clue(FormatERC721, 0.4, `"attributes" have a "trait_type"`)
+			case name:
+				clue(FormatTZIP21, 0.4, `"attributes" have a "name" (rather than a "trait_type")`)
+			}
+		}
+	}
+
+	return clues
+}
@@ -0,0 +1,114 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestDetect(t *testing.T) {
+
+	tests := []struct{
+		Data []byte
+		Expected nftmeta.Format
+	}{
+		{
+			Data: []byte(`{"name":"peanut-butter-jelly-time","external_link":"http://example.com/token/123","youtube_url":"https://youtu.be/eRBOgtp0Hac","attributes":[{"trait_type":"Bread 1","value":"Peanut Butter"}]}`),
+			Expected: nftmeta.FormatERC721,
+		},
+		{
+			Data: []byte(`{}`),
+			Expected: nftmeta.FormatERC721,
+		},
+		{
+			Data: []byte(`{"name":"Sword","decimals":0,"image":"https://example.com/{id}.png","properties":{"damage":7}}`),
+			Expected: nftmeta.FormatERC1155,
+		},
+		{
+			Data: []byte(`{"name":"Solflare X NFT","symbol":"","seller_fee_basis_points":500,"image":"https://www.arweave.net/abcd5678?ext=png","attributes":[{"trait_type":"web","value":"yes"}],"properties":{"files":[{"uri":"https://www.arweave.net/abcd5678?ext=png","type":"image/png"}],"category":"image"}}`),
+			Expected: nftmeta.FormatMetaplex,
+		},
+		{
+			Data: []byte(`{"name":"Tez","artifactUri":"ipfs://anim","displayUri":"ipfs://img","decimals":0,"isBooleanAmount":true,"attributes":[{"name":"Edition","value":"3"}]}`),
+			Expected: nftmeta.FormatTZIP21,
+		},
+		{
+			Data: []byte(`{"name":"Algo","image":"ipfs://img","image_integrity":"sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=","image_mimetype":"image/png","properties":{"Edition":3}}`),
+			Expected: nftmeta.FormatARC3,
+		},
+		{
+			Data: []byte(`{"standard":"arc69","description":"Lake Tahoe","media_url":"ipfs://x","properties":{"Edition":3}}`),
+			Expected: nftmeta.FormatARC69,
+		},
+		{
+			Data: []byte(`{"schema":"ipfs://QmNpi8rcXEkohca8iXu7zysKKSJYqCvBJn3xJwga8jXqWU","nftType":"art.v0","name":"Pirate","collection":{"name":"Pirates"},"attributes":[{"trait_type":"Hat","value":"Tricorn","description":"A hat"}]}`),
+			Expected: nftmeta.FormatXLS24d,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		detection, err := nftmeta.Detect(test.Data)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.Expected, detection.Format; expected != actual {
+			t.Errorf("For test #%d, the actual detected format is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			t.Logf("DETECTION: %#v", detection)
+			continue
+		}
+
+		if detection.Confidence < 0 || 1 < detection.Confidence {
+			t.Errorf("For test #%d, the actual confidence (%v) is not between 0 and 1.", testNumber, detection.Confidence)
+			continue
+		}
+
+		if `{}` != string(test.Data) && len(detection.Evidence) <= 0 {
+			t.Errorf("For test #%d, expected there to be evidence, but there was none.", testNumber)
+			continue
+		}
+	}
+}
+
+func TestDetectAndDecode(t *testing.T) {
+
+	var data = []byte(`{"name":"Solflare X NFT","symbol":"SFX","seller_fee_basis_points":500,"external_url":"https://solflare.com"}`)
+
+	metadata, detection, report, err := nftmeta.DetectAndDecode(data)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := nftmeta.FormatMetaplex, detection.Format; expected != actual {
+		t.Errorf("The actual detected format is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	if expected, actual := "https://solflare.com", metadata.ExternalLink().GetElse(""); expected != actual {
+		t.Errorf("The actual external-link is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	if expected, actual := 2, len(report.Dropped()); expected != actual {
+		t.Errorf("The actual number of dropped fields is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+}
+
+func TestDetect_notObject(t *testing.T) {
+
+	for testNumber, data := range []string{``, `[]`, `"x"`, `{`} {
+		if _, err := nftmeta.Detect([]byte(data)); nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("DATA: %q", data)
+		}
+	}
+}
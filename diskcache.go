@@ -0,0 +1,365 @@
+package nftmeta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDiskCacheMaxSize is what a DiskCache uses when its MaxSize is zero.
+const defaultDiskCacheMaxSize = 256 << 20
+
+// DiskCache is an on-disk cache of what a Resolver fetches. (See Resolver.Cache.)
+//
+// IPFS content is immutable — so it is stored by its CID, and is not revalidated.
+// But it is only stored if it is checked to be the content of its CID (see IPFSCIDv0 and IPFSCIDv1) — and it is checked again each time it is loaded — so a gateway that returns something else (such as an HTML error page) cannot poison the cache.
+// Note that this means that only the content of a whole file is stored, and not the content of a path under a directory CID (since it cannot be checked without the directory), nor Arweave content (since it cannot be checked without the transaction).
+//
+// Other (i.e., HTTP and HTTPS) content is stored by its URL, along with its ETag and Last-Modified (and is only stored if the response had at least one of them).
+// It is revalidated with a conditional request each time it is fetched — and if the response is 304 Not Modified, what is stored is used.
+//
+// Once what is stored (of both) is bigger than MaxSize, the least-recently used of it is evicted — until what is left is (at most) 90% of MaxSize, so that it is not scanned again on the very next store.
+//
+// Each file of the cache is written to a temporary file first, and then renamed — so (other goroutines and other processes) reading the cache never see a partially-written file.
+//
+// The cache is best-effort. A problem reading or writing it is not an error — it only means the content is fetched (again).
+type DiskCache struct {
+	// Dir is the directory the cache is in. If it is "", then the "nftmeta" directory in os.UserCacheDir is used.
+	Dir string
+
+	// MaxSize is the most content that is stored, in bytes.
+	// If it is 0, then 256 MiB is used.
+	MaxSize int64
+
+	mutex sync.Mutex
+	sizes map[string]int64 // The size of each file of the cache, by its path (relative to the directory) — which is loaded from the directory when it is first needed.
+	size  int64            // The sum of sizes.
+}
+
+// diskCacheHeader is the first line of the file of (HTTP or HTTPS) content in the cache — which is followed by the content itself.
+type diskCacheHeader struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+}
+
+func (receiver *DiskCache) dir() string {
+	if "" != receiver.Dir {
+		return receiver.Dir
+	}
+
+	dir, err := os.UserCacheDir()
+	if nil != err {
+		return ""
+	}
+	return filepath.Join(dir, "nftmeta")
+}
+
+func (receiver *DiskCache) maxSize() int64 {
+	if receiver.MaxSize <= 0 {
+		return defaultDiskCacheMaxSize
+	}
+	return receiver.MaxSize
+}
+
+// diskCacheSubdirs are the subdirectories of the cache — for IPFS content, and for HTTP(S) content.
+var diskCacheSubdirs = []string{"ipfs", "http"}
+
+// immutablePath returns the path of the file of the (immutable) IPFS content 'uri' — or "" if 'uri' is not the IPFS content of a whole file (i.e., if it is not IPFS, or if it has a path).
+func (receiver *DiskCache) immutablePath(uri URI) string {
+	if URIKindIPFS != uri.Kind() {
+		return ""
+	}
+	if path := uri.Path(); "" != path && "/" != path {
+		return ""
+	}
+
+	dir := receiver.dir()
+	if "" == dir || "" == uri.CID() {
+		return ""
+	}
+
+	return filepath.Join(dir, "ipfs", uri.CID())
+}
+
+// httpPath returns the path of the file of the HTTP(S) content 'url'.
+func (receiver *DiskCache) httpPath(url string) string {
+	dir := receiver.dir()
+	if "" == dir {
+		return ""
+	}
+
+	digest := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, "http", hex.EncodeToString(digest[:]))
+}
+
+// loadImmutable returns the stored IPFS content 'uri', if it is stored (and is still the content of its CID).
+func (receiver *DiskCache) loadImmutable(uri URI) ([]byte, bool) {
+	if nil == receiver {
+		return nil, false
+	}
+
+	path := receiver.immutablePath(uri)
+	if "" == path {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if nil != err {
+		return nil, false
+	}
+	if !ipfsContentHasCID(uri.CID(), data) {
+		return nil, false
+	}
+
+	touch(path)
+	return data, true
+}
+
+// storeImmutable stores the IPFS content 'uri' — if it is the content of its CID — and then evicts the least-recently used content, if there is too much.
+func (receiver *DiskCache) storeImmutable(uri URI, data []byte) {
+	if nil == receiver {
+		return
+	}
+	if receiver.maxSize() < int64(len(data)) {
+		return
+	}
+
+	path := receiver.immutablePath(uri)
+	if "" == path {
+		return
+	}
+	if !ipfsContentHasCID(uri.CID(), data) {
+		return
+	}
+
+	if !writeFileAtomically(path, data) {
+		return
+	}
+
+	receiver.stored(path, int64(len(data)))
+}
+
+// loadHTTP returns the stored HTTP(S) content 'url' (and its ETag and Last-Modified), if it is stored.
+func (receiver *DiskCache) loadHTTP(url string) (diskCacheHeader, []byte, bool) {
+	if nil == receiver {
+		return diskCacheHeader{}, nil, false
+	}
+
+	path := receiver.httpPath(url)
+	if "" == path {
+		return diskCacheHeader{}, nil, false
+	}
+
+	file, err := os.Open(path)
+	if nil != err {
+		return diskCacheHeader{}, nil, false
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	line, err := reader.ReadBytes('\n')
+	if nil != err {
+		return diskCacheHeader{}, nil, false
+	}
+
+	var header diskCacheHeader
+	if err := json.Unmarshal(line, &header); nil != err {
+		return diskCacheHeader{}, nil, false
+	}
+	// The file name is a digest of the URL, so this would only happen if the file is not what it should be.
+	if url != header.URL {
+		return diskCacheHeader{}, nil, false
+	}
+
+	data, err := io.ReadAll(reader)
+	if nil != err {
+		return diskCacheHeader{}, nil, false
+	}
+
+	return header, data, true
+}
+
+// touchHTTP marks the stored HTTP(S) content 'url' as just used — which is what the LRU eviction goes by.
+func (receiver *DiskCache) touchHTTP(url string) {
+	if nil == receiver {
+		return
+	}
+
+	path := receiver.httpPath(url)
+	if "" == path {
+		return
+	}
+
+	touch(path)
+}
+
+// touch marks the file 'path' of the cache as just used.
+func touch(path string) {
+	now := time.Now()
+	os.Chtimes(path, now, now)
+}
+
+// storeHTTP stores the HTTP(S) content 'url' — if it has an ETag or a Last-Modified — and then evicts the least-recently used content, if there is too much.
+func (receiver *DiskCache) storeHTTP(header diskCacheHeader, data []byte) {
+	if nil == receiver {
+		return
+	}
+	if "" == header.ETag && "" == header.LastModified {
+		return
+	}
+	if receiver.maxSize() < int64(len(data)) {
+		return
+	}
+
+	path := receiver.httpPath(header.URL)
+	if "" == path {
+		return
+	}
+
+	line, err := json.Marshal(header)
+	if nil != err {
+		return
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write(line)
+	buffer.WriteByte('\n')
+	buffer.Write(data)
+
+	if !writeFileAtomically(path, buffer.Bytes()) {
+		return
+	}
+
+	receiver.stored(path, int64(buffer.Len()))
+}
+
+// stored records that the file 'path' of the cache, which is 'size' bytes, was (just) written — and then evicts the least-recently used content, if there is too much.
+func (receiver *DiskCache) stored(path string, size int64) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	if nil == receiver.sizes {
+		receiver.loadSizes()
+	} else if name, err := filepath.Rel(receiver.dir(), path); nil == err {
+		receiver.size += size - receiver.sizes[name]
+		receiver.sizes[name] = size
+	}
+
+	if receiver.maxSize() < receiver.size {
+		receiver.evict()
+	}
+}
+
+// diskCacheFile is a file of the cache, with its path relative to the directory.
+type diskCacheFile struct {
+	name string
+	info fs.FileInfo
+}
+
+// loadSizes loads the size of each file of the cache from the directory — and returns them, in no particular order.
+//
+// The caller must hold the mutex.
+func (receiver *DiskCache) loadSizes() []diskCacheFile {
+	receiver.sizes = map[string]int64{}
+	receiver.size = 0
+
+	var files []diskCacheFile
+	for _, subdir := range diskCacheSubdirs {
+		dirEntries, err := os.ReadDir(filepath.Join(receiver.dir(), subdir))
+		if nil != err {
+			continue
+		}
+
+		for _, dirEntry := range dirEntries {
+			if !dirEntry.Type().IsRegular() || strings.HasPrefix(dirEntry.Name(), temporaryFilePrefix) {
+				continue
+			}
+
+			info, err := dirEntry.Info()
+			if nil != err {
+				continue
+			}
+
+			name := filepath.Join(subdir, info.Name())
+
+			files = append(files, diskCacheFile{name: name, info: info})
+			receiver.sizes[name] = info.Size()
+			receiver.size += info.Size()
+		}
+	}
+
+	return files
+}
+
+// evict removes the least-recently used content, until what is left is (at most) 90% of MaxSize.
+//
+// It goes by what is in the directory (rather than just what this DiskCache stored) — since other processes might be using it too.
+//
+// The caller must hold the mutex.
+func (receiver *DiskCache) evict() {
+	files := receiver.loadSizes()
+
+	maxSize := receiver.maxSize() / 10 * 9
+	if receiver.size <= maxSize {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+
+	for _, file := range files {
+		if receiver.size <= maxSize {
+			break
+		}
+
+		// Another process might have already removed it — which is fine.
+		os.Remove(filepath.Join(receiver.dir(), file.name))
+		delete(receiver.sizes, file.name)
+		receiver.size -= file.info.Size()
+	}
+}
+
+const temporaryFilePrefix = ".tmp-"
+
+// writeFileAtomically writes 'data' to a temporary file, and then renames it to 'path' — so that no one ever reads a partially-written 'path'.
+// It returns whether it worked.
+func writeFileAtomically(path string, data []byte) bool {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); nil != err {
+		return false
+	}
+
+	file, err := os.CreateTemp(dir, temporaryFilePrefix+"*")
+	if nil != err {
+		return false
+	}
+	temporaryPath := file.Name()
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		os.Remove(temporaryPath)
+		return false
+	}
+
+	if err := os.Rename(temporaryPath, path); nil != err {
+		os.Remove(temporaryPath)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,378 @@
+package nftmeta_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestDiskCache_immutable(t *testing.T) {
+
+	var three = []byte(`{"name":"three"}`)
+	var four  = []byte(`{"name":"four"}`)
+
+	var threeCID = nftmeta.IPFSCIDv0(three)
+	var fourCID  = nftmeta.IPFSCIDv1(four, true)
+
+	var mutex sync.Mutex
+	var requests = map[string]int{}
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		requests[request.URL.Path]++
+		mutex.Unlock()
+
+		switch request.URL.Path {
+		case "/ipfs/" + threeCID:
+			responseWriter.Write(three)
+		case "/ipfs/" + fourCID:
+			// Not the content of the CID.
+			responseWriter.Write([]byte(`{"name":"not four"}`))
+		case "/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3":
+			responseWriter.Write([]byte(`{"name":"directory"}`))
+		case "/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U":
+			responseWriter.Write([]byte(`{"name":"arweave"}`))
+		default:
+			http.NotFound(responseWriter, request)
+		}
+	}))
+	defer gateway.Close()
+
+	cache := &nftmeta.DiskCache{Dir: t.TempDir()}
+
+	resolver := nftmeta.Resolver{
+		IPFSGateways:   []string{gateway.URL},
+		ArweaveGateway: gateway.URL,
+		Cache:          cache,
+	}
+
+	tests := []struct{
+		TokenURI         string
+		Path             string
+		Expected         string
+		ExpectedRequests int
+	}{
+		{
+			// The content of its CID, so it is cached.
+			TokenURI:         "ipfs://" + threeCID,
+			Path:             "/ipfs/" + threeCID,
+			Expected:         "three",
+			ExpectedRequests: 1,
+		},
+		{
+			// Not the content of its CID, so it is not cached.
+			TokenURI:         "ipfs://" + fourCID,
+			Path:             "/ipfs/" + fourCID,
+			Expected:         "not four",
+			ExpectedRequests: 2,
+		},
+		{
+			// A path under a directory CID cannot be checked, so it is not cached.
+			TokenURI:         "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+			Path:             "/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+			Expected:         "directory",
+			ExpectedRequests: 2,
+		},
+		{
+			// Arweave content cannot be checked, so it is not cached.
+			TokenURI:         "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U",
+			Path:             "/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U",
+			Expected:         "arweave",
+			ExpectedRequests: 2,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		for round := 0; round < 2; round++ {
+			metadata, err := resolver.Resolve(context.Background(), test.TokenURI)
+			if nil != err {
+				t.Errorf("For test #%d and round #%d, did not expect an error but actually got one.", testNumber, round)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected, actual := test.Expected, metadata.Name().GetElse(""); expected != actual {
+				t.Errorf("For test #%d and round #%d, the actual name is not what was expected.", testNumber, round)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+
+		mutex.Lock()
+		actual := requests[test.Path]
+		mutex.Unlock()
+
+		if expected := test.ExpectedRequests; expected != actual {
+			t.Errorf("For test #%d, the actual number of requests is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			continue
+		}
+	}
+
+	// What is cached is on disk — so even a resolver without any gateways gets it.
+	metadata, err := nftmeta.Resolver{Cache: &nftmeta.DiskCache{Dir: cache.Dir}}.Resolve(context.Background(), tests[0].TokenURI)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	if expected, actual := tests[0].Expected, metadata.Name().GetElse(""); expected != actual {
+		t.Errorf("The actual name is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	// What is cached is checked again when it is loaded — so, if it has been changed, it is fetched again.
+	if err := os.WriteFile(filepath.Join(cache.Dir, "ipfs", threeCID), []byte(`<html>poisoned</html>`), 0644); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	metadata, err = resolver.Resolve(context.Background(), tests[0].TokenURI)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	if expected, actual := tests[0].Expected, metadata.Name().GetElse(""); expected != actual {
+		t.Errorf("The actual name is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+	mutex.Lock()
+	if expected, actual := 2, requests[tests[0].Path]; expected != actual {
+		t.Errorf("The actual number of requests is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	mutex.Unlock()
+}
+
+func TestDiskCache_revalidate(t *testing.T) {
+
+	var mutex sync.Mutex
+	var version = "1"
+	var statuses []int
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		etag := `"v` + version + `"`
+		if etag == request.Header.Get("If-None-Match") {
+			statuses = append(statuses, http.StatusNotModified)
+			responseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		statuses = append(statuses, http.StatusOK)
+		responseWriter.Header().Set("ETag", etag)
+		responseWriter.Write([]byte(`{"name":"version ` + version + `"}`))
+	}))
+	defer server.Close()
+
+	resolver := nftmeta.Resolver{
+		Cache: &nftmeta.DiskCache{Dir: t.TempDir()},
+	}
+
+	tests := []struct{
+		Version  string
+		Expected string
+	}{
+		{
+			Version:  "1",
+			Expected: "version 1",
+		},
+		{
+			Version:  "1",
+			Expected: "version 1",
+		},
+		{
+			Version:  "2",
+			Expected: "version 2",
+		},
+		{
+			Version:  "2",
+			Expected: "version 2",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		mutex.Lock()
+		version = test.Version
+		mutex.Unlock()
+
+		metadata, err := resolver.Resolve(context.Background(), server.URL+"/token/1")
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.Expected, metadata.Name().GetElse(""); expected != actual {
+			t.Errorf("For test #%d, the actual name is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+
+	mutex.Lock()
+	if expected, actual := []int{200, 304, 200, 304}, statuses; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("The actual response statuses are not what was expected.")
+		t.Logf("EXPECTED: %v", expected)
+		t.Logf("ACTUAL:   %v", actual)
+	}
+	mutex.Unlock()
+}
+
+func TestDiskCache_evict(t *testing.T) {
+
+	var mutex sync.Mutex
+	var conditional = map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		conditional[request.URL.Path] = "" != request.Header.Get("If-None-Match")
+		mutex.Unlock()
+
+		if "" != request.Header.Get("If-None-Match") {
+			responseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		responseWriter.Header().Set("ETag", `"same"`)
+		responseWriter.Write([]byte(`{"name":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	// Each entry is roughly 200 bytes, so only 2 of them fit.
+	resolver := nftmeta.Resolver{
+		Cache: &nftmeta.DiskCache{Dir: t.TempDir(), MaxSize: 450},
+	}
+
+	resolve := func(path string) {
+		if _, err := resolver.Resolve(context.Background(), server.URL+path); nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+		// So that each one is used at a different time.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resolve("/a")
+	resolve("/b")
+	resolve("/a") // "/a" is now more-recently used than "/b".
+	resolve("/c") // So "/b" is what gets evicted.
+
+	tests := []struct{
+		Path     string
+		Expected bool
+	}{
+		{
+			Path:     "/a",
+			Expected: true,
+		},
+		{
+			Path:     "/c",
+			Expected: true,
+		},
+		{
+			Path:     "/b",
+			Expected: false,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		resolve(test.Path)
+
+		mutex.Lock()
+		actual := conditional[test.Path]
+		mutex.Unlock()
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, whether the request for %q was conditional is not what was expected.", testNumber, test.Path)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			continue
+		}
+	}
+}
+
+func TestDiskCache_evictMaxSize(t *testing.T) {
+
+	// "/ipfs/<cid>" is the content of that CID, and anything else is HTTP content.
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if cid, found := strings.CutPrefix(request.URL.Path, "/ipfs/"); found {
+			for i := 0; i < 50; i++ {
+				if data := diskCacheTestIPFSContent(i); nftmeta.IPFSCIDv0(data) == cid {
+					responseWriter.Write(data)
+					return
+				}
+			}
+			http.NotFound(responseWriter, request)
+			return
+		}
+
+		responseWriter.Header().Set("ETag", `"same"`)
+		responseWriter.Write([]byte(`{"name":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	const maxSize = 1000
+
+	cache := &nftmeta.DiskCache{Dir: t.TempDir(), MaxSize: maxSize}
+	resolver := nftmeta.Resolver{
+		IPFSGateways: []string{server.URL},
+		Cache:        cache,
+	}
+
+	for i := 0; i < 50; i++ {
+		path := "/" + strconv.Itoa(i)
+
+		if _, err := resolver.Resolve(context.Background(), server.URL+path); nil != err {
+			t.Fatalf("For %q, did not expect an error but actually got one: (%T) %s", path, err, err)
+		}
+		if _, err := resolver.Resolve(context.Background(), "ipfs://"+nftmeta.IPFSCIDv0(diskCacheTestIPFSContent(i))); nil != err {
+			t.Fatalf("For %q, did not expect an error but actually got one: (%T) %s", path, err, err)
+		}
+
+		var size int64
+		for _, subdir := range []string{"http", "ipfs"} {
+			dirEntries, err := os.ReadDir(filepath.Join(cache.Dir, subdir))
+			if nil != err {
+				t.Fatalf("For %q, did not expect an error but actually got one: (%T) %s", path, err, err)
+			}
+
+			for _, dirEntry := range dirEntries {
+				info, err := dirEntry.Info()
+				if nil != err {
+					t.Fatalf("For %q, did not expect an error but actually got one: (%T) %s", path, err, err)
+				}
+				size += info.Size()
+			}
+		}
+
+		if maxSize < size {
+			t.Errorf("For %q, the size of the cache is bigger than its MaxSize.", path)
+			t.Logf("MAX-SIZE: %d", maxSize)
+			t.Logf("SIZE:     %d", size)
+		}
+		if 0 == size {
+			t.Errorf("For %q, the cache is empty.", path)
+		}
+	}
+}
+
+// diskCacheTestIPFSContent returns the (different) content of the IPFS file #'i'.
+func diskCacheTestIPFSContent(i int) []byte {
+	return []byte(`{"name":"` + strconv.Itoa(i) + strings.Repeat("y", 100) + `"}`)
+}
@@ -5,5 +5,38 @@ import (
 )
 
 const (
-	errTraitTypeNothing = erorr.Error("nftmeta: trait-type is nothing")
+	errARC69NotARC69              = erorr.Error("nftmeta: not ARC-69 metadata (\"standard\" is not \"arc69\")")
+	errAttributeValueMissing      = erorr.Error("nftmeta: attribute value is missing")
+	errBadVarint                  = erorr.Error("nftmeta: bad varint")
+	errCARBadBlock                = erorr.Error("nftmeta: bad CAR block")
+	errCARBadHeader               = erorr.Error("nftmeta: bad CAR header")
+	errCARHeaderMissing           = erorr.Error("nftmeta: CAR header is missing")
+	errCBORBadBignum              = erorr.Error("nftmeta: CBOR bignum is not a bytestring")
+	errCBORBadChunk               = erorr.Error("nftmeta: bad chunk in indefinite-length CBOR bytestring")
+	errCBORUnexpectedEnd          = erorr.Error("nftmeta: unexpected end of CBOR")
+	errCIDNotSHA256               = erorr.Error("nftmeta: CID multihash is not a sha2-256 digest")
+	errCIP68BadDatum              = erorr.Error("nftmeta: CIP-68 datum is not Constr 0 [metadata, version, extra]")
+	errCIP68BadVersion            = erorr.Error("nftmeta: CIP-68 version is not a (non-negative) integer")
+	errCanonicalJSONBadUTF8       = erorr.Error("nftmeta: JSON is not valid UTF-8")
+	errCanonicalJSONTrailingData  = erorr.Error("nftmeta: there is something after the JSON value")
+	errCrawlerNoTokenURI          = erorr.Error("nftmeta: crawler has no TokenURI func")
+	errFileNameEmpty              = erorr.Error("nftmeta: file name is empty")
+	errNEP177NameMissing          = erorr.Error("nftmeta: NEP-177 contract metadata \"name\" is missing")
+	errNEP177SymbolMissing        = erorr.Error("nftmeta: NEP-177 contract metadata \"symbol\" is missing")
+	errNilFolder                  = erorr.Error("nftmeta: nil folder")
+	errNilReader                  = erorr.Error("nftmeta: nil reader")
+	errNilReceiver                = erorr.Error("nftmeta: nil receiver")
+	errNilTokenID                 = erorr.Error("nftmeta: nil token-id")
+	errNilWriter                  = erorr.Error("nftmeta: nil writer")
+	errPlutusBadConstr            = erorr.Error("nftmeta: bad Plutus constr")
+	errPlutusNilInteger           = erorr.Error("nftmeta: nil *big.Int cannot be Plutus data")
+	errProtobufUnexpectedEnd      = erorr.Error("nftmeta: unexpected end of protobuf")
+	errResolverNoArweaveGateway   = erorr.Error("nftmeta: resolver has no Arweave gateway")
+	errResolverNoIPFSGateway      = erorr.Error("nftmeta: resolver has no IPFS gateways")
+	errRevealNotRevealed          = erorr.Error("nftmeta: not revealed yet")
+	errRevealSeedMissing          = erorr.Error("nftmeta: reveal seed is missing")
+	errRevealSourceMissing        = erorr.Error("nftmeta: reveal controller has no Source func")
+	errSellerFeeBasisPointsTooBig = erorr.Error("nftmeta: seller-fee-basis-points is greater than 10000")
+	errTraitTypeNothing           = erorr.Error("nftmeta: trait-type is nothing")
+	errURIEmpty                   = erorr.Error("nftmeta: URI is empty")
 )
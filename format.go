@@ -0,0 +1,19 @@
+package nftmeta
+
+// Format identifies an NFT metadata format (i.e., the JSON shape of an NFT metadata standard).
+type Format string
+
+const (
+	FormatARC3     Format = "arc3"     // Algorand ARC-3
+	FormatARC69    Format = "arc69"    // Algorand ARC-69
+	FormatCIP25    Format = "cip25"    // Cardano CIP-25
+	FormatERC1155  Format = "erc1155"  // Ethereum ERC-1155
+	FormatERC721   Format = "erc721"   // Ethereum ERC-721 (as extended by OpenSea) — i.e., the JSON of MetaData
+	FormatMetaplex Format = "metaplex" // Solana Metaplex
+	FormatTZIP21   Format = "tzip21"   // Tezos TZIP-21
+	FormatXLS24d   Format = "xls24d"   // XRP Ledger XLS-24d
+)
+
+func (receiver Format) String() string {
+	return string(receiver)
+}
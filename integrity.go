@@ -0,0 +1,47 @@
+package nftmeta
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// IntegritySHA256 returns the SRI (Subresource Integrity) string — "sha256-" followed by the base64 SHA-256 digest — of what is read from 'reader'.
+//
+// This is what goes into ARC-3's "image_integrity", "animation_url_integrity", and "external_url_integrity".
+func IntegritySHA256(reader io.Reader) (string, error) {
+	if nil == reader {
+		return "", errNilReader
+	}
+
+	hash, err := base64SHA256(reader)
+	if nil != err {
+		return "", erorr.Errorf("nftmeta: problem reading content to compute its integrity: %w", err)
+	}
+
+	return "sha256-" + hash, nil
+}
+
+// base64SHA256 returns the base64 (standard, with padding) SHA-256 digest of what is read from 'reader'.
+func base64SHA256(reader io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); nil != err {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// IntegritySHA256File is like IntegritySHA256, but for the (local) file at 'path'.
+func IntegritySHA256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if nil != err {
+		return "", erorr.Errorf("nftmeta: problem opening %q to compute its integrity: %w", path, err)
+	}
+	defer file.Close()
+
+	return IntegritySHA256(file)
+}
@@ -0,0 +1,108 @@
+package nftmeta
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// keccak256 returns the (legacy) Keccak-256 digest of the concatenation of 'data'.
+//
+// Note that this is the Keccak-256 used by Ethereum — which differs from the (later) standardized SHA3-256 in its padding.
+//
+// Keccak-256 is not part of the Go standard library, so it is implemented here.
+func keccak256(data ...[]byte) [32]byte {
+	const rate = 136 // (1600 - 2*256) / 8
+
+	var state [25]uint64
+	var block [rate]byte
+	var n int
+
+	absorb := func() {
+		for i := 0; i < rate/8; i++ {
+			state[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+		}
+		keccakF1600(&state)
+		n = 0
+	}
+
+	for _, p := range data {
+		for 0 < len(p) {
+			copied := copy(block[n:], p)
+			n += copied
+			p = p[copied:]
+
+			if rate == n {
+				absorb()
+			}
+		}
+	}
+
+	// Keccak padding (pad10*1, with the 0x01 domain byte rather than SHA3's 0x06).
+	for i := n; i < rate; i++ {
+		block[i] = 0
+	}
+	block[n] ^= 0x01
+	block[rate-1] ^= 0x80
+	absorb()
+
+	var digest [32]byte
+	for i := 0; i < len(digest)/8; i++ {
+		binary.LittleEndian.PutUint64(digest[i*8:], state[i])
+	}
+	return digest
+}
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var keccakRotations = [25]int{
+	 0,  1, 62, 28, 27,
+	36, 44,  6, 55, 20,
+	 3, 10, 43, 25, 39,
+	41, 45, 15, 21,  8,
+	18,  2, 61, 56, 14,
+}
+
+// keccakF1600 applies the Keccak-f[1600] permutation to 'state'.
+//
+// The lane at (x,y) is state[x+5*y].
+func keccakF1600(state *[25]uint64) {
+	var c [5]uint64
+	var b [25]uint64
+
+	for round := 0; round < 24; round++ {
+		// θ
+		for x := 0; x < 5; x++ {
+			c[x] = state[x] ^ state[x+5] ^ state[x+10] ^ state[x+15] ^ state[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				state[x+y] ^= d
+			}
+		}
+
+		// ρ and π
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(state[x+5*y], keccakRotations[x+5*y])
+			}
+		}
+
+		// χ
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				state[x+y] = b[x+y] ^ (^b[(x+1)%5+y] & b[(x+2)%5+y])
+			}
+		}
+
+		// ι
+		state[0] ^= keccakRoundConstants[round]
+	}
+}
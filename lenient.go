@@ -0,0 +1,377 @@
+package nftmeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// utf8BOM is the UTF-8 byte-order-mark.
+const utf8BOM = "\xEF\xBB\xBF"
+
+// UnmarshalMetaDataLenient is like (*MetaData).UnmarshalJSON, but accepts the kinds of malformed NFT metadata JSON that are common in the wild — fixing them up.
+// It returns a warning describing each fix it applied.
+//
+// The fixes are:
+//
+// • a UTF-8 byte-order-mark at the beginning is removed;
+//
+// • trailing commas (such as in [1,2,] or {"a":1,}) are removed;
+//
+// • "attributes": null is treated as no attributes (which (*MetaData).UnmarshalJSON does too, but without a warning);
+//
+// • a "traitType" or "trait-type" (or "displayType" or "display-type") is used as the "trait_type" (or "display_type");
+//
+// • a "value" that is a string holding a JSON number — such as "5" — becomes a number;
+//
+// • a "value" that is a boolean becomes a string — "true" or "false";
+//
+// • an attribute without a trait-type, or without a (usable) value, is skipped.
+//
+// (The map form of "attributes" — such as {"Hat":"Red","Level":5} — is not a fix, since (*MetaData).UnmarshalJSON accepts it too. But its values get the fixes above.)
+//
+// (*MetaData).UnmarshalJSON stays strict, and accepts none of the other fixes.
+func UnmarshalMetaDataLenient(data []byte) (MetaData, []string, error) {
+	var warnings []string
+
+	if bytes.HasPrefix(data, []byte(utf8BOM)) {
+		data = data[len(utf8BOM):]
+		warnings = append(warnings, "removed UTF-8 byte-order-mark")
+	}
+
+	{
+		var offsets []int
+		data, offsets = removeTrailingCommas(data)
+		for _, offset := range offsets {
+			warnings = append(warnings, "removed trailing comma at byte offset "+strconv.Itoa(offset))
+		}
+	}
+
+	var raw struct {
+		AnimationURL    *string         `json:"animation_url"`
+		BackgroundColor *string         `json:"background_color"`
+		Description     *string         `json:"description"`
+		ExternalLink    *string         `json:"external_link"`
+		Image           *string         `json:"image"`
+		ImageData       *string         `json:"image_data"`
+		Name            *string         `json:"name"`
+		YouTubeURL      *string         `json:"youtube_url"`
+		Attributes      json.RawMessage `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return MetaData{}, warnings, erorr.Errorf("nftmeta: problem json-unmarshaling metadata: %w", err)
+	}
+
+	var metadata MetaData
+
+	metadata.animationURL    = optionalString(raw.AnimationURL)
+	metadata.backgroundColor = optionalString(raw.BackgroundColor)
+	metadata.description     = optionalString(raw.Description)
+	metadata.externalLink    = optionalString(raw.ExternalLink)
+	metadata.image           = optionalString(raw.Image)
+	metadata.imageData       = optionalString(raw.ImageData)
+	metadata.name            = optionalString(raw.Name)
+	metadata.youtubeURL      = optionalString(raw.YouTubeURL)
+
+	attributes, attributeWarnings, err := unmarshalAttributesLenient(raw.Attributes)
+	warnings = append(warnings, attributeWarnings...)
+	if nil != err {
+		return MetaData{}, warnings, err
+	}
+	metadata.attributes = attributes
+
+	return metadata, warnings, nil
+}
+
+// removeTrailingCommas returns 'data' without any commas that are (ignoring whitespace) right before a '}' or ']', and the byte offsets (in 'data') of the commas removed.
+// Commas inside of JSON strings are left alone.
+func removeTrailingCommas(data []byte) ([]byte, []int) {
+	var result []byte
+	var offsets []int
+
+	var inString bool
+	var escaped bool
+
+	for index := 0; index < len(data); index++ {
+		b := data[index]
+
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case '\\' == b:
+				escaped = true
+			case '"' == b:
+				inString = false
+			}
+		case '"' == b:
+			inString = true
+		case ',' == b:
+			next := index + 1
+			for next < len(data) && 0 <= strings.IndexByte(" \t\r\n", data[next]) {
+				next++
+			}
+			if next < len(data) && ('}' == data[next] || ']' == data[next]) {
+				if nil == result {
+					result = append(result, data[:index]...)
+				}
+				offsets = append(offsets, index)
+				continue
+			}
+		}
+
+		if nil != result {
+			result = append(result, b)
+		}
+	}
+
+	if nil == result {
+		return data, nil
+	}
+	return result, offsets
+}
+
+// unmarshalAttributesLenient turns the JSON "attributes" 'data' into attributes — accepting an array, an object, or null.
+func unmarshalAttributesLenient(data json.RawMessage) ([]Attribute, []string, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) <= 0 {
+		return nil, nil, nil
+	}
+
+	var warnings []string
+
+	switch data[0] {
+	case 'n':
+		if "null" == string(data) {
+			return nil, []string{`"attributes" is null; treated as no attributes`}, nil
+		}
+	case '{':
+		entries, err := orderedObjectEntries(data)
+		if nil != err {
+			return nil, nil, err
+		}
+
+		// A single attribute, that is not in an array.
+		if _, found := lenientAttributeTraitType(entries); found {
+			attribute, ok, attributeWarnings := unmarshalAttributeLenient("attributes", entries)
+			warnings = append(warnings, `"attributes" is a single attribute rather than an array; treated as an array of one`)
+			warnings = append(warnings, attributeWarnings...)
+			if !ok {
+				return nil, warnings, nil
+			}
+			return []Attribute{attribute}, warnings, nil
+		}
+
+		// The map form of attributes — which (like the strict decoder) is ordered by trait-type.
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+
+		var attributes []Attribute
+		for _, entry := range entries {
+			where := "attributes." + entry.name
+
+			value, ok, valueWarnings := unmarshalAttributeValueLenient(where, entry.value)
+			warnings = append(warnings, valueWarnings...)
+			if !ok {
+				continue
+			}
+
+			attributes = append(attributes, Attribute{
+				traitType: opt.Something(entry.name),
+				value:     value,
+			})
+		}
+		return attributes, warnings, nil
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(data, &elements); nil != err {
+			return nil, nil, erorr.Errorf("nftmeta: problem json-unmarshaling attributes: %w", err)
+		}
+
+		var attributes []Attribute
+		for index, element := range elements {
+			where := "attributes[" + strconv.Itoa(index) + "]"
+
+			entries, err := orderedObjectEntries(element)
+			if nil != err {
+				warnings = append(warnings, where+" is not an object; skipped")
+				continue
+			}
+
+			attribute, ok, attributeWarnings := unmarshalAttributeLenient(where, entries)
+			warnings = append(warnings, attributeWarnings...)
+			if !ok {
+				continue
+			}
+
+			attributes = append(attributes, attribute)
+		}
+		return attributes, warnings, nil
+	}
+
+	return nil, nil, erorr.Errorf("nftmeta: \"attributes\" is JSON %s rather than an array or an object", data)
+}
+
+// lenientAttributeTraitType returns the trait-type of the attribute 'entries', under any of its accepted spellings.
+func lenientAttributeTraitType(entries []orderedObjectEntry) (orderedObjectEntry, bool) {
+	return lookupOrderedObjectEntry(entries, "trait_type", "traitType", "trait-type")
+}
+
+// unmarshalAttributeLenient turns the attribute 'entries' into an Attribute. It returns false if the attribute should be skipped.
+func unmarshalAttributeLenient(where string, entries []orderedObjectEntry) (Attribute, bool, []string) {
+	var warnings []string
+
+	var attribute Attribute
+
+	{
+		entry, found := lenientAttributeTraitType(entries)
+		if !found {
+			return Attribute{}, false, []string{where + " has no trait-type; skipped"}
+		}
+		if "trait_type" != entry.name {
+			warnings = append(warnings, where+" has "+strconv.Quote(entry.name)+" rather than \"trait_type\"")
+		}
+
+		var traitType string
+		if err := json.Unmarshal(entry.value, &traitType); nil != err {
+			return Attribute{}, false, append(warnings, where+" has a trait-type that is not a string; skipped")
+		}
+		attribute.traitType = opt.Something(traitType)
+	}
+
+	if entry, found := lookupOrderedObjectEntry(entries, "display_type", "displayType", "display-type"); found {
+		if "display_type" != entry.name {
+			warnings = append(warnings, where+" has "+strconv.Quote(entry.name)+" rather than \"display_type\"")
+		}
+
+		var displayType *string
+		if err := json.Unmarshal(entry.value, &displayType); nil != err {
+			warnings = append(warnings, where+" has a display-type that is not a string; ignored")
+		} else if nil != displayType {
+			attribute.displayType = opt.Something(*displayType)
+		}
+	}
+
+	{
+		entry, found := lookupOrderedObjectEntry(entries, "value")
+		if !found {
+			return Attribute{}, false, append(warnings, where+" has no \"value\"; skipped")
+		}
+
+		value, ok, valueWarnings := unmarshalAttributeValueLenient(where+".value", entry.value)
+		warnings = append(warnings, valueWarnings...)
+		if !ok {
+			return Attribute{}, false, warnings
+		}
+		attribute.value = value
+	}
+
+	return attribute, true, warnings
+}
+
+// unmarshalAttributeValueLenient is like unmarshalAttributeValue, but turns a string holding a JSON number into a number, and a boolean into a string.
+// It returns false if the value cannot be used.
+func unmarshalAttributeValueLenient(where string, data json.RawMessage) (interface{}, bool, []string) {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case "true" == string(data) || "false" == string(data):
+		return string(data), true, []string{where + " is a boolean; became the string " + strconv.Quote(string(data))}
+	case "null" == string(data):
+		return nil, false, []string{where + " is null; skipped"}
+	}
+
+	value, err := unmarshalAttributeValue(data)
+	if nil != err {
+		return nil, false, []string{where + " cannot be an attribute value (" + err.Error() + "); skipped"}
+	}
+
+	if str, ok := value.(string); ok && isJSONNumber(str) {
+		number, err := parseAttributeNumber(str)
+		if nil == err {
+			return number, true, []string{where + " is the string " + strconv.Quote(str) + "; became a number"}
+		}
+	}
+
+	return value, true, nil
+}
+
+// isJSONNumber returns true if 'str' is (exactly) a JSON number — so, for example, "5" and "-1.5e3" are, but " 5", "+5", "05", and "0x5" are not.
+func isJSONNumber(str string) bool {
+	if "" == str {
+		return false
+	}
+	if b := str[0]; '-' != b && (b < '0' || '9' < b) {
+		return false
+	}
+
+	var number json.Number
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	if err := decoder.Decode(&number); nil != err {
+		return false
+	}
+	return number.String() == str
+}
+
+// orderedObjectEntry is a name-value entry of a JSON object.
+type orderedObjectEntry struct {
+	name  string
+	value json.RawMessage
+}
+
+// orderedObjectEntries returns the entries of the JSON object 'data', in the order they are in.
+func orderedObjectEntries(data json.RawMessage) ([]orderedObjectEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem json-unmarshaling object: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || '{' != delim {
+		return nil, erorr.Errorf("nftmeta: JSON %s is not an object", data)
+	}
+
+	var entries []orderedObjectEntry
+	for decoder.More() {
+		token, err := decoder.Token()
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-unmarshaling object: %w", err)
+		}
+
+		name, ok := token.(string)
+		if !ok {
+			return nil, erorr.Errorf("nftmeta: JSON object has a name that is a %T rather than a string", token)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-unmarshaling %q: %w", name, err)
+		}
+
+		entries = append(entries, orderedObjectEntry{name: name, value: value})
+	}
+
+	return entries, nil
+}
+
+// lookupOrderedObjectEntry returns the first entry named any of 'names' (trying them in order).
+func lookupOrderedObjectEntry(entries []orderedObjectEntry, names ...string) (orderedObjectEntry, bool) {
+	for _, name := range names {
+		for _, entry := range entries {
+			if name == entry.name {
+				return entry, true
+			}
+		}
+	}
+	return orderedObjectEntry{}, false
+}
@@ -0,0 +1,139 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestUnmarshalMetaDataLenient(t *testing.T) {
+
+	tests := []struct{
+		Data []byte
+		Expected []byte
+		ExpectedWarnings []string
+	}{
+		{
+			Data: []byte(`{"name":"Clean","attributes":[{"trait_type":"Hat","value":"Red"}]}`),
+			Expected: []byte(`{"name":"Clean","attributes":[{"trait_type":"Hat","value":"Red"}]}`),
+		},
+		{
+			Data: []byte("\xEF\xBB\xBF" + `{"name":"BOM"}`),
+			Expected: []byte(`{"name":"BOM"}`),
+			ExpectedWarnings: []string{
+				"removed UTF-8 byte-order-mark",
+			},
+		},
+		{
+			Data: []byte(`{"name":"a,]","attributes":[{"trait_type":"Hat","value":"Red",},],}`),
+			Expected: []byte(`{"name":"a,]","attributes":[{"trait_type":"Hat","value":"Red"}]}`),
+			ExpectedWarnings: []string{
+				"removed trailing comma at byte offset 61",
+				"removed trailing comma at byte offset 63",
+				"removed trailing comma at byte offset 65",
+			},
+		},
+		{
+			Data: []byte(`{"name":"Null","attributes":null}`),
+			Expected: []byte(`{"name":"Null"}`),
+			ExpectedWarnings: []string{
+				`"attributes" is null; treated as no attributes`,
+			},
+		},
+		{
+			Data: []byte(`{"attributes":{"Level":"5","Hat":"Red","Power":1.5}}`),
+			Expected: []byte(`{"attributes":[{"trait_type":"Hat","value":"Red"},{"trait_type":"Level","value":5},{"trait_type":"Power","value":1.5}]}`),
+			ExpectedWarnings: []string{
+				`attributes.Level is the string "5"; became a number`,
+			},
+		},
+		{
+			Data: []byte(`{"attributes":[{"traitType":"Hat","value":"Red"},{"trait-type":"Level","displayType":"number","value":"-12"},{"value":"orphan"},{"trait_type":"Shiny","value":true},{"trait_type":"Code","value":"007"}]}`),
+			Expected: []byte(`{"attributes":[{"trait_type":"Hat","value":"Red"},{"display_type":"number","trait_type":"Level","value":-12},{"trait_type":"Shiny","value":"true"},{"trait_type":"Code","value":"007"}]}`),
+			ExpectedWarnings: []string{
+				`attributes[0] has "traitType" rather than "trait_type"`,
+				`attributes[1] has "trait-type" rather than "trait_type"`,
+				`attributes[1] has "displayType" rather than "display_type"`,
+				`attributes[1].value is the string "-12"; became a number`,
+				`attributes[2] has no trait-type; skipped`,
+				`attributes[3].value is a boolean; became the string "true"`,
+			},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		metadata, warnings, err := nftmeta.UnmarshalMetaDataLenient(test.Data)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		actual, err := json.Marshal(metadata)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error when marshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual metadata is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+
+		if expected, actual := test.ExpectedWarnings, warnings; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual warnings are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %#v", expected)
+			t.Logf("ACTUAL:   %#v", actual)
+			continue
+		}
+	}
+}
+
+func TestUnmarshalMetaDataLenient_strictStaysStrict(t *testing.T) {
+
+	for testNumber, data := range []string{
+		"\xEF\xBB\xBF" + `{"name":"BOM"}`,
+		`{"name":"x",}`,
+		`{"attributes":{"Shiny":true}}`,
+		`{"attributes":[{"traitType":"Hat","value":"Red"}]}`,
+	}{
+		var metadata nftmeta.MetaData
+		if err := json.Unmarshal([]byte(data), &metadata); nil == err {
+			t.Errorf("For test #%d, expected the strict decoder to return an error, but it did not actually return one.", testNumber)
+			t.Logf("DATA: %s", data)
+			continue
+		}
+
+		if _, _, err := nftmeta.UnmarshalMetaDataLenient([]byte(data)); nil != err {
+			t.Errorf("For test #%d, did not expect the lenient decoder to return an error but it actually returned one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("DATA: %s", data)
+			continue
+		}
+	}
+}
+
+func TestUnmarshalMetaDataLenient_strictAttributesNull(t *testing.T) {
+
+	var data = []byte(`{"name":"Null","attributes":null}`)
+
+	// The strict decoder also treats "attributes": null as no attributes — it just does not warn about it.
+	var metadata nftmeta.MetaData
+	if err := json.Unmarshal(data, &metadata); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := 0, len(metadata.Attributes()); expected != actual {
+		t.Errorf("The actual number of attributes is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+}
@@ -1,6 +1,9 @@
 package nftmeta
 
 import (
+	"encoding/json"
+
+	"sourcecode.social/reiver/go-erorr"
 	"sourcecode.social/reiver/go-opt"
 )
 
@@ -18,6 +21,22 @@ type MetaData struct {
 }
 
 func (receiver MetaData) MarshalJSON() ([]byte, error) {
+	return receiver.marshalJSON(false)
+}
+
+// MarshalJSONAttributeMap is like MarshalJSON, but the "attributes" are in the map form — i.e., an object of trait-type to value. For example:
+//
+//	"attributes":{"Background":"Blue","Eyes":"Laser"}
+//
+// (rather than an array of {"trait_type","value"} objects). This is for consumers that require the map form.
+//
+// The map form has no display-types; so they are left out.
+// MarshalJSONAttributeMap returns an error if two attributes have the same trait-type, since the map form cannot hold both.
+func (receiver MetaData) MarshalJSONAttributeMap() ([]byte, error) {
+	return receiver.marshalJSON(true)
+}
+
+func (receiver MetaData) marshalJSON(attributeMap bool) ([]byte, error) {
 
 	var after bool
 
@@ -183,20 +202,28 @@ func (receiver MetaData) MarshalJSON() ([]byte, error) {
 		}
 		after = true
 
-		p = append(p , `"attributes":[`...)
-		for index, attribute := range receiver.attributes {
-			if 0 < index {
-				p = append(p, ',')
-			}
-
-			bytes, err := attribute.MarshalJSON()
+		if attributeMap {
+			var err error
+			p, err = appendAttributeMap(p, "attributes", receiver.attributes)
 			if nil != err {
 				return nil, err
 			}
-
-			p = append(p, bytes...)
+		} else {
+			p = append(p , `"attributes":[`...)
+			for index, attribute := range receiver.attributes {
+				if 0 < index {
+					p = append(p, ',')
+				}
+
+				bytes, err := attribute.MarshalJSON()
+				if nil != err {
+					return nil, err
+				}
+
+				p = append(p, bytes...)
+			}
+			p = append(p, ']')
 		}
-		p = append(p, ']')
 	}
 
 	p = append(p, '}')
@@ -239,3 +266,88 @@ func (receiver *MetaData) SetYouTubeURL(value string) {
 func (receiver *MetaData) AppendAttribute(attribute Attribute) {
 	receiver.attributes = append(receiver.attributes, attribute)
 }
+
+func (receiver MetaData) AnimationURL() opt.Optional[string] {
+	return receiver.animationURL
+}
+
+func (receiver MetaData) BackgroundColor() opt.Optional[string] {
+	return receiver.backgroundColor
+}
+
+func (receiver MetaData) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver MetaData) ExternalLink() opt.Optional[string] {
+	return receiver.externalLink
+}
+
+func (receiver MetaData) Image() opt.Optional[string] {
+	return receiver.image
+}
+
+func (receiver MetaData) ImageData() opt.Optional[string] {
+	return receiver.imageData
+}
+
+func (receiver MetaData) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver MetaData) YouTubeURL() opt.Optional[string] {
+	return receiver.youtubeURL
+}
+
+// Attributes returns (a copy of) the attributes.
+func (receiver MetaData) Attributes() []Attribute {
+	if len(receiver.attributes) <= 0 {
+		return nil
+	}
+
+	attributes := make([]Attribute, len(receiver.attributes))
+	copy(attributes, receiver.attributes)
+	return attributes
+}
+
+func (receiver *MetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		AnimationURL    *string         `json:"animation_url"`
+		BackgroundColor *string         `json:"background_color"`
+		Description     *string         `json:"description"`
+		ExternalLink    *string         `json:"external_link"`
+		Image           *string         `json:"image"`
+		ImageData       *string         `json:"image_data"`
+		Name            *string         `json:"name"`
+		YouTubeURL      *string         `json:"youtube_url"`
+		Attributes      json.RawMessage `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling metadata: %w", err)
+	}
+
+	var metadata MetaData
+
+	metadata.animationURL    = optionalString(raw.AnimationURL)
+	metadata.backgroundColor = optionalString(raw.BackgroundColor)
+	metadata.description     = optionalString(raw.Description)
+	metadata.externalLink    = optionalString(raw.ExternalLink)
+	metadata.image           = optionalString(raw.Image)
+	metadata.imageData       = optionalString(raw.ImageData)
+	metadata.name            = optionalString(raw.Name)
+	metadata.youtubeURL      = optionalString(raw.YouTubeURL)
+
+	attributes, err := unmarshalAttributes(raw.Attributes)
+	if nil != err {
+		return err
+	}
+	metadata.attributes = attributes
+
+	*receiver = metadata
+	return nil
+}
@@ -0,0 +1,53 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMetaData_MarshalCanonicalJSON(t *testing.T) {
+
+	tests := []struct{
+		JSON     string
+		Expected string
+	}{
+		{
+			JSON:     `{"name":"A<b>&é","image":"ipfs://x","description":"d","attributes":[{"value":5,"trait_type":"Level","display_type":"number"}]}`,
+			Expected: `{"attributes":[{"display_type":"number","trait_type":"Level","value":5}],"description":"d","image":"ipfs://x","name":"A<b>&é"}`,
+		},
+		{
+			// The string and the numbers are from the example in RFC 8785 (section 3.2.2).
+			JSON:     `{"name":"€$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","attributes":[{"trait_type":"a","value":333333333.33333329},{"trait_type":"b","value":1E30},{"trait_type":"c","value":4.50},{"trait_type":"d","value":2e-3},{"trait_type":"e","value":0.000000000000000000000000001},{"trait_type":"f","value":-0}]}`,
+			Expected: `{"attributes":[{"trait_type":"a","value":333333333.3333333},{"trait_type":"b","value":1e+30},{"trait_type":"c","value":4.5},{"trait_type":"d","value":0.002},{"trait_type":"e","value":1e-27},{"trait_type":"f","value":0}],"name":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			JSON:     `{"name":"x",  "background_color" : "ffffff"}`,
+			Expected: `{"background_color":"ffffff","name":"x"}`,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var metadata nftmeta.MetaData
+		if err := metadata.UnmarshalJSON([]byte(test.JSON)); nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		actual, err := metadata.MarshalCanonicalJSON()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != string(actual) {
+			t.Errorf("For test #%d, the actual canonical JSON is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMetaData_MarshalJSONAttributeMap(t *testing.T) {
+
+	tests := []struct{
+		MetaData nftmeta.MetaData
+		Expected []byte
+	}{
+		{
+			MetaData: nftmeta.MetaData{},
+			Expected: []byte(`{}`),
+		},
+		{
+			MetaData: func()nftmeta.MetaData{
+				var metadata nftmeta.MetaData
+				metadata.SetName("Laser Ape")
+				metadata.AppendAttribute(nftmeta.AttributeString("Eyes", "Laser"))
+				metadata.AppendAttribute(nftmeta.AttributeString("Background", "Blue"))
+				metadata.AppendAttribute(nftmeta.TypedAttributeUint64("Level", 5, "number"))
+
+				return metadata
+			}(),
+			Expected: []byte(`{"name":"Laser Ape","attributes":{"Eyes":"Laser","Background":"Blue","Level":5}}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := test.MetaData.MarshalJSONAttributeMap()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual JSON is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
+
+func TestMetaData_MarshalJSONAttributeMap_duplicate(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.AppendAttribute(nftmeta.AttributeString("Hat", "Red"))
+	metadata.AppendAttribute(nftmeta.AttributeString("Hat", "Blue"))
+
+	if _, err := metadata.MarshalJSONAttributeMap(); nil == err {
+		t.Errorf("Expected an error for duplicate trait-types, but did not actually get one.")
+	}
+
+	// The array form can still hold them.
+	if _, err := metadata.MarshalJSON(); nil != err {
+		t.Errorf("Did not expect an error from the array form, but actually got one: (%T) %s", err, err)
+	}
+}
+
+func TestMetaData_UnmarshalJSON_attributeMap(t *testing.T) {
+
+	var data = []byte(`{"name":"Laser Ape","attributes":{"Eyes":"Laser","Background":"Blue","Level":5}}`)
+
+	var metadata nftmeta.MetaData
+	if err := json.Unmarshal(data, &metadata); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	expected := []byte(`{"name":"Laser Ape","attributes":[{"trait_type":"Background","value":"Blue"},{"trait_type":"Eyes","value":"Laser"},{"trait_type":"Level","value":5}]}`)
+
+	actual, err := json.Marshal(metadata)
+	if nil != err {
+		t.Fatalf("Did not expect an error when marshaling but actually got one: (%T) %s", err, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual JSON is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+
+	if err := json.Unmarshal([]byte(`{"attributes":{"Eyes":["Laser"]}}`), &metadata); nil == err {
+		t.Errorf("Expected an error for a map-form attribute value that is an array, but did not actually get one.")
+	}
+}
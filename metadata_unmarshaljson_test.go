@@ -0,0 +1,105 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMetaData_UnmarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		JSON []byte
+	}{
+		{
+			JSON: []byte(`{}`),
+		},
+		{
+			JSON: []byte(`{"animation_url":"http://example.com/video.mp4"}`),
+		},
+		{
+			JSON: []byte(`{"description":"To explore!","external_link":"http://example.com/token/123","name":"peanut-butter-jelly-time"}`),
+		},
+		{
+			JSON: []byte(`{"image":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco/wiki/","image_data":"data:image/svg+xml;base64,PHN2Zy8+","youtube_url":"https://youtu.be/eRBOgtp0Hac"}`),
+		},
+		{
+			JSON: []byte(`{"name":"thing","attributes":[{"trait_type":"Bread 1","value":"Peanut Butter"},{"trait_type":"Bread 2","value":"Jelly"}]}`),
+		},
+		{
+			JSON: []byte(`{"attributes":[{"display_type":"number","trait_type":"Generation","value":2},{"trait_type":"Level","value":-5},{"trait_type":"Big","value":18446744073709551615},{"trait_type":"Bigger","value":123456789012345678901234567890},{"display_type":"boost_percentage","trait_type":"Stamina","value":1.5}]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var metadata nftmeta.MetaData
+
+		err := json.Unmarshal(test.JSON, &metadata)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("JSON:\n%s", test.JSON)
+			continue
+		}
+
+		actual, err := json.Marshal(metadata)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("METADATA: %#v", metadata)
+			continue
+		}
+
+		{
+			expected := test.JSON
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the re-marshaled json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				t.Logf("METADATA: %#v", metadata)
+				continue
+			}
+		}
+	}
+}
+
+func TestMetaData_UnmarshalJSON_fail(t *testing.T) {
+
+	tests := []struct{
+		JSON []byte
+	}{
+		{
+			JSON: []byte(`[]`),
+		},
+		{
+			JSON: []byte(`{"name":5}`),
+		},
+		{
+			JSON: []byte(`{"attributes":[{"value":"no trait-type"}]}`),
+		},
+		{
+			JSON: []byte(`{"attributes":[{"trait_type":"no value"}]}`),
+		},
+		{
+			JSON: []byte(`{"attributes":[{"trait_type":"Object","value":{}}]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var metadata nftmeta.MetaData
+
+		err := json.Unmarshal(test.JSON, &metadata)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("JSON:\n%s", test.JSON)
+			t.Logf("METADATA: %#v", metadata)
+			continue
+		}
+	}
+}
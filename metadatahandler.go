@@ -0,0 +1,228 @@
+package nftmeta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// These are what a MetaDataHandler uses when its field is "".
+const (
+	defaultMetaDataHandlerCacheControl = "public, max-age=300"
+	defaultMetaDataHandlerAllowOrigin  = "*"
+)
+
+// MetaDataHandler is an http.Handler that serves the metadata of tokens, at either of:
+//
+//	/token/{id}
+//	/{id}.json
+//
+// For /token/{id}, the {id} is decimal, or hexadecimal with a "0x". (See ParseTokenID.)
+// For /{id}.json, the {id} can also be (as ERC-1155 substitutes "{id}") exactly 64 hexadecimal digits. (See ParseERC1155TokenID.)
+//
+// Note that 64 digits are ambiguous — they could also be a (decimal) token-id, since a uint256 can have up to 78 decimal digits.
+// So they are only read as hexadecimal on the /{id}.json route (which is the one ERC-1155 URIs use), and are always read as decimal on the /token/{id} route.
+//
+// It answers GET and HEAD requests (including conditional ones, with If-None-Match), and CORS preflight (OPTIONS) requests.
+//
+// If Lookup returns a TokenNotFoundError (or an error that fs.ErrNotExist is), the response is 404 Not Found.
+// If it returns any other error, the response is 500 Internal Server Error.
+type MetaDataHandler struct {
+	// Lookup returns the metadata of the token 'tokenID'.
+	Lookup func(ctx context.Context, tokenID *big.Int) (MetaData, error)
+
+	// CacheControl is the Cache-Control header of each (successful) response.
+	// If it is "", then "public, max-age=300" is used.
+	CacheControl string
+
+	// AllowOrigin is the Access-Control-Allow-Origin header of each response.
+	// If it is "", then "*" is used.
+	AllowOrigin string
+}
+
+// TokenNotFoundError is the error (possibly wrapped) that a MetaDataHandler's Lookup should return when there is no token with the token-id.
+type TokenNotFoundError struct {
+	TokenID *big.Int
+}
+
+func (receiver TokenNotFoundError) Error() string {
+	return "nftmeta: token " + receiver.TokenID.String() + " not found"
+}
+
+// ParseTokenID parses a token-id — which is either decimal (for example, "314592"), or hexadecimal with a "0x" (for example, "0x4cce0").
+//
+// A token-id of 64 digits is decimal. (For the 64 hexadecimal digits that ERC-1155 substitutes "{id}" with, see ParseERC1155TokenID.)
+func ParseTokenID(str string) (*big.Int, error) {
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		return parseTokenID(str, str[2:], 16)
+	}
+	return parseTokenID(str, str, 10)
+}
+
+// ParseERC1155TokenID parses a token-id the way ERC-1155 substitutes "{id}" — which is exactly 64 hexadecimal digits (for example, "000000000000000000000000000000000000000000000000000000000004cce0").
+func ParseERC1155TokenID(str string) (*big.Int, error) {
+	if 64 != len(str) {
+		return nil, erorr.Errorf("nftmeta: %q is not an ERC-1155 token-id, which is 64 hexadecimal digits", truncateForError(str))
+	}
+	return parseTokenID(str, str, 16)
+}
+
+// parseTokenID parses the token-id 'str' — whose digits (in 'base') are 'digits'.
+func parseTokenID(str string, digits string, base int) (*big.Int, error) {
+	// big.Int's SetString would also accept signs and underscores, which a token-id cannot have.
+	for _, r := range digits {
+		if !isDigit(r, base) {
+			return nil, erorr.Errorf("nftmeta: %q is not a token-id", truncateForError(str))
+		}
+	}
+
+	tokenID, ok := big.NewInt(0).SetString(digits, base)
+	if !ok {
+		return nil, erorr.Errorf("nftmeta: %q is not a token-id", truncateForError(str))
+	}
+	if 256 < tokenID.BitLen() {
+		return nil, erorr.Errorf("nftmeta: token-id %q does not fit into 256 bits", truncateForError(str))
+	}
+	return tokenID, nil
+}
+
+func isDigit(r rune, base int) bool {
+	switch {
+	case '0' <= r && r <= '9':
+		return true
+	case 16 == base && 'a' <= r && r <= 'f':
+		return true
+	case 16 == base && 'A' <= r && r <= 'F':
+		return true
+	default:
+		return false
+	}
+}
+
+// tokenIDFromPath returns the token-id of a path that is either /token/{id} or /{id}.json — or false if the path is neither.
+// (An {id} that is not a token-id is an error.)
+func tokenIDFromPath(path string) (*big.Int, bool, error) {
+	if id, found := strings.CutPrefix(path, "/token/"); found && "" != id && !strings.Contains(id, "/") {
+		tokenID, err := ParseTokenID(id)
+		return tokenID, true, err
+	}
+
+	if id, found := strings.CutSuffix(strings.TrimPrefix(path, "/"), ".json"); found && "" != id && !strings.Contains(id, "/") {
+		if 64 == len(id) {
+			tokenID, err := ParseERC1155TokenID(id)
+			return tokenID, true, err
+		}
+
+		tokenID, err := ParseTokenID(id)
+		return tokenID, true, err
+	}
+
+	return nil, false, nil
+}
+
+func (receiver MetaDataHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	header := responseWriter.Header()
+
+	allowOrigin := receiver.AllowOrigin
+	if "" == allowOrigin {
+		allowOrigin = defaultMetaDataHandlerAllowOrigin
+	}
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	if "*" != allowOrigin {
+		header.Add("Vary", "Origin")
+	}
+
+	switch request.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions:
+		header.Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		header.Set("Access-Control-Allow-Headers", "If-None-Match")
+		header.Set("Access-Control-Max-Age", "86400")
+		responseWriter.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		header.Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(responseWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	tokenID, found, err := tokenIDFromPath(request.URL.Path)
+	if !found {
+		http.NotFound(responseWriter, request)
+		return
+	}
+	if nil != err {
+		http.Error(responseWriter, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if nil == receiver.Lookup {
+		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	metadata, err := receiver.Lookup(request.Context(), tokenID)
+	if nil != err {
+		var notFoundError TokenNotFoundError
+		if errors.As(err, &notFoundError) || errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(responseWriter, request)
+			return
+		}
+		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := metadata.MarshalJSON()
+	if nil != err {
+		http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	digest := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(digest[:]) + `"`
+
+	cacheControl := receiver.CacheControl
+	if "" == cacheControl {
+		cacheControl = defaultMetaDataHandlerCacheControl
+	}
+
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", cacheControl)
+	header.Set("Access-Control-Expose-Headers", "ETag")
+
+	if etagMatches(request.Header.Get("If-None-Match"), etag) {
+		responseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/json")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	responseWriter.WriteHeader(http.StatusOK)
+
+	if http.MethodHead == request.Method {
+		return
+	}
+	responseWriter.Write(body)
+}
+
+// etagMatches returns whether the If-None-Match header 'ifNoneMatch' matches the ETag 'etag' — using the weak comparison (which is what If-None-Match uses).
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if "*" == candidate {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
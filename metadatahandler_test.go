@@ -0,0 +1,441 @@
+package nftmeta_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestParseTokenID(t *testing.T) {
+
+	tests := []struct{
+		Value    string
+		Expected string
+	}{
+		{
+			Value:    "0",
+			Expected: "0",
+		},
+		{
+			Value:    "314592",
+			Expected: "314592",
+		},
+		{
+			Value:    "0x4cce0",
+			Expected: "314592",
+		},
+		{
+			// 64 digits are decimal — not the hexadecimal of ERC-1155.
+			Value:    "0000000000000000000000000000000000000000000000000000000000314592",
+			Expected: "314592",
+		},
+		{
+			Value:    "1000000000000000000000000000000000000000000000000000000000000000",
+			Expected: "1000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			Value:    "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+			Expected: "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		tokenID, err := nftmeta.ParseTokenID(test.Value)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+
+		if expected, actual := test.Expected, tokenID.String(); expected != actual {
+			t.Errorf("For test #%d, the actual token-id is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+	}
+}
+
+func TestParseTokenID_bad(t *testing.T) {
+
+	tests := []struct{
+		Value string
+	}{
+		{
+			Value: "",
+		},
+		{
+			Value: "-1",
+		},
+		{
+			Value: "+1",
+		},
+		{
+			Value: "1_000",
+		},
+		{
+			Value: "4cce0",
+		},
+		{
+			Value: "0x",
+		},
+		{
+			Value: "0x1" + "0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			Value: "000000000000000000000000000000000000000000000000000000000004cce0",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := nftmeta.ParseTokenID(test.Value)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+	}
+}
+
+func TestParseERC1155TokenID(t *testing.T) {
+
+	tests := []struct{
+		Value       string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Value:    "000000000000000000000000000000000000000000000000000000000004cce0",
+			Expected: "314592",
+		},
+		{
+			Value:    "000000000000000000000000000000000000000000000000000000000004CCE0",
+			Expected: "314592",
+		},
+		{
+			Value:    "0000000000000000000000000000000000000000000000000000000000314592",
+			Expected: "3229074",
+		},
+		{
+			Value:       "314592",
+			ExpectError: true,
+		},
+		{
+			Value:       "0x000000000000000000000000000000000000000000000000000000000004cce0",
+			ExpectError: true,
+		},
+		{
+			Value:       "000000000000000000000000000000000000000000000000000000000004ccex",
+			ExpectError: true,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		tokenID, err := nftmeta.ParseERC1155TokenID(test.Value)
+		if test.ExpectError {
+			if nil == err {
+				t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+				t.Logf("VALUE: %q", test.Value)
+			}
+			continue
+		}
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+
+		if expected, actual := test.Expected, tokenID.String(); expected != actual {
+			t.Errorf("For test #%d, the actual token-id is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			t.Logf("VALUE: %q", test.Value)
+			continue
+		}
+	}
+}
+
+func TestMetaDataHandler(t *testing.T) {
+
+	handler := nftmeta.MetaDataHandler{
+		Lookup: func(ctx context.Context, tokenID *big.Int) (nftmeta.MetaData, error) {
+			switch tokenID.Int64() {
+			case 1, 314592:
+				var metadata nftmeta.MetaData
+				metadata.SetName(fmt.Sprintf("Token #%s", tokenID))
+				return metadata, nil
+			case 404:
+				return nftmeta.MetaData{}, fmt.Errorf("lookup: %w", fs.ErrNotExist)
+			case 500:
+				return nftmeta.MetaData{}, errors.New("database is down")
+			default:
+				return nftmeta.MetaData{}, nftmeta.TokenNotFoundError{TokenID: tokenID}
+			}
+		},
+	}
+
+	tests := []struct{
+		Method       string
+		Path         string
+		IfNoneMatch  string
+		ExpectedCode int
+		ExpectedBody string
+	}{
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/1",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"name":"Token #1"}`,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/1.json",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"name":"Token #1"}`,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/000000000000000000000000000000000000000000000000000000000004cce0.json",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"name":"Token #314592"}`,
+		},
+		{
+			Method:       http.MethodHead,
+			Path:         "/token/1",
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: "",
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/1",
+			IfNoneMatch:  `"nope", W/"also-nope"`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"name":"Token #1"}`,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/1",
+			IfNoneMatch:  "*",
+			ExpectedCode: http.StatusNotModified,
+			ExpectedBody: "",
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/2",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/404",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/500",
+			ExpectedCode: http.StatusInternalServerError,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/token/apple",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/something/1",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Method:       http.MethodPost,
+			Path:         "/token/1",
+			ExpectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			Method:       http.MethodOptions,
+			Path:         "/token/1",
+			ExpectedCode: http.StatusNoContent,
+			ExpectedBody: "",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		request := httptest.NewRequest(test.Method, test.Path, nil)
+		if "" != test.IfNoneMatch {
+			request.Header.Set("If-None-Match", test.IfNoneMatch)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if expected, actual := test.ExpectedCode, recorder.Code; expected != actual {
+			t.Errorf("For test #%d, the actual status code is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			t.Logf("METHOD: %s", test.Method)
+			t.Logf("PATH: %s", test.Path)
+			continue
+		}
+
+		if expected, actual := "*", recorder.Header().Get("Access-Control-Allow-Origin"); expected != actual {
+			t.Errorf("For test #%d, the actual Access-Control-Allow-Origin is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if http.StatusOK != test.ExpectedCode && "" == test.ExpectedBody {
+			continue
+		}
+
+		if expected, actual := test.ExpectedBody, recorder.Body.String(); expected != actual {
+			t.Errorf("For test #%d, the actual body is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if http.StatusOK != test.ExpectedCode {
+			continue
+		}
+
+		if expected, actual := "application/json", recorder.Header().Get("Content-Type"); expected != actual {
+			t.Errorf("For test #%d, the actual Content-Type is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := "public, max-age=300", recorder.Header().Get("Cache-Control"); expected != actual {
+			t.Errorf("For test #%d, the actual Cache-Control is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestMetaDataHandler_64Digits(t *testing.T) {
+
+	handler := nftmeta.MetaDataHandler{
+		Lookup: func(ctx context.Context, tokenID *big.Int) (nftmeta.MetaData, error) {
+			var metadata nftmeta.MetaData
+			metadata.SetName(tokenID.String())
+			return metadata, nil
+		},
+	}
+
+	const digits = "1000000000000000000000000000000000000000000000000000000000000000"
+
+	tests := []struct{
+		Path         string
+		ExpectedCode int
+		Expected     string
+	}{
+		{
+			// On /token/{id}, 64 digits are decimal.
+			Path:         "/token/" + digits,
+			ExpectedCode: http.StatusOK,
+			Expected:     digits,
+		},
+		{
+			// On /{id}.json (which is the route ERC-1155 URIs use), 64 digits are hexadecimal — 0x1 followed by 63 zeros, which is 2^252.
+			Path:         "/" + digits + ".json",
+			ExpectedCode: http.StatusOK,
+			Expected:     new(big.Int).Lsh(big.NewInt(1), 252).String(),
+		},
+		{
+			Path:         "/token/000000000000000000000000000000000000000000000000000000000004cce0",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Path:         "/000000000000000000000000000000000000000000000000000000000004cce0.json",
+			ExpectedCode: http.StatusOK,
+			Expected:     "314592",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.Path, nil))
+
+		if expected, actual := test.ExpectedCode, recorder.Code; expected != actual {
+			t.Errorf("For test #%d, the actual status code is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			t.Logf("PATH: %s", test.Path)
+			continue
+		}
+
+		if http.StatusOK != test.ExpectedCode {
+			continue
+		}
+
+		if expected, actual := `{"name":"`+test.Expected+`"}`, recorder.Body.String(); expected != actual {
+			t.Errorf("For test #%d, the actual body is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			t.Logf("PATH: %s", test.Path)
+			continue
+		}
+	}
+}
+
+func TestMetaDataHandler_etag(t *testing.T) {
+
+	handler := nftmeta.MetaDataHandler{
+		Lookup: func(ctx context.Context, tokenID *big.Int) (nftmeta.MetaData, error) {
+			var metadata nftmeta.MetaData
+			metadata.SetName("x")
+			return metadata, nil
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/token/1", nil))
+
+	// The strong ETag is the sha256 digest of the body — which is {"name":"x"}.
+	etag := recorder.Header().Get("ETag")
+	if expected, actual := `"0229d37e33daae149bf40543a5ce1db4459d10f830d5139279aa2bfd5f6485a1"`, etag; expected != actual {
+		t.Errorf("The actual ETag is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+
+	for testNumber, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag} {
+
+		request := httptest.NewRequest(http.MethodGet, "/1.json", nil)
+		request.Header.Set("If-None-Match", ifNoneMatch)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if expected, actual := http.StatusNotModified, recorder.Code; expected != actual {
+			t.Errorf("For test #%d, the actual status code is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+			t.Logf("IF-NONE-MATCH: %s", ifNoneMatch)
+			continue
+		}
+
+		if expected, actual := etag, recorder.Header().Get("ETag"); expected != actual {
+			t.Errorf("For test #%d, the actual ETag is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
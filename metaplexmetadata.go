@@ -0,0 +1,372 @@
+package nftmeta
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// MetaplexMetaData represents the (off-chain) JSON of the Solana Metaplex Token Metadata standard.
+//
+// It differs from the ERC-721 metadata (represented by MetaData) by adding "symbol", "seller_fee_basis_points", and "properties";
+// and by calling its link "external_url" (rather than "external_link").
+//
+// Use MetaplexMetaDataFrom and MetaplexMetaData.MetaData to convert to and from MetaData.
+type MetaplexMetaData struct {
+	animationURL         opt.Optional[string]
+	description          opt.Optional[string]
+	externalURL          opt.Optional[string]
+	image                opt.Optional[string]
+	name                 opt.Optional[string]
+	symbol               opt.Optional[string]
+	sellerFeeBasisPoints opt.Optional[uint64]
+	attributes         []Attribute
+	category             opt.Optional[string]
+	files              []MetaplexFile
+	creators           []MetaplexCreator
+}
+
+// MetaplexFile represents an entry in the "properties.files" array of the Metaplex metadata JSON.
+type MetaplexFile struct {
+	URI  string `json:"uri"`
+	Type string `json:"type"`
+	CDN  bool   `json:"cdn,omitempty"`
+}
+
+// MetaplexCreator represents an entry in the "properties.creators" array of the Metaplex metadata JSON.
+//
+// Share is a percentage. The shares of all the creators must sum to 100.
+type MetaplexCreator struct {
+	Address string `json:"address"`
+	Share   uint64 `json:"share"`
+}
+
+// MetaplexMetaDataFrom returns the Metaplex metadata equivalent of 'metadata'.
+//
+// The "background_color", "image_data", and "youtube_url" of 'metadata' have no Metaplex equivalent, and are not carried over.
+func MetaplexMetaDataFrom(metadata MetaData) MetaplexMetaData {
+	return MetaplexMetaData{
+		animationURL: metadata.animationURL,
+		description:  metadata.description,
+		externalURL:  metadata.externalLink,
+		image:        metadata.image,
+		name:         metadata.name,
+		attributes:   metadata.Attributes(),
+	}
+}
+
+// MetaData returns the ERC-721 metadata equivalent of the Metaplex metadata.
+//
+// The "symbol", "seller_fee_basis_points", and "properties" have no ERC-721 equivalent, and are not carried over.
+func (receiver MetaplexMetaData) MetaData() MetaData {
+	return MetaData{
+		animationURL: receiver.animationURL,
+		description:  receiver.description,
+		externalLink: receiver.externalURL,
+		image:        receiver.image,
+		name:         receiver.name,
+		attributes:   receiver.Attributes(),
+	}
+}
+
+// Validate returns an error if the "seller_fee_basis_points" is greater than 10000,
+// or if there are creators but their shares do not sum to 100 (or any one of them is greater than 100).
+func (receiver MetaplexMetaData) Validate() error {
+	if value, something := receiver.sellerFeeBasisPoints.Get(); something && maxSellerFeeBasisPoints < value {
+		return errSellerFeeBasisPointsTooBig
+	}
+
+	if 0 < len(receiver.creators) {
+		var sum uint64
+		for _, creator := range receiver.creators {
+			// Otherwise the sum could overflow — and, for example, shares of 2^64-1 and 101 would sum to 100.
+			if 100 < creator.Share {
+				return erorr.Errorf("nftmeta: creator %q has a share of %d, which is greater than 100", creator.Address, creator.Share)
+			}
+			sum += creator.Share
+		}
+
+		if 100 != sum {
+			return erorr.Errorf("nftmeta: creator shares sum to %d rather than 100", sum)
+		}
+	}
+
+	return nil
+}
+
+func (receiver MetaplexMetaData) MarshalJSON() ([]byte, error) {
+	if err := receiver.Validate(); nil != err {
+		return nil, err
+	}
+
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, '{')
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"animation_url", receiver.animationURL},
+		{"description",   receiver.description},
+		{"external_url",  receiver.externalURL},
+		{"image",         receiver.image},
+		{"name",          receiver.name},
+		{"symbol",        receiver.symbol},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	{
+		value, something := receiver.sellerFeeBasisPoints.Get()
+		if something {
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			p = append(p, `"seller_fee_basis_points":`...)
+			p = strconv.AppendUint(p, value, 10)
+		}
+	}
+
+	if 0 < len(receiver.attributes) {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		p = append(p, `"attributes":[`...)
+		for index, attribute := range receiver.attributes {
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			bytes, err := attribute.MarshalJSON()
+			if nil != err {
+				return nil, err
+			}
+
+			p = append(p, bytes...)
+		}
+		p = append(p, ']')
+	}
+
+	if 0 < len(receiver.files) || receiver.category.IsSomething() || 0 < len(receiver.creators) {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var properties struct {
+			Files    []MetaplexFile    `json:"files,omitempty"`
+			Category *string           `json:"category,omitempty"`
+			Creators []MetaplexCreator `json:"creators,omitempty"`
+		}
+		properties.Files = receiver.files
+		if value, something := receiver.category.Get(); something {
+			properties.Category = &value
+		}
+		properties.Creators = receiver.creators
+
+		bytes, err := json.Marshal(properties)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling properties: %w", err)
+		}
+
+		p = append(p, `"properties":`...)
+		p = append(p, bytes...)
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func (receiver *MetaplexMetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		AnimationURL         *string     `json:"animation_url"`
+		Description          *string     `json:"description"`
+		ExternalURL          *string     `json:"external_url"`
+		Image                *string     `json:"image"`
+		Name                 *string     `json:"name"`
+		Symbol               *string     `json:"symbol"`
+		SellerFeeBasisPoints *uint64     `json:"seller_fee_basis_points"`
+		Attributes           []Attribute `json:"attributes"`
+		Properties           struct {
+			Files    []MetaplexFile    `json:"files"`
+			Category *string           `json:"category"`
+			Creators []MetaplexCreator `json:"creators"`
+		} `json:"properties"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling metaplex metadata: %w", err)
+	}
+
+	var metadata MetaplexMetaData
+
+	metadata.animationURL = optionalString(raw.AnimationURL)
+	metadata.description  = optionalString(raw.Description)
+	metadata.externalURL  = optionalString(raw.ExternalURL)
+	metadata.image        = optionalString(raw.Image)
+	metadata.name         = optionalString(raw.Name)
+	metadata.symbol       = optionalString(raw.Symbol)
+	if nil != raw.SellerFeeBasisPoints {
+		metadata.sellerFeeBasisPoints = opt.Something(*raw.SellerFeeBasisPoints)
+	}
+	metadata.attributes   = raw.Attributes
+	metadata.files        = raw.Properties.Files
+	metadata.category     = optionalString(raw.Properties.Category)
+	metadata.creators     = raw.Properties.Creators
+
+	if err := metadata.Validate(); nil != err {
+		return err
+	}
+
+	*receiver = metadata
+	return nil
+}
+
+func (receiver MetaplexMetaData) AnimationURL() opt.Optional[string] {
+	return receiver.animationURL
+}
+
+func (receiver MetaplexMetaData) Category() opt.Optional[string] {
+	return receiver.category
+}
+
+func (receiver MetaplexMetaData) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver MetaplexMetaData) ExternalURL() opt.Optional[string] {
+	return receiver.externalURL
+}
+
+func (receiver MetaplexMetaData) Image() opt.Optional[string] {
+	return receiver.image
+}
+
+func (receiver MetaplexMetaData) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver MetaplexMetaData) SellerFeeBasisPoints() opt.Optional[uint64] {
+	return receiver.sellerFeeBasisPoints
+}
+
+func (receiver MetaplexMetaData) Symbol() opt.Optional[string] {
+	return receiver.symbol
+}
+
+// Attributes returns (a copy of) the attributes.
+func (receiver MetaplexMetaData) Attributes() []Attribute {
+	if len(receiver.attributes) <= 0 {
+		return nil
+	}
+
+	attributes := make([]Attribute, len(receiver.attributes))
+	copy(attributes, receiver.attributes)
+	return attributes
+}
+
+// Creators returns (a copy of) the "properties.creators".
+func (receiver MetaplexMetaData) Creators() []MetaplexCreator {
+	if len(receiver.creators) <= 0 {
+		return nil
+	}
+
+	creators := make([]MetaplexCreator, len(receiver.creators))
+	copy(creators, receiver.creators)
+	return creators
+}
+
+// Files returns (a copy of) the "properties.files".
+func (receiver MetaplexMetaData) Files() []MetaplexFile {
+	if len(receiver.files) <= 0 {
+		return nil
+	}
+
+	files := make([]MetaplexFile, len(receiver.files))
+	copy(files, receiver.files)
+	return files
+}
+
+func (receiver *MetaplexMetaData) SetAnimationURL(value string) {
+	receiver.animationURL = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) SetCategory(value string) {
+	receiver.category = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) SetExternalURL(value string) {
+	receiver.externalURL = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) SetImage(value string) {
+	receiver.image = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) SetName(value string) {
+	receiver.name = opt.Something(value)
+}
+
+// SetSellerFeeBasisPoints sets the "seller_fee_basis_points".
+//
+// SetSellerFeeBasisPoints returns an error if 'value' is greater than 10000 (i.e., 100%).
+func (receiver *MetaplexMetaData) SetSellerFeeBasisPoints(value uint64) error {
+	if maxSellerFeeBasisPoints < value {
+		return errSellerFeeBasisPointsTooBig
+	}
+
+	receiver.sellerFeeBasisPoints = opt.Something(value)
+	return nil
+}
+
+func (receiver *MetaplexMetaData) SetSymbol(value string) {
+	receiver.symbol = opt.Something(value)
+}
+
+func (receiver *MetaplexMetaData) AppendAttribute(attribute Attribute) {
+	receiver.attributes = append(receiver.attributes, attribute)
+}
+
+// AppendCreator appends to the "properties.creators".
+//
+// Note that the shares of all the creators must sum to 100 before the metadata will marshal.
+func (receiver *MetaplexMetaData) AppendCreator(creator MetaplexCreator) {
+	receiver.creators = append(receiver.creators, creator)
+}
+
+func (receiver *MetaplexMetaData) AppendFile(file MetaplexFile) {
+	receiver.files = append(receiver.files, file)
+}
@@ -0,0 +1,155 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestMetaplexMetaData_MarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		MetaplexMetaData nftmeta.MetaplexMetaData
+		Expected []byte
+	}{
+		{
+			MetaplexMetaData: nftmeta.MetaplexMetaData{},
+			Expected: []byte(`{}`),
+		},
+		{
+			MetaplexMetaData: func()nftmeta.MetaplexMetaData{
+				var metadata nftmeta.MetaplexMetaData
+				metadata.SetName("Solflare X NFT")
+				metadata.SetSymbol("")
+				metadata.SetDescription("Celebratory Solflare NFT for the Solflare X launch")
+				if err := metadata.SetSellerFeeBasisPoints(500); nil != err {
+					panic(err)
+				}
+				metadata.SetImage("https://www.arweave.net/abcd5678?ext=png")
+				metadata.SetAnimationURL("https://www.arweave.net/efgh1234?ext=mp4")
+				metadata.SetExternalURL("https://solflare.com")
+				metadata.AppendAttribute(nftmeta.AttributeString("web", "yes"))
+				metadata.AppendAttribute(nftmeta.AttributeString("mobile", "yes"))
+				metadata.AppendFile(nftmeta.MetaplexFile{URI:"https://www.arweave.net/abcd5678?ext=png", Type:"image/png"})
+				metadata.AppendFile(nftmeta.MetaplexFile{URI:"https://watch.videodelivery.net/9876jkl", Type:"unknown", CDN:true})
+				metadata.SetCategory("video")
+				metadata.AppendCreator(nftmeta.MetaplexCreator{Address:"xEtQ9Fpv62qdc1GYfpNReMasVTe9YW5bHJwfVKqo72u", Share:60})
+				metadata.AppendCreator(nftmeta.MetaplexCreator{Address:"5NCDkXmGBMcGoHqbjDmMVDkXmGBMcGoHqbjDmMVDkXm", Share:40})
+
+				return metadata
+			}(),
+			Expected: []byte(`{"animation_url":"https://www.arweave.net/efgh1234?ext=mp4","description":"Celebratory Solflare NFT for the Solflare X launch","external_url":"https://solflare.com","image":"https://www.arweave.net/abcd5678?ext=png","name":"Solflare X NFT","symbol":"","seller_fee_basis_points":500,"attributes":[{"trait_type":"web","value":"yes"},{"trait_type":"mobile","value":"yes"}],"properties":{"files":[{"uri":"https://www.arweave.net/abcd5678?ext=png","type":"image/png"},{"uri":"https://watch.videodelivery.net/9876jkl","type":"unknown","cdn":true}],"category":"video","creators":[{"address":"xEtQ9Fpv62qdc1GYfpNReMasVTe9YW5bHJwfVKqo72u","share":60},{"address":"5NCDkXmGBMcGoHqbjDmMVDkXmGBMcGoHqbjDmMVDkXm","share":40}]}}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := json.Marshal(test.MetaplexMetaData)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("METAPLEX-METADATA: %#v", test.MetaplexMetaData)
+			continue
+		}
+
+		{
+			expected := test.Expected
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+
+		{
+			var metadata nftmeta.MetaplexMetaData
+
+			if err := json.Unmarshal(actual, &metadata); nil != err {
+				t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			again, err := json.Marshal(metadata)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(actual, again) {
+				t.Errorf("For test #%d, the re-marshaled json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", actual)
+				t.Logf("ACTUAL:   %q", again)
+				continue
+			}
+		}
+	}
+}
+
+func TestMetaplexMetaData_MarshalJSON_fail(t *testing.T) {
+
+	var metadata nftmeta.MetaplexMetaData
+	metadata.AppendCreator(nftmeta.MetaplexCreator{Address:"xEtQ9Fpv62qdc1GYfpNReMasVTe9YW5bHJwfVKqo72u", Share:60})
+	metadata.AppendCreator(nftmeta.MetaplexCreator{Address:"5NCDkXmGBMcGoHqbjDmMVDkXmGBMcGoHqbjDmMVDkXm", Share:30})
+
+	if _, err := json.Marshal(metadata); nil == err {
+		t.Errorf("Expected an error when creator shares do not sum to 100, but did not actually get one.")
+	}
+
+	if err := json.Unmarshal([]byte(`{"properties":{"creators":[{"address":"xEtQ9Fpv62qdc1GYfpNReMasVTe9YW5bHJwfVKqo72u","share":101}]}}`), &metadata); nil == err {
+		t.Errorf("Expected an error when unmarshaling creator shares that do not sum to 100, but did not actually get one.")
+	}
+
+	// 18446744073709551615 + 101 overflows to 100.
+	if err := json.Unmarshal([]byte(`{"properties":{"creators":[{"address":"xEtQ9Fpv62qdc1GYfpNReMasVTe9YW5bHJwfVKqo72u","share":18446744073709551615},{"address":"5NCDkXmGBMcGoHqbjDmMVDkXmGBMcGoHqbjDmMVDkXm","share":101}]}}`), &metadata); nil == err {
+		t.Errorf("Expected an error when unmarshaling creator shares that overflow to 100, but did not actually get one.")
+	}
+}
+
+func TestMetaplexMetaData_MetaData(t *testing.T) {
+
+	const metaplexJSON = `{"description":"a thing","external_url":"https://example.com/","image":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco","name":"Thing #1","symbol":"THNG","seller_fee_basis_points":250,"attributes":[{"trait_type":"Color","value":"Red"}]}`
+
+	var metaplex nftmeta.MetaplexMetaData
+	if err := json.Unmarshal([]byte(metaplexJSON), &metaplex); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	metadata := metaplex.MetaData()
+
+	{
+		expected := []byte(`{"description":"a thing","external_link":"https://example.com/","image":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco","name":"Thing #1","attributes":[{"trait_type":"Color","value":"Red"}]}`)
+
+		actual, err := json.Marshal(metadata)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("The actual converted metadata is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+
+	{
+		expected := []byte(`{"description":"a thing","external_url":"https://example.com/","image":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco","name":"Thing #1","attributes":[{"trait_type":"Color","value":"Red"}]}`)
+
+		actual, err := json.Marshal(nftmeta.MetaplexMetaDataFrom(metadata))
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("The actual converted-back metaplex metadata is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+}
@@ -0,0 +1,584 @@
+package nftmeta
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// defaultNEP177Spec is the NEP-177 "spec" used when none is set.
+const defaultNEP177Spec = "nft-1.0.0"
+
+// NEP177TokenMetadata represents the (on-chain) TokenMetadata of the NEAR NEP-177 standard.
+//
+// For example:
+//
+//	{"title":"Arch Nemesis: Mail Carrier","description":"...","media":"https://...","media_hash":"...","copies":1}
+//
+// The "media_hash" and "reference_hash" are the base64 SHA-256 of the content that "media" and "reference" point to.
+// (Use NEP177Hash to compute them.) If there is a "media", then there must also be a "media_hash"; likewise for "reference".
+//
+// The "issued_at", "expires_at", "starts_at", and "updated_at" are Unix epoch times, in milliseconds, as strings.
+//
+// Use NEP177TokenMetadataFrom and NEP177TokenMetadata.MetaData to convert to and from MetaData.
+type NEP177TokenMetadata struct {
+	title         opt.Optional[string]
+	description   opt.Optional[string]
+	media         opt.Optional[string]
+	mediaHash     opt.Optional[string]
+	copies        opt.Optional[uint64]
+	issuedAt      opt.Optional[string]
+	expiresAt     opt.Optional[string]
+	startsAt      opt.Optional[string]
+	updatedAt     opt.Optional[string]
+	extra         opt.Optional[string]
+	reference     opt.Optional[string]
+	referenceHash opt.Optional[string]
+}
+
+// NEP177ContractMetadata represents the (on-chain) NFTContractMetadata of the NEAR NEP-177 standard.
+//
+// For example:
+//
+//	{"spec":"nft-1.0.0","name":"Mochi Rising","symbol":"MOCHI","icon":"data:image/svg+xml,...","base_uri":"https://..."}
+//
+// The "spec", "name", and "symbol" are required. If no "spec" is set, then "nft-1.0.0" is used.
+//
+// Use NEP177ContractMetadataFrom and NEP177ContractMetadata.CollectionMetaData to convert to and from CollectionMetaData.
+type NEP177ContractMetadata struct {
+	spec          opt.Optional[string]
+	name          opt.Optional[string]
+	symbol        opt.Optional[string]
+	icon          opt.Optional[string]
+	baseURI       opt.Optional[string]
+	reference     opt.Optional[string]
+	referenceHash opt.Optional[string]
+}
+
+// NEP177Hash returns the base64 SHA-256 digest of what is read from 'reader'.
+//
+// This is what goes into NEP-177's "media_hash" and "reference_hash".
+func NEP177Hash(reader io.Reader) (string, error) {
+	if nil == reader {
+		return "", errNilReader
+	}
+
+	hash, err := base64SHA256(reader)
+	if nil != err {
+		return "", erorr.Errorf("nftmeta: problem reading content to compute its hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// validateNEP177Hash returns an error if the "<name>" is something but "<name>_hash" is not a base64 SHA-256 digest.
+func validateNEP177Hash(name string, value opt.Optional[string], hash opt.Optional[string]) error {
+	if value.IsNothing() && hash.IsNothing() {
+		return nil
+	}
+
+	str, something := hash.Get()
+	if !something {
+		return erorr.Errorf("nftmeta: NEP-177 %q is set, but %q is missing", name, name+"_hash")
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(str)
+	if nil != err || sha256.Size != len(digest) {
+		return erorr.Errorf("nftmeta: NEP-177 %q is not a base64 SHA-256 digest", name+"_hash")
+	}
+
+	return nil
+}
+
+// NEP177TokenMetadataFrom returns the NEP-177 token metadata equivalent of 'metadata'.
+//
+// The "name" becomes the "title"; the "image" becomes the "media"; and the "attributes" go into the "extra" as:
+//
+//	{"attributes":[...]}
+//
+// Nothing else of 'metadata' has a NEP-177 equivalent.
+//
+// Note that (if there is an "image") the "media_hash" still needs to be set before the token metadata will marshal.
+func NEP177TokenMetadataFrom(metadata MetaData) (NEP177TokenMetadata, error) {
+	var token NEP177TokenMetadata
+
+	token.title       = metadata.name
+	token.description = metadata.description
+	token.media       = metadata.image
+
+	if 0 < len(metadata.attributes) {
+		var p []byte
+
+		p = append(p, `{"attributes":[`...)
+		for index, attribute := range metadata.attributes {
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			bytes, err := attribute.MarshalJSON()
+			if nil != err {
+				return NEP177TokenMetadata{}, err
+			}
+
+			p = append(p, bytes...)
+		}
+		p = append(p, `]}`...)
+
+		token.extra = opt.Something(string(p))
+	}
+
+	return token, nil
+}
+
+// MetaData returns the ERC-721 metadata equivalent of the NEP-177 token metadata.
+//
+// If the "extra" is a JSON object with an "attributes" array, then those become the attributes. Otherwise the "extra" is not carried over.
+func (receiver NEP177TokenMetadata) MetaData() MetaData {
+	var metadata MetaData
+
+	metadata.name        = receiver.title
+	metadata.description = receiver.description
+	metadata.image       = receiver.media
+
+	if extra, something := receiver.extra.Get(); something {
+		var raw struct {
+			Attributes []Attribute `json:"attributes"`
+		}
+
+		if err := json.Unmarshal([]byte(extra), &raw); nil == err {
+			metadata.attributes = raw.Attributes
+		}
+	}
+
+	return metadata
+}
+
+// Validate returns an error if there is a "media" without a (valid) "media_hash", or a "reference" without a (valid) "reference_hash".
+func (receiver NEP177TokenMetadata) Validate() error {
+	if err := validateNEP177Hash("media", receiver.media, receiver.mediaHash); nil != err {
+		return err
+	}
+	if err := validateNEP177Hash("reference", receiver.reference, receiver.referenceHash); nil != err {
+		return err
+	}
+
+	return nil
+}
+
+func (receiver NEP177TokenMetadata) MarshalJSON() ([]byte, error) {
+	if err := receiver.Validate(); nil != err {
+		return nil, err
+	}
+
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, '{')
+
+	appendField := func(name string, value opt.Optional[string]) error {
+		str, something := value.Get()
+		if !something {
+			return nil
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, name, str)
+		return err
+	}
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"title",       receiver.title},
+		{"description", receiver.description},
+		{"media",       receiver.media},
+		{"media_hash",  receiver.mediaHash},
+	}{
+		if err := appendField(field.name, field.value); nil != err {
+			return nil, err
+		}
+	}
+
+	if value, something := receiver.copies.Get(); something {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		p = append(p, `"copies":`...)
+		p = strconv.AppendUint(p, value, 10)
+	}
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"issued_at",      receiver.issuedAt},
+		{"expires_at",     receiver.expiresAt},
+		{"starts_at",      receiver.startsAt},
+		{"updated_at",     receiver.updatedAt},
+		{"extra",          receiver.extra},
+		{"reference",      receiver.reference},
+		{"reference_hash", receiver.referenceHash},
+	}{
+		if err := appendField(field.name, field.value); nil != err {
+			return nil, err
+		}
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+// UnmarshalJSON decodes NEP-177 token metadata. A JSON null is treated the same as a missing field.
+//
+// It does not Validate it — since what is on-chain is often not valid (for example, a "media" without a "media_hash") but should still be readable.
+// (Only MarshalJSON requires it to be valid.)
+func (receiver *NEP177TokenMetadata) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		Title         *string `json:"title"`
+		Description   *string `json:"description"`
+		Media         *string `json:"media"`
+		MediaHash     *string `json:"media_hash"`
+		Copies        *uint64 `json:"copies"`
+		IssuedAt      *string `json:"issued_at"`
+		ExpiresAt     *string `json:"expires_at"`
+		StartsAt      *string `json:"starts_at"`
+		UpdatedAt     *string `json:"updated_at"`
+		Extra         *string `json:"extra"`
+		Reference     *string `json:"reference"`
+		ReferenceHash *string `json:"reference_hash"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling nep-177 token metadata: %w", err)
+	}
+
+	var token NEP177TokenMetadata
+
+	token.title         = optionalString(raw.Title)
+	token.description   = optionalString(raw.Description)
+	token.media         = optionalString(raw.Media)
+	token.mediaHash     = optionalString(raw.MediaHash)
+	if nil != raw.Copies {
+		token.copies = opt.Something(*raw.Copies)
+	}
+	token.issuedAt      = optionalString(raw.IssuedAt)
+	token.expiresAt     = optionalString(raw.ExpiresAt)
+	token.startsAt      = optionalString(raw.StartsAt)
+	token.updatedAt     = optionalString(raw.UpdatedAt)
+	token.extra         = optionalString(raw.Extra)
+	token.reference     = optionalString(raw.Reference)
+	token.referenceHash = optionalString(raw.ReferenceHash)
+
+	*receiver = token
+	return nil
+}
+
+func (receiver NEP177TokenMetadata) Copies() opt.Optional[uint64] {
+	return receiver.copies
+}
+
+func (receiver NEP177TokenMetadata) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver NEP177TokenMetadata) ExpiresAt() opt.Optional[string] {
+	return receiver.expiresAt
+}
+
+func (receiver NEP177TokenMetadata) Extra() opt.Optional[string] {
+	return receiver.extra
+}
+
+func (receiver NEP177TokenMetadata) IssuedAt() opt.Optional[string] {
+	return receiver.issuedAt
+}
+
+func (receiver NEP177TokenMetadata) Media() opt.Optional[string] {
+	return receiver.media
+}
+
+func (receiver NEP177TokenMetadata) MediaHash() opt.Optional[string] {
+	return receiver.mediaHash
+}
+
+func (receiver NEP177TokenMetadata) Reference() opt.Optional[string] {
+	return receiver.reference
+}
+
+func (receiver NEP177TokenMetadata) ReferenceHash() opt.Optional[string] {
+	return receiver.referenceHash
+}
+
+func (receiver NEP177TokenMetadata) StartsAt() opt.Optional[string] {
+	return receiver.startsAt
+}
+
+func (receiver NEP177TokenMetadata) Title() opt.Optional[string] {
+	return receiver.title
+}
+
+func (receiver NEP177TokenMetadata) UpdatedAt() opt.Optional[string] {
+	return receiver.updatedAt
+}
+
+func (receiver *NEP177TokenMetadata) SetCopies(value uint64) {
+	receiver.copies = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetExpiresAt(value string) {
+	receiver.expiresAt = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetExtra(value string) {
+	receiver.extra = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetIssuedAt(value string) {
+	receiver.issuedAt = opt.Something(value)
+}
+
+// SetMedia sets the "media", and (from 'content', the bytes that 'url' points to) the "media_hash".
+func (receiver *NEP177TokenMetadata) SetMedia(url string, content []byte) {
+	// Reading from a bytes.Reader cannot fail.
+	hash, _ := base64SHA256(bytes.NewReader(content))
+
+	receiver.media     = opt.Something(url)
+	receiver.mediaHash = opt.Something(hash)
+}
+
+// SetMediaHash sets the "media_hash" — the base64 SHA-256 of the content that "media" points to. (See NEP177Hash.)
+func (receiver *NEP177TokenMetadata) SetMediaHash(value string) {
+	receiver.mediaHash = opt.Something(value)
+}
+
+// SetReference sets the "reference", and (from 'content', the bytes that 'url' points to) the "reference_hash".
+func (receiver *NEP177TokenMetadata) SetReference(url string, content []byte) {
+	// Reading from a bytes.Reader cannot fail.
+	hash, _ := base64SHA256(bytes.NewReader(content))
+
+	receiver.reference     = opt.Something(url)
+	receiver.referenceHash = opt.Something(hash)
+}
+
+// SetReferenceHash sets the "reference_hash" — the base64 SHA-256 of the content that "reference" points to. (See NEP177Hash.)
+func (receiver *NEP177TokenMetadata) SetReferenceHash(value string) {
+	receiver.referenceHash = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetStartsAt(value string) {
+	receiver.startsAt = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetTitle(value string) {
+	receiver.title = opt.Something(value)
+}
+
+func (receiver *NEP177TokenMetadata) SetUpdatedAt(value string) {
+	receiver.updatedAt = opt.Something(value)
+}
+
+// NEP177ContractMetadataFrom returns the NEP-177 contract metadata equivalent of 'collection'.
+//
+// The "name" is used, and the "image" becomes the "icon". Nothing else of 'collection' has a NEP-177 equivalent.
+//
+// Note that the "symbol" still needs to be set before the contract metadata will marshal.
+func NEP177ContractMetadataFrom(collection CollectionMetaData) NEP177ContractMetadata {
+	return NEP177ContractMetadata{
+		name: collection.name,
+		icon: collection.image,
+	}
+}
+
+// CollectionMetaData returns the (OpenSea) collection metadata equivalent of the NEP-177 contract metadata.
+//
+// The "spec", "symbol", "base_uri", "reference", and "reference_hash" have no equivalent, and are not carried over.
+func (receiver NEP177ContractMetadata) CollectionMetaData() CollectionMetaData {
+	return CollectionMetaData{
+		name:  receiver.name,
+		image: receiver.icon,
+	}
+}
+
+// Validate returns an error if the "name" or "symbol" is missing, or if there is a "reference" without a (valid) "reference_hash".
+func (receiver NEP177ContractMetadata) Validate() error {
+	if receiver.name.IsNothing() {
+		return errNEP177NameMissing
+	}
+	if receiver.symbol.IsNothing() {
+		return errNEP177SymbolMissing
+	}
+	if err := validateNEP177Hash("reference", receiver.reference, receiver.referenceHash); nil != err {
+		return err
+	}
+
+	return nil
+}
+
+func (receiver NEP177ContractMetadata) MarshalJSON() ([]byte, error) {
+	if err := receiver.Validate(); nil != err {
+		return nil, err
+	}
+
+	var buffer [256]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, '{')
+
+	var err error
+	p, err = appendJSONNameValue(p, "spec", receiver.Spec())
+	if nil != err {
+		return nil, err
+	}
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"name",           receiver.name},
+		{"symbol",         receiver.symbol},
+		{"icon",           receiver.icon},
+		{"base_uri",       receiver.baseURI},
+		{"reference",      receiver.reference},
+		{"reference_hash", receiver.referenceHash},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		p = append(p, ',')
+
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+// UnmarshalJSON decodes NEP-177 contract metadata. A JSON null is treated the same as a missing field.
+//
+// Like NEP177TokenMetadata.UnmarshalJSON, it does not Validate it.
+func (receiver *NEP177ContractMetadata) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		Spec          *string `json:"spec"`
+		Name          *string `json:"name"`
+		Symbol        *string `json:"symbol"`
+		Icon          *string `json:"icon"`
+		BaseURI       *string `json:"base_uri"`
+		Reference     *string `json:"reference"`
+		ReferenceHash *string `json:"reference_hash"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling nep-177 contract metadata: %w", err)
+	}
+
+	var contract NEP177ContractMetadata
+
+	contract.spec          = optionalString(raw.Spec)
+	contract.name          = optionalString(raw.Name)
+	contract.symbol        = optionalString(raw.Symbol)
+	contract.icon          = optionalString(raw.Icon)
+	contract.baseURI       = optionalString(raw.BaseURI)
+	contract.reference     = optionalString(raw.Reference)
+	contract.referenceHash = optionalString(raw.ReferenceHash)
+
+	*receiver = contract
+	return nil
+}
+
+func (receiver NEP177ContractMetadata) BaseURI() opt.Optional[string] {
+	return receiver.baseURI
+}
+
+func (receiver NEP177ContractMetadata) Icon() opt.Optional[string] {
+	return receiver.icon
+}
+
+func (receiver NEP177ContractMetadata) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver NEP177ContractMetadata) Reference() opt.Optional[string] {
+	return receiver.reference
+}
+
+func (receiver NEP177ContractMetadata) ReferenceHash() opt.Optional[string] {
+	return receiver.referenceHash
+}
+
+// Spec returns the "spec". If none was set, then it returns "nft-1.0.0".
+func (receiver NEP177ContractMetadata) Spec() string {
+	return receiver.spec.GetElse(defaultNEP177Spec)
+}
+
+func (receiver NEP177ContractMetadata) Symbol() opt.Optional[string] {
+	return receiver.symbol
+}
+
+func (receiver *NEP177ContractMetadata) SetBaseURI(value string) {
+	receiver.baseURI = opt.Something(value)
+}
+
+func (receiver *NEP177ContractMetadata) SetIcon(value string) {
+	receiver.icon = opt.Something(value)
+}
+
+func (receiver *NEP177ContractMetadata) SetName(value string) {
+	receiver.name = opt.Something(value)
+}
+
+// SetReference sets the "reference", and (from 'content', the bytes that 'url' points to) the "reference_hash".
+func (receiver *NEP177ContractMetadata) SetReference(url string, content []byte) {
+	// Reading from a bytes.Reader cannot fail.
+	hash, _ := base64SHA256(bytes.NewReader(content))
+
+	receiver.reference     = opt.Something(url)
+	receiver.referenceHash = opt.Something(hash)
+}
+
+// SetReferenceHash sets the "reference_hash" — the base64 SHA-256 of the content that "reference" points to. (See NEP177Hash.)
+func (receiver *NEP177ContractMetadata) SetReferenceHash(value string) {
+	receiver.referenceHash = opt.Something(value)
+}
+
+func (receiver *NEP177ContractMetadata) SetSpec(value string) {
+	receiver.spec = opt.Something(value)
+}
+
+func (receiver *NEP177ContractMetadata) SetSymbol(value string) {
+	receiver.symbol = opt.Something(value)
+}
@@ -0,0 +1,196 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestNEP177TokenMetadata_MarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		TokenMetadata nftmeta.NEP177TokenMetadata
+		Expected []byte
+	}{
+		{
+			TokenMetadata: nftmeta.NEP177TokenMetadata{},
+			Expected: []byte(`{}`),
+		},
+		{
+			TokenMetadata: func()nftmeta.NEP177TokenMetadata{
+				var token nftmeta.NEP177TokenMetadata
+				token.SetTitle("Arch Nemesis: Mail Carrier")
+				token.SetDescription("Message in a bottle")
+				token.SetMedia("https://example.com/mail-carrier.png", []byte("hello"))
+				token.SetCopies(1)
+				token.SetIssuedAt("1640995200000")
+
+				return token
+			}(),
+			Expected: []byte(`{"title":"Arch Nemesis: Mail Carrier","description":"Message in a bottle","media":"https://example.com/mail-carrier.png","media_hash":"LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=","copies":1,"issued_at":"1640995200000"}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := json.Marshal(test.TokenMetadata)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual JSON is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+
+		var token nftmeta.NEP177TokenMetadata
+		if err := json.Unmarshal(actual, &token); nil != err {
+			t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if !bytes.Equal(test.Expected, mustMarshalJSON(t, token)) {
+			t.Errorf("For test #%d, the token metadata did not round-trip.", testNumber)
+			continue
+		}
+	}
+}
+
+func TestNEP177TokenMetadata_mediaHashMissing(t *testing.T) {
+
+	var data = []byte(`{"title":"x","media":"https://example.com/x.png","media_hash":null}`)
+
+	// What is on-chain is still readable, even though it is not valid.
+	var token nftmeta.NEP177TokenMetadata
+	if err := json.Unmarshal(data, &token); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := "https://example.com/x.png", token.Media().GetElse(""); expected != actual {
+		t.Errorf("The actual \"media\" is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	if err := token.Validate(); nil == err {
+		t.Errorf("Expected an error (from Validate) for a \"media\" without a \"media_hash\", but did not actually get one.")
+	}
+
+	if _, err := json.Marshal(token); nil == err {
+		t.Errorf("Expected an error (from MarshalJSON) for a \"media\" without a \"media_hash\", but did not actually get one.")
+	}
+}
+
+func TestNEP177ContractMetadata_referenceHashMissing(t *testing.T) {
+
+	var data = []byte(`{"spec":"nft-1.0.0","name":"Mochi Rising","symbol":"MOCHI","reference":"https://example.com/mochi.json"}`)
+
+	var contract nftmeta.NEP177ContractMetadata
+	if err := json.Unmarshal(data, &contract); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if expected, actual := "https://example.com/mochi.json", contract.Reference().GetElse(""); expected != actual {
+		t.Errorf("The actual \"reference\" is not what was expected.")
+		t.Logf("EXPECTED: %q", expected)
+		t.Logf("ACTUAL:   %q", actual)
+	}
+
+	if err := contract.Validate(); nil == err {
+		t.Errorf("Expected an error (from Validate) for a \"reference\" without a \"reference_hash\", but did not actually get one.")
+	}
+
+	contract.SetReference("https://example.com/mochi.json", []byte("hello"))
+
+	expected := []byte(`{"spec":"nft-1.0.0","name":"Mochi Rising","symbol":"MOCHI","reference":"https://example.com/mochi.json","reference_hash":"LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="}`)
+	actual := mustMarshalJSON(t, contract)
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual JSON is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+}
+
+func TestNEP177TokenMetadataFrom(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("Mochi #7")
+	metadata.SetImage("https://example.com/7.png")
+	metadata.SetExternalLink("https://example.com/7")
+	metadata.AppendAttribute(nftmeta.AttributeString("Flavour", "Matcha"))
+
+	token, err := nftmeta.NEP177TokenMetadataFrom(metadata)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	hash, err := nftmeta.NEP177Hash(strings.NewReader("hello"))
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	token.SetMediaHash(hash)
+
+	{
+		expected := []byte(`{"title":"Mochi #7","media":"https://example.com/7.png","media_hash":"LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=","extra":"{\"attributes\":[{\"trait_type\":\"Flavour\",\"value\":\"Matcha\"}]}"}`)
+		actual := mustMarshalJSON(t, token)
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("The actual JSON is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+
+	{
+		expected := []byte(`{"image":"https://example.com/7.png","name":"Mochi #7","attributes":[{"trait_type":"Flavour","value":"Matcha"}]}`)
+		actual := mustMarshalJSON(t, token.MetaData())
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("The actual JSON of the converted-back metadata is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+}
+
+func TestNEP177ContractMetadata_MarshalJSON(t *testing.T) {
+
+	var contract nftmeta.NEP177ContractMetadata
+	contract.SetName("Mochi Rising")
+
+	if _, err := json.Marshal(contract); nil == err {
+		t.Errorf("Expected an error for contract metadata without a \"symbol\", but did not actually get one.")
+	}
+
+	contract.SetSymbol("MOCHI")
+	contract.SetBaseURI("https://example.com/mochi/")
+
+	expected := []byte(`{"spec":"nft-1.0.0","name":"Mochi Rising","symbol":"MOCHI","base_uri":"https://example.com/mochi/"}`)
+	actual := mustMarshalJSON(t, contract)
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual JSON is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+}
+
+func mustMarshalJSON(t *testing.T, value interface{}) []byte {
+	t.Helper()
+
+	p, err := json.Marshal(value)
+	if nil != err {
+		t.Fatalf("Did not expect an error when json-marshaling %T but actually got one: (%T) %s", value, err, err)
+	}
+	return p
+}
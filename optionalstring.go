@@ -0,0 +1,14 @@
+package nftmeta
+
+import (
+	"sourcecode.social/reiver/go-opt"
+)
+
+// optionalString turns a (possibly nil) *string, such as what encoding/json produces for an optional field, into an opt.Optional[string].
+func optionalString(value *string) opt.Optional[string] {
+	if nil == value {
+		return opt.Nothing[string]()
+	}
+
+	return opt.Something(*value)
+}
@@ -0,0 +1,438 @@
+package nftmeta
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// Plutus data is the (Cardano) data-model that datums use. It is encoded as CBOR.
+//
+// Here Plutus data is represented with these Go types:
+//
+//	plutusConstr  — Constr i [fields...]
+//	plutusMap     — Map [(key, value)...]
+//	[]interface{} — List [items...]
+//	*big.Int      — I n
+//	[]byte        — B bytes
+//
+// The encoding follows what the Haskell Plutus implementation (and so, the Cardano ledger) produces:
+// non-empty lists are indefinite-length, maps are definite-length, and bytestrings longer than 64 bytes are split into 64-byte chunks.
+
+type plutusConstr struct {
+	index  uint64
+	fields []interface{}
+}
+
+type plutusMap []plutusMapEntry
+
+type plutusMapEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+const (
+	cborMajorUnsigned = 0
+	cborMajorNegative = 1
+	cborMajorBytes    = 2
+	cborMajorText     = 3
+	cborMajorArray    = 4
+	cborMajorMap      = 5
+	cborMajorTag      = 6
+	cborMajorSimple   = 7
+)
+
+const (
+	cborIndefinite = 31
+	cborBreak      = 0xff
+)
+
+const (
+	cborTagPositiveBignum = 2
+	cborTagNegativeBignum = 3
+	cborTagConstrGeneral  = 102
+)
+
+// plutusChunkSize is the maximum length of a (chunk of a) bytestring in Plutus data.
+const plutusChunkSize = 64
+
+// cborAppendHead appends a CBOR "head" — i.e., the major-type together with its argument.
+func cborAppendHead(p []byte, major byte, argument uint64) []byte {
+	major <<= 5
+
+	switch {
+	case argument < 24:
+		return append(p, major|byte(argument))
+	case argument <= 0xff:
+		return append(p, major|24, byte(argument))
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(p, major|25), uint16(argument))
+	case argument <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(p, major|26), uint32(argument))
+	default:
+		return binary.BigEndian.AppendUint64(append(p, major|27), argument)
+	}
+}
+
+// appendPlutusData appends the CBOR encoding of the Plutus data 'value'.
+func appendPlutusData(p []byte, value interface{}) ([]byte, error) {
+	switch casted := value.(type) {
+	case plutusConstr:
+		switch {
+		case casted.index <= 6:
+			p = cborAppendHead(p, cborMajorTag, 121+casted.index)
+		case casted.index <= 127:
+			p = cborAppendHead(p, cborMajorTag, 1280+casted.index-7)
+		default:
+			p = cborAppendHead(p, cborMajorTag, cborTagConstrGeneral)
+			p = cborAppendHead(p, cborMajorArray, 2)
+			p = cborAppendHead(p, cborMajorUnsigned, casted.index)
+		}
+		return appendPlutusList(p, casted.fields)
+	case plutusMap:
+		p = cborAppendHead(p, cborMajorMap, uint64(len(casted)))
+		for _, entry := range casted {
+			var err error
+			p, err = appendPlutusData(p, entry.key)
+			if nil != err {
+				return nil, err
+			}
+			p, err = appendPlutusData(p, entry.value)
+			if nil != err {
+				return nil, err
+			}
+		}
+		return p, nil
+	case []interface{}:
+		return appendPlutusList(p, casted)
+	case *big.Int:
+		if nil == casted {
+			return nil, errPlutusNilInteger
+		}
+		return appendPlutusInteger(p, casted), nil
+	case []byte:
+		return appendPlutusBytes(p, casted), nil
+	default:
+		return nil, erorr.Errorf("nftmeta: %T cannot be Plutus data", value)
+	}
+}
+
+func appendPlutusList(p []byte, items []interface{}) ([]byte, error) {
+	if len(items) <= 0 {
+		return cborAppendHead(p, cborMajorArray, 0), nil
+	}
+
+	p = append(p, cborMajorArray<<5|cborIndefinite)
+	for _, item := range items {
+		var err error
+		p, err = appendPlutusData(p, item)
+		if nil != err {
+			return nil, err
+		}
+	}
+	return append(p, cborBreak), nil
+}
+
+func appendPlutusInteger(p []byte, n *big.Int) []byte {
+	if n.IsUint64() {
+		return cborAppendHead(p, cborMajorUnsigned, n.Uint64())
+	}
+
+	// CBOR encodes a negative integer n as -1-n.
+	if 0 > n.Sign() {
+		m := big.NewInt(0).Neg(n)
+		m.Sub(m, big.NewInt(1))
+		if m.IsUint64() {
+			return cborAppendHead(p, cborMajorNegative, m.Uint64())
+		}
+
+		p = cborAppendHead(p, cborMajorTag, cborTagNegativeBignum)
+		return appendPlutusBytes(p, m.Bytes())
+	}
+
+	p = cborAppendHead(p, cborMajorTag, cborTagPositiveBignum)
+	return appendPlutusBytes(p, n.Bytes())
+}
+
+func appendPlutusBytes(p []byte, b []byte) []byte {
+	if len(b) <= plutusChunkSize {
+		p = cborAppendHead(p, cborMajorBytes, uint64(len(b)))
+		return append(p, b...)
+	}
+
+	p = append(p, cborMajorBytes<<5|cborIndefinite)
+	for 0 < len(b) {
+		var n int = len(b)
+		if plutusChunkSize < n {
+			n = plutusChunkSize
+		}
+
+		p = cborAppendHead(p, cborMajorBytes, uint64(n))
+		p = append(p, b[:n]...)
+		b = b[n:]
+	}
+	return append(p, cborBreak)
+}
+
+// decodePlutusData decodes CBOR encoded Plutus data.
+// It returns an error if there is anything after the Plutus data.
+func decodePlutusData(data []byte) (interface{}, error) {
+	var decoder = cborDecoder{data: data}
+
+	value, err := decoder.plutusData()
+	if nil != err {
+		return nil, err
+	}
+
+	if decoder.pos != len(decoder.data) {
+		return nil, erorr.Errorf("nftmeta: %d unexpected bytes after Plutus data", len(decoder.data)-decoder.pos)
+	}
+
+	return value, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head reads a CBOR head. For an indefinite-length item, 'indefinite' is true (and 'argument' is meaningless).
+func (receiver *cborDecoder) head() (major byte, argument uint64, indefinite bool, err error) {
+	if len(receiver.data) <= receiver.pos {
+		return 0, 0, false, errCBORUnexpectedEnd
+	}
+
+	initial := receiver.data[receiver.pos]
+	receiver.pos++
+
+	major = initial >> 5
+	info := initial & 0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), false, nil
+	case 24 == info:
+		size = 1
+	case 25 == info:
+		size = 2
+	case 26 == info:
+		size = 4
+	case 27 == info:
+		size = 8
+	case cborIndefinite == info:
+		switch major {
+		case cborMajorBytes, cborMajorText, cborMajorArray, cborMajorMap:
+			return major, 0, true, nil
+		default:
+			return 0, 0, false, erorr.Errorf("nftmeta: CBOR major-type %d cannot be indefinite-length", major)
+		}
+	default:
+		return 0, 0, false, erorr.Errorf("nftmeta: reserved CBOR additional-information %d", info)
+	}
+
+	if len(receiver.data) < receiver.pos+size {
+		return 0, 0, false, errCBORUnexpectedEnd
+	}
+	for _, b := range receiver.data[receiver.pos : receiver.pos+size] {
+		argument = argument<<8 | uint64(b)
+	}
+	receiver.pos += size
+
+	return major, argument, false, nil
+}
+
+// isBreak returns true (and consumes it) if the next byte is the CBOR "break".
+func (receiver *cborDecoder) isBreak() (bool, error) {
+	if len(receiver.data) <= receiver.pos {
+		return false, errCBORUnexpectedEnd
+	}
+
+	if cborBreak == receiver.data[receiver.pos] {
+		receiver.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (receiver *cborDecoder) bytes(argument uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if uint64(len(receiver.data)-receiver.pos) < argument {
+			return nil, errCBORUnexpectedEnd
+		}
+
+		b := append([]byte{}, receiver.data[receiver.pos:receiver.pos+int(argument)]...)
+		receiver.pos += int(argument)
+		return b, nil
+	}
+
+	var b []byte = []byte{}
+	for {
+		done, err := receiver.isBreak()
+		if nil != err {
+			return nil, err
+		}
+		if done {
+			return b, nil
+		}
+
+		major, argument, indefinite, err := receiver.head()
+		if nil != err {
+			return nil, err
+		}
+		if cborMajorBytes != major || indefinite {
+			return nil, errCBORBadChunk
+		}
+
+		chunk, err := receiver.bytes(argument, false)
+		if nil != err {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+func (receiver *cborDecoder) list(argument uint64, indefinite bool) ([]interface{}, error) {
+	var items []interface{}
+
+	for i := uint64(0); indefinite || i < argument; i++ {
+		if indefinite {
+			done, err := receiver.isBreak()
+			if nil != err {
+				return nil, err
+			}
+			if done {
+				break
+			}
+		}
+
+		item, err := receiver.plutusData()
+		if nil != err {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (receiver *cborDecoder) plutusData() (interface{}, error) {
+	major, argument, indefinite, err := receiver.head()
+	if nil != err {
+		return nil, err
+	}
+
+	switch major {
+	case cborMajorUnsigned:
+		return big.NewInt(0).SetUint64(argument), nil
+	case cborMajorNegative:
+		n := big.NewInt(0).SetUint64(argument)
+		return n.Neg(n).Sub(n, big.NewInt(1)), nil
+	case cborMajorBytes:
+		return receiver.bytes(argument, indefinite)
+	case cborMajorArray:
+		return receiver.list(argument, indefinite)
+	case cborMajorMap:
+		var m plutusMap
+		for i := uint64(0); indefinite || i < argument; i++ {
+			if indefinite {
+				done, err := receiver.isBreak()
+				if nil != err {
+					return nil, err
+				}
+				if done {
+					break
+				}
+			}
+
+			key, err := receiver.plutusData()
+			if nil != err {
+				return nil, err
+			}
+			value, err := receiver.plutusData()
+			if nil != err {
+				return nil, err
+			}
+			m = append(m, plutusMapEntry{key: key, value: value})
+		}
+		return m, nil
+	case cborMajorTag:
+		return receiver.tagged(argument)
+	default:
+		return nil, erorr.Errorf("nftmeta: CBOR major-type %d is not Plutus data", major)
+	}
+}
+
+func (receiver *cborDecoder) tagged(tag uint64) (interface{}, error) {
+	switch {
+	case 121 <= tag && tag <= 127:
+		fields, err := receiver.fields()
+		if nil != err {
+			return nil, err
+		}
+		return plutusConstr{index: tag - 121, fields: fields}, nil
+	case 1280 <= tag && tag <= 1400:
+		fields, err := receiver.fields()
+		if nil != err {
+			return nil, err
+		}
+		return plutusConstr{index: tag - 1280 + 7, fields: fields}, nil
+	case cborTagConstrGeneral == tag:
+		major, argument, indefinite, err := receiver.head()
+		if nil != err {
+			return nil, err
+		}
+		if cborMajorArray != major || indefinite || 2 != argument {
+			return nil, errPlutusBadConstr
+		}
+
+		major, index, _, err := receiver.head()
+		if nil != err {
+			return nil, err
+		}
+		if cborMajorUnsigned != major {
+			return nil, errPlutusBadConstr
+		}
+
+		fields, err := receiver.fields()
+		if nil != err {
+			return nil, err
+		}
+		return plutusConstr{index: index, fields: fields}, nil
+	case cborTagPositiveBignum == tag || cborTagNegativeBignum == tag:
+		major, argument, indefinite, err := receiver.head()
+		if nil != err {
+			return nil, err
+		}
+		if cborMajorBytes != major {
+			return nil, errCBORBadBignum
+		}
+
+		b, err := receiver.bytes(argument, indefinite)
+		if nil != err {
+			return nil, err
+		}
+
+		n := big.NewInt(0).SetBytes(b)
+		if cborTagNegativeBignum == tag {
+			n.Neg(n).Sub(n, big.NewInt(1))
+		}
+		return n, nil
+	default:
+		return nil, erorr.Errorf("nftmeta: CBOR tag %d is not Plutus data", tag)
+	}
+}
+
+// fields reads the list of fields of a Plutus constr.
+func (receiver *cborDecoder) fields() ([]interface{}, error) {
+	major, argument, indefinite, err := receiver.head()
+	if nil != err {
+		return nil, err
+	}
+	if cborMajorArray != major {
+		return nil, errPlutusBadConstr
+	}
+
+	return receiver.list(argument, indefinite)
+}
@@ -0,0 +1,561 @@
+package nftmeta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// ProvenanceAlgorithm is the hash algorithm of a Provenance.
+type ProvenanceAlgorithm int
+
+const (
+	// ProvenanceSHA256 is SHA-256.
+	ProvenanceSHA256 ProvenanceAlgorithm = iota
+
+	// ProvenanceKeccak256 is the (legacy) Keccak-256 that Ethereum uses — i.e., keccak256 in Solidity.
+	ProvenanceKeccak256
+)
+
+func (receiver ProvenanceAlgorithm) String() string {
+	switch receiver {
+	case ProvenanceSHA256:
+		return "sha256"
+	case ProvenanceKeccak256:
+		return "keccak256"
+	default:
+		return "unknown"
+	}
+}
+
+func parseProvenanceAlgorithm(str string) (ProvenanceAlgorithm, error) {
+	switch str {
+	case "sha256":
+		return ProvenanceSHA256, nil
+	case "keccak256":
+		return ProvenanceKeccak256, nil
+	default:
+		return 0, erorr.Errorf("nftmeta: unknown provenance algorithm %q", str)
+	}
+}
+
+func (receiver ProvenanceAlgorithm) sum(data []byte) ([32]byte, error) {
+	switch receiver {
+	case ProvenanceSHA256:
+		return sha256.Sum256(data), nil
+	case ProvenanceKeccak256:
+		return keccak256(data), nil
+	default:
+		return [32]byte{}, erorr.Errorf("nftmeta: unknown provenance algorithm %d", int(receiver))
+	}
+}
+
+// ProvenanceSource is what the token hashes of a Provenance are the hashes of.
+type ProvenanceSource int
+
+const (
+	// ProvenanceSourceMetaData means each token's hash is the hash of the canonical JSON (RFC 8785) of its metadata file — all of it, including any names that MetaData does not have.
+	ProvenanceSourceMetaData ProvenanceSource = iota
+
+	// ProvenanceSourceImages means each token's hash is the hash of (the bytes of) its image file — the way, for example, the Bored Ape Yacht Club provenance is.
+	ProvenanceSourceImages
+)
+
+func (receiver ProvenanceSource) String() string {
+	switch receiver {
+	case ProvenanceSourceMetaData:
+		return "metadata"
+	case ProvenanceSourceImages:
+		return "images"
+	default:
+		return "unknown"
+	}
+}
+
+func parseProvenanceSource(str string) (ProvenanceSource, error) {
+	switch str {
+	case "metadata":
+		return ProvenanceSourceMetaData, nil
+	case "images":
+		return ProvenanceSourceImages, nil
+	default:
+		return 0, erorr.Errorf("nftmeta: unknown provenance source %q", str)
+	}
+}
+
+// Provenance is the provenance of a collection — which is published before the mint, to show that the metadata (or images), and its order, was fixed beforehand.
+//
+// Each token's hash is the hash of its metadata or its image — as Source says.
+// The provenance hash is the hash of the concatenation of the (lower-case hexadecimal) token hashes, in the original order. For example, with 3 tokens:
+//
+//	Hash = H(hex(H(json₀)) + hex(H(json₁)) + hex(H(json₂)))
+type Provenance struct {
+	Algorithm   ProvenanceAlgorithm
+	Source      ProvenanceSource
+	Hash        [32]byte
+	TokenHashes [][32]byte
+}
+
+// ProvenanceOf returns the Provenance of the (metadata of the) tokens 'metadatas', which are in their original order.
+//
+// Each token's hash is of the canonical form of its MetaData.MarshalJSON — so if the files that are published have anything MetaData does not, use ProvenanceOfJSON (with the files themselves) instead.
+func ProvenanceOf(algorithm ProvenanceAlgorithm, metadatas []MetaData) (Provenance, error) {
+	var files [][]byte
+
+	for index, metadata := range metadatas {
+		data, err := metadata.MarshalJSON()
+		if nil != err {
+			return Provenance{}, erorr.Errorf("nftmeta: problem json-marshaling token #%d: %w", index, err)
+		}
+		files = append(files, data)
+	}
+
+	return ProvenanceOfJSON(algorithm, files)
+}
+
+// ProvenanceOfJSON returns the Provenance of the (JSON) metadata files 'files' of the tokens, which are in their original order.
+func ProvenanceOfJSON(algorithm ProvenanceAlgorithm, files [][]byte) (Provenance, error) {
+	return provenanceOf(algorithm, ProvenanceSourceMetaData, files)
+}
+
+// ProvenanceOfImages returns the Provenance of the image files 'images' of the tokens, which are in their original order.
+// Each token's hash is the hash of the bytes of its image, as they are.
+//
+// For example, this is how the Bored Ape Yacht Club provenance was made (with ProvenanceSHA256).
+func ProvenanceOfImages(algorithm ProvenanceAlgorithm, images [][]byte) (Provenance, error) {
+	return provenanceOf(algorithm, ProvenanceSourceImages, images)
+}
+
+func provenanceOf(algorithm ProvenanceAlgorithm, source ProvenanceSource, files [][]byte) (Provenance, error) {
+	var provenance = Provenance{Algorithm: algorithm, Source: source}
+
+	for index, data := range files {
+		tokenHash, err := provenance.tokenHash(data)
+		if nil != err {
+			return Provenance{}, erorr.Errorf("nftmeta: problem hashing token #%d: %w", index, err)
+		}
+		provenance.TokenHashes = append(provenance.TokenHashes, tokenHash)
+	}
+
+	hash, err := provenanceHash(algorithm, provenance.TokenHashes)
+	if nil != err {
+		return Provenance{}, err
+	}
+	provenance.Hash = hash
+
+	return provenance, nil
+}
+
+// tokenHash returns the hash of the token file 'data' — which, for metadata, is the hash of its canonical form.
+func (receiver Provenance) tokenHash(data []byte) ([32]byte, error) {
+	switch receiver.Source {
+	case ProvenanceSourceMetaData:
+		canonical, err := canonicalJSON(data)
+		if nil != err {
+			return [32]byte{}, err
+		}
+		return receiver.Algorithm.sum(canonical)
+	case ProvenanceSourceImages:
+		return receiver.Algorithm.sum(data)
+	default:
+		return [32]byte{}, erorr.Errorf("nftmeta: unknown provenance source %d", int(receiver.Source))
+	}
+}
+
+func provenanceHash(algorithm ProvenanceAlgorithm, tokenHashes [][32]byte) ([32]byte, error) {
+	var concatenated = make([]byte, 0, len(tokenHashes)*64)
+	for _, tokenHash := range tokenHashes {
+		concatenated = hex.AppendEncode(concatenated, tokenHash[:])
+	}
+
+	return algorithm.sum(concatenated)
+}
+
+// String returns the provenance hash, in lower-case hexadecimal.
+func (receiver Provenance) String() string {
+	return hex.EncodeToString(receiver.Hash[:])
+}
+
+// provenanceJSON is the JSON form of a Provenance. For example:
+//
+//	{"algorithm":"sha256","source":"metadata","provenance":"…","tokens":["…","…","…"]}
+type provenanceJSON struct {
+	Algorithm  string   `json:"algorithm"`
+	Source     string   `json:"source"`
+	Provenance string   `json:"provenance"`
+	Tokens     []string `json:"tokens"`
+}
+
+func (receiver Provenance) MarshalJSON() ([]byte, error) {
+	var value = provenanceJSON{
+		Algorithm:  receiver.Algorithm.String(),
+		Source:     receiver.Source.String(),
+		Provenance: receiver.String(),
+		Tokens:     []string{},
+	}
+	for _, tokenHash := range receiver.TokenHashes {
+		value.Tokens = append(value.Tokens, hex.EncodeToString(tokenHash[:]))
+	}
+
+	return json.Marshal(value)
+}
+
+func (receiver *Provenance) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var value provenanceJSON
+	if err := json.Unmarshal(data, &value); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling provenance: %w", err)
+	}
+
+	algorithm, err := parseProvenanceAlgorithm(value.Algorithm)
+	if nil != err {
+		return err
+	}
+
+	source, err := parseProvenanceSource(value.Source)
+	if nil != err {
+		return err
+	}
+
+	hash, err := decodeHash32(value.Provenance)
+	if nil != err {
+		return erorr.Errorf("nftmeta: bad provenance hash: %w", err)
+	}
+
+	var tokenHashes [][32]byte
+	for index, str := range value.Tokens {
+		tokenHash, err := decodeHash32(str)
+		if nil != err {
+			return erorr.Errorf("nftmeta: bad hash of token #%d: %w", index, err)
+		}
+		tokenHashes = append(tokenHashes, tokenHash)
+	}
+
+	*receiver = Provenance{Algorithm: algorithm, Source: source, Hash: hash, TokenHashes: tokenHashes}
+	return nil
+}
+
+func decodeHash32(str string) ([32]byte, error) {
+	var hash [32]byte
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if nil != err {
+		return hash, err
+	}
+	if len(hash) != len(decoded) {
+		return hash, erorr.Errorf("nftmeta: hash is %d bytes rather than %d bytes", len(decoded), len(hash))
+	}
+
+	copy(hash[:], decoded)
+	return hash, nil
+}
+
+// Verify recomputes the provenance from the published folder 'folder' — in which the file of each token (whose token-ids start at 'firstTokenID', in the original order) is named according to 'naming' — and reports each token whose metadata (or image) does not match.
+//
+// For example:
+//
+//	report, err := provenance.Verify(os.DirFS("metadata"), nftmeta.TokenFileNamingDecimalJSON, big.NewInt(1))
+//
+// For a provenance of images, the file of each token can also have an extension — for example, "1.png" (with TokenFileNamingDecimal):
+//
+//	report, err := provenance.Verify(os.DirFS("images"), nftmeta.TokenFileNamingDecimal, big.NewInt(1))
+//
+// It only returns an error if the provenance itself is inconsistent (i.e., its Hash is not the hash of its TokenHashes) or cannot be used.
+func (receiver Provenance) Verify(folder fs.FS, naming TokenFileNaming, firstTokenID *big.Int) (ProvenanceReport, error) {
+	if nil == folder {
+		return ProvenanceReport{}, errNilFolder
+	}
+	if nil == firstTokenID {
+		firstTokenID = new(big.Int)
+	}
+
+	if hash, err := provenanceHash(receiver.Algorithm, receiver.TokenHashes); nil != err {
+		return ProvenanceReport{}, err
+	} else if hash != receiver.Hash {
+		return ProvenanceReport{}, erorr.Errorf("nftmeta: provenance hash %s is not the hash of its token hashes, which is %s", receiver.String(), hex.EncodeToString(hash[:]))
+	}
+
+	var report = ProvenanceReport{Algorithm: receiver.Algorithm, Expected: receiver.Hash}
+
+	dirEntries, dirErr := fs.ReadDir(folder, ".")
+
+	// The names of the image files, by their names without their extensions.
+	var imageFileNames = map[string][]string{}
+	if ProvenanceSourceImages == receiver.Source && nil == dirErr {
+		for _, dirEntry := range dirEntries {
+			if dirEntry.IsDir() {
+				continue
+			}
+			stem := provenanceFileStem(dirEntry.Name())
+			imageFileNames[stem] = append(imageFileNames[stem], dirEntry.Name())
+		}
+	}
+
+	var expectedFileNames = map[string]struct{}{}
+	var actualTokenHashes [][32]byte
+	var complete = true
+	for index, expected := range receiver.TokenHashes {
+		tokenID := new(big.Int).Add(firstTokenID, big.NewInt(int64(index)))
+
+		fileName, err := naming.FileName(tokenID)
+		if nil != err {
+			return ProvenanceReport{}, err
+		}
+		expectedFileNames[fileName] = struct{}{}
+
+		mismatch := ProvenanceMismatch{Index: index, TokenID: tokenID, FileName: fileName, Expected: expected}
+
+		if candidates := imageFileNames[fileName]; 1 == len(candidates) {
+			fileName = candidates[0]
+			mismatch.FileName = fileName
+			expectedFileNames[fileName] = struct{}{}
+		} else if 1 < len(candidates) {
+			for _, candidate := range candidates {
+				expectedFileNames[candidate] = struct{}{}
+			}
+			complete = false
+			mismatch.Kind = ProvenanceUnreadable
+			mismatch.Reason = "more than one file: " + strings.Join(candidates, ", ")
+			report.mismatches = append(report.mismatches, mismatch)
+			continue
+		}
+
+		data, err := fs.ReadFile(folder, fileName)
+		if nil != err {
+			complete = false
+			mismatch.Kind = ProvenanceMissing
+			if !errors.Is(err, fs.ErrNotExist) {
+				mismatch.Kind = ProvenanceUnreadable
+				mismatch.Reason = err.Error()
+			}
+			report.mismatches = append(report.mismatches, mismatch)
+			continue
+		}
+
+		actual, err := receiver.tokenHash(data)
+		if nil != err {
+			complete = false
+			mismatch.Kind = ProvenanceUnreadable
+			mismatch.Reason = err.Error()
+			report.mismatches = append(report.mismatches, mismatch)
+			continue
+		}
+		actualTokenHashes = append(actualTokenHashes, actual)
+
+		if actual != expected {
+			mismatch.Kind = ProvenanceChanged
+			mismatch.Actual = actual
+			report.mismatches = append(report.mismatches, mismatch)
+		}
+	}
+
+	// Token files in the folder that the provenance does not have.
+	if nil == dirErr {
+		var extras []ProvenanceMismatch
+		for _, dirEntry := range dirEntries {
+			if dirEntry.IsDir() {
+				continue
+			}
+			if _, found := expectedFileNames[dirEntry.Name()]; found {
+				continue
+			}
+
+			name := dirEntry.Name()
+			if ProvenanceSourceImages == receiver.Source {
+				name = provenanceFileStem(name)
+			}
+
+			tokenID, ok := tokenIDFromFileName(naming, name)
+			if !ok {
+				continue
+			}
+
+			extras = append(extras, ProvenanceMismatch{Kind: ProvenanceExtra, Index: -1, TokenID: tokenID, FileName: dirEntry.Name()})
+		}
+		sort.Slice(extras, func(i, j int) bool {
+			return extras[i].TokenID.Cmp(extras[j].TokenID) < 0
+		})
+		report.mismatches = append(report.mismatches, extras...)
+	}
+
+	if complete {
+		actual, err := provenanceHash(receiver.Algorithm, actualTokenHashes)
+		if nil != err {
+			return ProvenanceReport{}, err
+		}
+		report.Actual = actual
+		report.Recomputed = true
+	}
+
+	return report, nil
+}
+
+// provenanceFileStem returns the file name 'name' without its extension — for example, "1" for "1.png".
+func provenanceFileStem(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// tokenIDFromFileName returns the token-id whose file (named according to 'naming') is named 'name' — or false if no token's file would be named that.
+func tokenIDFromFileName(naming TokenFileNaming, name string) (*big.Int, bool) {
+	str := name
+	switch naming {
+	case TokenFileNamingDecimalJSON, TokenFileNamingHexJSON:
+		var found bool
+		str, found = strings.CutSuffix(name, ".json")
+		if !found {
+			return nil, false
+		}
+	}
+
+	parse := ParseTokenID
+	switch naming {
+	case TokenFileNamingHex, TokenFileNamingHexJSON:
+		parse = ParseERC1155TokenID
+	}
+
+	tokenID, err := parse(str)
+	if nil != err {
+		return nil, false
+	}
+
+	// This rules out, for example, "007" (for TokenFileNamingDecimal) and "0x7".
+	if fileName, err := naming.FileName(tokenID); nil != err || name != fileName {
+		return nil, false
+	}
+	return tokenID, true
+}
+
+// ProvenanceMismatchKind says how a token does not match a Provenance.
+type ProvenanceMismatchKind int
+
+const (
+	// ProvenanceChanged means the token's metadata (or image) is not what it was.
+	ProvenanceChanged ProvenanceMismatchKind = iota
+
+	// ProvenanceMissing means the token's file is missing.
+	ProvenanceMissing
+
+	// ProvenanceUnreadable means the token's file could not be read, or (for metadata) is not JSON, or (for images) there is more than one file for the token.
+	ProvenanceUnreadable
+
+	// ProvenanceExtra means there is a file for a token that the provenance does not have.
+	ProvenanceExtra
+)
+
+func (receiver ProvenanceMismatchKind) String() string {
+	switch receiver {
+	case ProvenanceChanged:
+		return "changed"
+	case ProvenanceMissing:
+		return "missing"
+	case ProvenanceUnreadable:
+		return "unreadable"
+	case ProvenanceExtra:
+		return "extra"
+	default:
+		return "unknown"
+	}
+}
+
+// ProvenanceMismatch is a single entry of a ProvenanceReport — a token that does not match.
+//
+// Index is the token's position in the original order (or -1 for a ProvenanceExtra).
+// Expected is the token's hash in the Provenance, and Actual is the hash of what is in the folder (which is only set for a ProvenanceChanged).
+type ProvenanceMismatch struct {
+	Kind     ProvenanceMismatchKind
+	Index    int
+	TokenID  *big.Int
+	FileName string
+	Expected [32]byte
+	Actual   [32]byte
+	Reason   string
+}
+
+func (receiver ProvenanceMismatch) String() string {
+	var builder strings.Builder
+
+	builder.WriteString("token ")
+	builder.WriteString(receiver.TokenID.String())
+	builder.WriteString(" (")
+	builder.WriteString(receiver.FileName)
+	builder.WriteString("): ")
+	builder.WriteString(receiver.Kind.String())
+
+	switch receiver.Kind {
+	case ProvenanceChanged:
+		builder.WriteString(": expected hash ")
+		builder.WriteString(hex.EncodeToString(receiver.Expected[:]))
+		builder.WriteString(" but it is ")
+		builder.WriteString(hex.EncodeToString(receiver.Actual[:]))
+	case ProvenanceExtra:
+		builder.WriteString(": not in the provenance")
+	}
+	if "" != receiver.Reason {
+		builder.WriteString(": ")
+		builder.WriteString(receiver.Reason)
+	}
+
+	return builder.String()
+}
+
+// ProvenanceReport is what Provenance.Verify found.
+//
+// Expected is the (published) provenance hash.
+// Actual is the provenance hash recomputed from the folder — which is only set (and Recomputed is only true) if every token's file could be read.
+type ProvenanceReport struct {
+	Algorithm  ProvenanceAlgorithm
+	Expected   [32]byte
+	Actual     [32]byte
+	Recomputed bool
+	mismatches []ProvenanceMismatch
+}
+
+// Mismatches returns (a copy of) the tokens that do not match — in the original order, followed by any extra tokens.
+func (receiver ProvenanceReport) Mismatches() []ProvenanceMismatch {
+	if len(receiver.mismatches) <= 0 {
+		return nil
+	}
+
+	mismatches := make([]ProvenanceMismatch, len(receiver.mismatches))
+	copy(mismatches, receiver.mismatches)
+	return mismatches
+}
+
+// Verified returns true if every token matches, and so the recomputed provenance hash is the published one.
+func (receiver ProvenanceReport) Verified() bool {
+	return receiver.Recomputed && receiver.Expected == receiver.Actual && len(receiver.mismatches) <= 0
+}
+
+func (receiver ProvenanceReport) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(receiver.Algorithm.String())
+	builder.WriteString(" provenance ")
+	builder.WriteString(hex.EncodeToString(receiver.Expected[:]))
+	switch {
+	case receiver.Verified():
+		builder.WriteString(": verified")
+	case receiver.Recomputed:
+		builder.WriteString(": not verified: recomputed as ")
+		builder.WriteString(hex.EncodeToString(receiver.Actual[:]))
+	default:
+		builder.WriteString(": not verified: cannot be recomputed")
+	}
+	for _, mismatch := range receiver.mismatches {
+		builder.WriteString("\n\t")
+		builder.WriteString(mismatch.String())
+	}
+
+	return builder.String()
+}
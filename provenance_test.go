@@ -0,0 +1,508 @@
+package nftmeta_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func provenanceTestMetaDatas(t *testing.T) []nftmeta.MetaData {
+	var metadatas []nftmeta.MetaData
+	for _, str := range []string{
+		`{"name":"Token #0"}`,
+		`{"name":"Token #1","image":"ipfs://x/1.png"}`,
+		`{"name":"Token #2"}`,
+	} {
+		var metadata nftmeta.MetaData
+		if err := metadata.UnmarshalJSON([]byte(str)); nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+		metadatas = append(metadatas, metadata)
+	}
+	return metadatas
+}
+
+func TestProvenanceOf(t *testing.T) {
+
+	provenance, err := nftmeta.ProvenanceOf(nftmeta.ProvenanceSHA256, provenanceTestMetaDatas(t))
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	// These are sha256sum of each token's canonical JSON, and then sha256sum of the concatenation of those (in hexadecimal).
+	expectedTokenHashes := []string{
+		"65e5cee69625a009b57670c810d5824277814dca5cfd1e80bba24f06619a4b4e",
+		"99dda09475010ac8409da037783a607d202915b937e79358ba44c1ec560a38dc",
+		"55c408f5ff974005cfbfb19d8aa0e2bd41d96469064fe03fccbcb1aa63290404",
+	}
+
+	var actualTokenHashes []string
+	for _, tokenHash := range provenance.TokenHashes {
+		actualTokenHashes = append(actualTokenHashes, hex.EncodeToString(tokenHash[:]))
+	}
+
+	if expected, actual := expectedTokenHashes, actualTokenHashes; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("The actual token hashes are not what was expected.")
+		t.Logf("EXPECTED: %v", expected)
+		t.Logf("ACTUAL:   %v", actual)
+	}
+
+	if expected, actual := "2fecc981d021bf27aadc95ec49b31f9bb3f17027bdceb03c03a22fbdd58c43f1", provenance.String(); expected != actual {
+		t.Errorf("The actual provenance hash is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+}
+
+func TestProvenanceOfJSON(t *testing.T) {
+
+	files := [][]byte{
+		[]byte(`{"name":"Token #0"}`),
+		[]byte("{\n  \"rarity\": \"legendary\",\n  \"name\": \"Token #1\"\n}\n"),
+	}
+
+	provenance, err := nftmeta.ProvenanceOfJSON(nftmeta.ProvenanceSHA256, files)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	// These are sha256sum of `{"name":"Token #0"}` and of `{"name":"Token #1","rarity":"legendary"}` — the second file, canonicalized, with the "rarity" that MetaData does not have.
+	expectedTokenHashes := []string{
+		"65e5cee69625a009b57670c810d5824277814dca5cfd1e80bba24f06619a4b4e",
+		"d858168f2bdd1068c9b3224b46b58b1f2ce04efb238de9ce83a703c4e80f5ca5",
+	}
+
+	var actualTokenHashes []string
+	for _, tokenHash := range provenance.TokenHashes {
+		actualTokenHashes = append(actualTokenHashes, hex.EncodeToString(tokenHash[:]))
+	}
+
+	if expected, actual := expectedTokenHashes, actualTokenHashes; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("The actual token hashes are not what was expected.")
+		t.Logf("EXPECTED: %v", expected)
+		t.Logf("ACTUAL:   %v", actual)
+	}
+}
+
+func TestProvenanceOfJSON_bad(t *testing.T) {
+
+	tests := []struct{
+		File []byte
+	}{
+		{
+			// Duplicate names — which readers that keep the first would read differently than readers that keep the last.
+			File: []byte(`{"name":"a","name":"b"}`),
+		},
+		{
+			File: []byte(`{"name":"a","\u006eame":"b"}`),
+		},
+		{
+			File: []byte(`{"name":"a","attributes":[{"trait_type":"x","value":1,"value":2}]}`),
+		},
+		{
+			// Invalid UTF-8.
+			File: []byte("{\"name\":\"\xff\"}"),
+		},
+		{
+			// Unpaired surrogate escapes.
+			File: []byte(`{"name":"\ud800"}`),
+		},
+		{
+			File: []byte(`{"name":"\udc00"}`),
+		},
+		{
+			File: []byte(`{"name":"\ud800\u0041"}`),
+		},
+		{
+			File: []byte(`{"name":"\udc00\ud800"}`),
+		},
+		{
+			File: []byte(`{"\ud800":"a"}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := nftmeta.ProvenanceOfJSON(nftmeta.ProvenanceSHA256, [][]byte{test.File})
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("FILE: %q", test.File)
+			continue
+		}
+	}
+}
+
+func TestProvenanceOfJSON_strings(t *testing.T) {
+
+	tests := []struct{
+		File     []byte
+		Expected string
+	}{
+		{
+			File:     []byte(`{"name":"\ud83d\ude00"}`),
+			Expected: `{"name":"😀"}`,
+		},
+		{
+			File:     []byte(`{"name":"\uD83D\uDE00"}`),
+			Expected: `{"name":"😀"}`,
+		},
+		{
+			File:     []byte(`{"name":"�"}`),
+			Expected: `{"name":"�"}`,
+		},
+		{
+			// An escaped backslash, followed by the text "ud800" — which is not a surrogate escape.
+			File:     []byte(`{"name":"\\ud800"}`),
+			Expected: `{"name":"\\ud800"}`,
+		},
+		{
+			// An escaped quotation mark does not end the string.
+			File:     []byte(`{"name":"\"\ud83d\ude00"}`),
+			Expected: `{"name":"\"😀"}`,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		provenance, err := nftmeta.ProvenanceOfJSON(nftmeta.ProvenanceSHA256, [][]byte{test.File})
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("FILE: %q", test.File)
+			continue
+		}
+
+		expected := sha256.Sum256([]byte(test.Expected))
+
+		if actual := provenance.TokenHashes[0]; expected != actual {
+			t.Errorf("For test #%d, the actual token hash is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %x", expected)
+			t.Logf("ACTUAL:   %x", actual)
+			t.Logf("FILE: %q", test.File)
+			continue
+		}
+	}
+}
+
+func TestProvenance_JSON(t *testing.T) {
+
+	tests := []struct{
+		Algorithm nftmeta.ProvenanceAlgorithm
+		Source    nftmeta.ProvenanceSource
+	}{
+		{
+			Algorithm: nftmeta.ProvenanceSHA256,
+			Source:    nftmeta.ProvenanceSourceMetaData,
+		},
+		{
+			Algorithm: nftmeta.ProvenanceKeccak256,
+			Source:    nftmeta.ProvenanceSourceMetaData,
+		},
+		{
+			Algorithm: nftmeta.ProvenanceKeccak256,
+			Source:    nftmeta.ProvenanceSourceImages,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var provenance nftmeta.Provenance
+		var err error
+		switch test.Source {
+		case nftmeta.ProvenanceSourceImages:
+			provenance, err = nftmeta.ProvenanceOfImages(test.Algorithm, [][]byte{[]byte("\x89PNG one")})
+		default:
+			provenance, err = nftmeta.ProvenanceOf(test.Algorithm, provenanceTestMetaDatas(t))
+		}
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		data, err := provenance.MarshalJSON()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		var actual nftmeta.Provenance
+		if err := actual.UnmarshalJSON(data); nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("JSON: %s", data)
+			continue
+		}
+
+		if expected := provenance; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual provenance is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %#v", expected)
+			t.Logf("ACTUAL:   %#v", actual)
+			t.Logf("JSON: %s", data)
+			continue
+		}
+	}
+}
+
+func TestProvenance_Verify(t *testing.T) {
+
+	provenance, err := nftmeta.ProvenanceOf(nftmeta.ProvenanceKeccak256, provenanceTestMetaDatas(t))
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	tests := []struct{
+		Folder             fstest.MapFS
+		ExpectedVerified   bool
+		ExpectedRecomputed bool
+		ExpectedKinds      []nftmeta.ProvenanceMismatchKind
+		ExpectedTokenIDs   []string
+	}{
+		{
+			// Whitespace and the order of the names do not matter, since the JSON is canonicalized.
+			Folder: fstest.MapFS{
+				"1.json":    {Data: []byte(`{"name":"Token #0"}`)},
+				"2.json":    {Data: []byte("{\n  \"image\": \"ipfs://x/1.png\",\n  \"name\": \"Token #1\"\n}\n")},
+				"3.json":    {Data: []byte(`{"name":"Token #2"}`)},
+				"README.md": {Data: []byte(`not a token`)},
+			},
+			ExpectedVerified:   true,
+			ExpectedRecomputed: true,
+		},
+		{
+			Folder: fstest.MapFS{
+				"1.json": {Data: []byte(`{"name":"Token #0"}`)},
+				"2.json": {Data: []byte(`{"name":"Token #1","image":"ipfs://y/1.png"}`)},
+				"3.json": {Data: []byte(`{"name":"Token #2"}`)},
+			},
+			ExpectedVerified:   false,
+			ExpectedRecomputed: true,
+			ExpectedKinds:      []nftmeta.ProvenanceMismatchKind{nftmeta.ProvenanceChanged},
+			ExpectedTokenIDs:   []string{"2"},
+		},
+		{
+			// A name that MetaData does not have is still part of what is hashed.
+			Folder: fstest.MapFS{
+				"1.json": {Data: []byte(`{"name":"Token #0"}`)},
+				"2.json": {Data: []byte(`{"name":"Token #1","image":"ipfs://x/1.png"}`)},
+				"3.json": {Data: []byte(`{"name":"Token #2","rarity":"legendary"}`)},
+			},
+			ExpectedVerified:   false,
+			ExpectedRecomputed: true,
+			ExpectedKinds:      []nftmeta.ProvenanceMismatchKind{nftmeta.ProvenanceChanged},
+			ExpectedTokenIDs:   []string{"3"},
+		},
+		{
+			Folder: fstest.MapFS{
+				"1.json": {Data: []byte(`{"name":"Token #0"} {"name":"Token #0"}`)},
+				"2.json": {Data: []byte(`{"name":"Token #1","image":"ipfs://x/1.png"}`)},
+				"3.json": {Data: []byte(`{"name":"Token #2"}`)},
+			},
+			ExpectedVerified:   false,
+			ExpectedRecomputed: false,
+			ExpectedKinds:      []nftmeta.ProvenanceMismatchKind{nftmeta.ProvenanceUnreadable},
+			ExpectedTokenIDs:   []string{"1"},
+		},
+		{
+			Folder: fstest.MapFS{
+				"2.json": {Data: []byte(`{"name":"Token #1","image":"ipfs://x/1.png"}`)},
+				"3.json": {Data: []byte(`{"name":`)},
+				"4.json": {Data: []byte(`{"name":"Token #3"}`)},
+			},
+			ExpectedVerified:   false,
+			ExpectedRecomputed: false,
+			ExpectedKinds:      []nftmeta.ProvenanceMismatchKind{nftmeta.ProvenanceMissing, nftmeta.ProvenanceUnreadable, nftmeta.ProvenanceExtra},
+			ExpectedTokenIDs:   []string{"1", "3", "4"},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		report, err := provenance.Verify(test.Folder, nftmeta.TokenFileNamingDecimalJSON, big.NewInt(1))
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.ExpectedVerified, report.Verified(); expected != actual {
+			t.Errorf("For test #%d, whether it was verified is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		if expected, actual := test.ExpectedRecomputed, report.Recomputed; expected != actual {
+			t.Errorf("For test #%d, whether it was recomputed is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		var actualKinds []nftmeta.ProvenanceMismatchKind
+		var actualTokenIDs []string
+		for _, mismatch := range report.Mismatches() {
+			actualKinds = append(actualKinds, mismatch.Kind)
+			actualTokenIDs = append(actualTokenIDs, mismatch.TokenID.String())
+		}
+
+		if expected, actual := test.ExpectedKinds, actualKinds; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual kinds of mismatches are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %v", expected)
+			t.Logf("ACTUAL:   %v", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		if expected, actual := test.ExpectedTokenIDs, actualTokenIDs; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual token-ids of mismatches are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %v", expected)
+			t.Logf("ACTUAL:   %v", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+	}
+}
+
+func TestProvenance_Verify_inconsistent(t *testing.T) {
+
+	provenance, err := nftmeta.ProvenanceOf(nftmeta.ProvenanceSHA256, provenanceTestMetaDatas(t))
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	provenance.TokenHashes[1][0] ^= 0xFF
+
+	if _, err := provenance.Verify(fstest.MapFS{}, nftmeta.TokenFileNamingDecimalJSON, big.NewInt(1)); nil == err {
+		t.Errorf("Expected an error but did not actually get one.")
+	}
+}
+
+func TestProvenanceOfImages(t *testing.T) {
+
+	images := [][]byte{
+		[]byte("\x89PNG one"),
+		[]byte("GIF89a two"),
+		[]byte("\x89PNG three"),
+	}
+
+	provenance, err := nftmeta.ProvenanceOfImages(nftmeta.ProvenanceSHA256, images)
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	// These are sha256sum of each image's bytes (as they are), and then sha256sum of the concatenation of those (in hexadecimal).
+	expectedTokenHashes := []string{
+		"0f910e785fbcc9a739ddc7dce0deb2b4c15bb889c25613a6658e58973ec84af0",
+		"093ef2fe0ac806f2326deeaca5042863df1d2f6b03278d63424e603906b7a0a8",
+		"e9197be9164e026f0d78e97ddabee8daeba385beab002c6718639412bc2832ce",
+	}
+
+	var actualTokenHashes []string
+	for _, tokenHash := range provenance.TokenHashes {
+		actualTokenHashes = append(actualTokenHashes, hex.EncodeToString(tokenHash[:]))
+	}
+
+	if expected, actual := expectedTokenHashes, actualTokenHashes; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("The actual token hashes are not what was expected.")
+		t.Logf("EXPECTED: %v", expected)
+		t.Logf("ACTUAL:   %v", actual)
+	}
+
+	if expected, actual := "8e1c13c20c95b5b5e8205fa95f15533cedda33714b7b2e983f844dd92d358440", provenance.String(); expected != actual {
+		t.Errorf("The actual provenance hash is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+
+	tests := []struct{
+		Folder            fstest.MapFS
+		ExpectedVerified  bool
+		ExpectedKinds     []nftmeta.ProvenanceMismatchKind
+		ExpectedTokenIDs  []string
+		ExpectedFileNames []string
+	}{
+		{
+			// The image files can have any extension (or none).
+			Folder: fstest.MapFS{
+				"0.png":     {Data: images[0]},
+				"1.gif":     {Data: images[1]},
+				"2":         {Data: images[2]},
+				"README.md": {Data: []byte(`not a token`)},
+			},
+			ExpectedVerified: true,
+		},
+		{
+			Folder: fstest.MapFS{
+				"0.png": {Data: images[0]},
+				"1.png": {Data: images[1]},
+				"1.gif": {Data: images[1]},
+				"2.png": {Data: []byte("\x89PNG something else")},
+				"3.png": {Data: images[2]},
+			},
+			ExpectedVerified:  false,
+			ExpectedKinds:     []nftmeta.ProvenanceMismatchKind{nftmeta.ProvenanceUnreadable, nftmeta.ProvenanceChanged, nftmeta.ProvenanceExtra},
+			ExpectedTokenIDs:  []string{"1", "2", "3"},
+			ExpectedFileNames: []string{"1", "2.png", "3.png"},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		report, err := provenance.Verify(test.Folder, nftmeta.TokenFileNamingDecimal, big.NewInt(0))
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.ExpectedVerified, report.Verified(); expected != actual {
+			t.Errorf("For test #%d, whether it was verified is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		var actualKinds []nftmeta.ProvenanceMismatchKind
+		var actualTokenIDs []string
+		var actualFileNames []string
+		for _, mismatch := range report.Mismatches() {
+			actualKinds = append(actualKinds, mismatch.Kind)
+			actualTokenIDs = append(actualTokenIDs, mismatch.TokenID.String())
+			actualFileNames = append(actualFileNames, mismatch.FileName)
+		}
+
+		if expected, actual := test.ExpectedKinds, actualKinds; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual kinds of mismatches are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %v", expected)
+			t.Logf("ACTUAL:   %v", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		if expected, actual := test.ExpectedTokenIDs, actualTokenIDs; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual token-ids of mismatches are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %v", expected)
+			t.Logf("ACTUAL:   %v", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+
+		if expected, actual := test.ExpectedFileNames, actualFileNames; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("For test #%d, the actual file names of mismatches are not what was expected.", testNumber)
+			t.Logf("EXPECTED: %v", expected)
+			t.Logf("ACTUAL:   %v", actual)
+			t.Logf("REPORT: %s", report)
+			continue
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package nftmeta
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// These are what a Resolver uses when its field is zero.
+const (
+	defaultResolverTimeout      = 30 * time.Second
+	defaultResolverMaxBodySize  = 10 << 20
+	defaultResolverMaxRedirects = 5
+)
+
+// Resolver fetches (and parses) what a tokenURI points to.
+//
+// It dispatches on the kind of URI (see ParseURI):
+//
+// • http and https URLs are fetched with HTTPClient;
+//
+// • IPFS URIs (including IPFS gateway URLs) are fetched through each of IPFSGateways, in order, until one of them works;
+//
+// • Arweave URIs are fetched through ArweaveGateway;
+//
+// • data URIs are decoded from the URI itself.
+//
+// The zero value of Resolver can fetch http, https, and data URIs. (IPFS and Arweave URIs need gateways.)
+//
+// With a Cache, IPFS content that is cached (which is only the content of a whole file, checked against its CID) is not fetched again, and http and https content that is cached is revalidated with a conditional request.
+// (See DiskCache.)
+type Resolver struct {
+	// HTTPClient is used for every HTTP(S) request. If it is nil, a client with http.DefaultTransport is used.
+	// (Its CheckRedirect is replaced, to enforce MaxRedirects.)
+	HTTPClient *http.Client
+
+	// IPFSGateways are the base URLs of the IPFS (path) gateways to try, in order — for example, "https://ipfs.io".
+	IPFSGateways []string
+
+	// ArweaveGateway is the base URL of the Arweave gateway — for example, "https://arweave.net".
+	ArweaveGateway string
+
+	// Timeout is the timeout of each HTTP(S) request. (A fallback to another IPFS gateway gets its own timeout.)
+	// If it is 0, then 30 seconds is used.
+	Timeout time.Duration
+
+	// MaxBodySize is the largest (response) body that is accepted, in bytes.
+	// If it is 0, then 10 MiB is used.
+	MaxBodySize int64
+
+	// MaxRedirects is the most redirects that are followed for each HTTP(S) request.
+	// If it is 0, then 5 is used. If it is negative, then no redirects are followed.
+	MaxRedirects int
+
+	// Cache, if it is not nil, caches what is fetched. (See DiskCache.)
+	Cache *DiskCache
+}
+
+// HTTPStatusError is the error (possibly wrapped) that a Resolver returns when an HTTP(S) request gets a response with a status code other than 200 OK.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+
+	// RetryAfter is from the Retry-After header (if the response had one, in seconds), or else 0.
+	RetryAfter time.Duration
+}
+
+func (receiver HTTPStatusError) Error() string {
+	return "nftmeta: HTTP request for " + strconv.Quote(receiver.URL) + " got status " + strconv.Itoa(receiver.StatusCode) + " " + http.StatusText(receiver.StatusCode)
+}
+
+// Resolve fetches what 'tokenURI' points to (see Fetch), and json-unmarshals it into MetaData.
+func (receiver Resolver) Resolve(ctx context.Context, tokenURI string) (MetaData, error) {
+	data, err := receiver.Fetch(ctx, tokenURI)
+	if nil != err {
+		return MetaData{}, err
+	}
+
+	var metadata MetaData
+	if err := metadata.UnmarshalJSON(data); nil != err {
+		return MetaData{}, erorr.Errorf("nftmeta: problem parsing metadata from %q: %w", truncateForError(tokenURI), err)
+	}
+
+	return metadata, nil
+}
+
+// Fetch returns the content that 'tokenURI' points to.
+func (receiver Resolver) Fetch(ctx context.Context, tokenURI string) ([]byte, error) {
+	uri, err := ParseURI(tokenURI)
+	if nil != err {
+		return nil, err
+	}
+
+	switch uri.Kind() {
+	case URIKindData:
+		data, err := uri.Data()
+		if nil != err {
+			return nil, err
+		}
+		if receiver.maxBodySize() < int64(len(data)) {
+			return nil, erorr.Errorf("nftmeta: data URI is bigger than %d bytes", receiver.maxBodySize())
+		}
+		return data, nil
+	case URIKindIPFS, URIKindArweave:
+		// IPFS content is immutable, so what is cached (which is checked against its CID) never needs revalidating.
+		if data, found := receiver.Cache.loadImmutable(uri); found {
+			return data, nil
+		}
+
+		var data []byte
+		var err error
+		if URIKindIPFS == uri.Kind() {
+			data, err = receiver.fetchIPFS(ctx, uri, tokenURI)
+		} else {
+			data, err = receiver.fetchArweave(ctx, uri)
+		}
+		if nil != err {
+			return nil, err
+		}
+
+		receiver.Cache.storeImmutable(uri, data)
+		return data, nil
+	default:
+		scheme, _, _ := strings.Cut(uri.String(), ":")
+		if !isHTTPScheme(scheme) {
+			return nil, erorr.Errorf("nftmeta: cannot resolve %q URIs", scheme)
+		}
+		return receiver.fetchHTTPCached(ctx, uri.String())
+	}
+}
+
+// fetchIPFS fetches the IPFS content 'uri' through each of the IPFS gateways, in order, until one of them works.
+func (receiver Resolver) fetchIPFS(ctx context.Context, uri URI, tokenURI string) ([]byte, error) {
+	if len(receiver.IPFSGateways) <= 0 {
+		// An IPFS gateway URL can still be fetched as it is.
+		if scheme, _, _ := strings.Cut(tokenURI, ":"); isHTTPScheme(scheme) {
+			return receiver.fetchHTTP(ctx, strings.TrimSpace(tokenURI))
+		}
+		return nil, errResolverNoIPFSGateway
+	}
+
+	var errs []error
+	for _, gateway := range receiver.IPFSGateways {
+		url, err := uri.Rewrite(Gateway{IPFS: gateway})
+		if nil != err {
+			errs = append(errs, err)
+			continue
+		}
+
+		data, err := receiver.fetchHTTP(ctx, url)
+		if nil == err {
+			return data, nil
+		}
+		errs = append(errs, err)
+
+		// There is no point in falling back, if the caller has given up.
+		if nil != ctx.Err() {
+			break
+		}
+	}
+	return nil, erorr.Errorf("nftmeta: every IPFS gateway failed for %q: %w", uri.String(), errors.Join(errs...))
+}
+
+// fetchArweave fetches the Arweave content 'uri' through the Arweave gateway.
+func (receiver Resolver) fetchArweave(ctx context.Context, uri URI) ([]byte, error) {
+	if "" == receiver.ArweaveGateway {
+		return nil, errResolverNoArweaveGateway
+	}
+
+	url, err := uri.Rewrite(Gateway{Arweave: receiver.ArweaveGateway})
+	if nil != err {
+		return nil, err
+	}
+	return receiver.fetchHTTP(ctx, url)
+}
+
+func isHTTPScheme(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "http", "https":
+		return true
+	default:
+		return false
+	}
+}
+
+func (receiver Resolver) maxBodySize() int64 {
+	if receiver.MaxBodySize <= 0 {
+		return defaultResolverMaxBodySize
+	}
+	return receiver.MaxBodySize
+}
+
+func (receiver Resolver) timeout() time.Duration {
+	if receiver.Timeout <= 0 {
+		return defaultResolverTimeout
+	}
+	return receiver.Timeout
+}
+
+// httpClient returns (a copy of) HTTPClient, whose CheckRedirect enforces MaxRedirects.
+func (receiver Resolver) httpClient() *http.Client {
+	var client http.Client
+	if nil != receiver.HTTPClient {
+		client = *receiver.HTTPClient
+	}
+
+	maxRedirects := receiver.MaxRedirects
+	switch {
+	case 0 == maxRedirects:
+		maxRedirects = defaultResolverMaxRedirects
+	case maxRedirects < 0:
+		maxRedirects = 0
+	}
+
+	client.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if maxRedirects < len(via) {
+			return erorr.Errorf("nftmeta: stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+
+	return &client
+}
+
+// fetchHTTPCached is like fetchHTTP, but (if there is a Cache) revalidates what is cached of 'url' with a conditional request — or caches what is fetched.
+func (receiver Resolver) fetchHTTPCached(ctx context.Context, url string) ([]byte, error) {
+	// If nothing is cached, then 'cached' has no ETag nor Last-Modified — so the request is not conditional.
+	cached, cachedData, _ := receiver.Cache.loadHTTP(url)
+
+	response, err := receiver.doHTTP(ctx, url, cached)
+	if nil != err {
+		return nil, err
+	}
+
+	if response.notModified {
+		receiver.Cache.touchHTTP(url)
+		return cachedData, nil
+	}
+
+	receiver.Cache.storeHTTP(diskCacheHeader{URL: url, ETag: response.etag, LastModified: response.lastModified}, response.data)
+	return response.data, nil
+}
+
+// fetchHTTP GETs 'url', enforcing the timeout, redirect limit, and maximum body size.
+func (receiver Resolver) fetchHTTP(ctx context.Context, url string) ([]byte, error) {
+	response, err := receiver.doHTTP(ctx, url, diskCacheHeader{})
+	if nil != err {
+		return nil, err
+	}
+	return response.data, nil
+}
+
+// httpResponse is what doHTTP got.
+type httpResponse struct {
+	data         []byte
+	etag         string
+	lastModified string
+
+	// notModified is true if the response was 304 Not Modified (in which case there is no data).
+	notModified bool
+}
+
+// doHTTP GETs 'url', enforcing the timeout, redirect limit, and maximum body size.
+// If 'conditions' has an ETag or Last-Modified, then the request is conditional (i.e., it has an If-None-Match or If-Modified-Since).
+func (receiver Resolver) doHTTP(ctx context.Context, url string, conditions diskCacheHeader) (httpResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, receiver.timeout())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if nil != err {
+		return httpResponse{}, erorr.Errorf("nftmeta: problem creating HTTP request for %q: %w", url, err)
+	}
+	request.Header.Set("Accept", "application/json, */*;q=0.5")
+	if "" != conditions.ETag {
+		request.Header.Set("If-None-Match", conditions.ETag)
+	}
+	if "" != conditions.LastModified {
+		request.Header.Set("If-Modified-Since", conditions.LastModified)
+	}
+
+	response, err := receiver.httpClient().Do(request)
+	if nil != err {
+		return httpResponse{}, erorr.Errorf("nftmeta: problem with HTTP request for %q: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if http.StatusNotModified == response.StatusCode && ("" != conditions.ETag || "" != conditions.LastModified) {
+		return httpResponse{notModified: true}, nil
+	}
+
+	if http.StatusOK != response.StatusCode {
+		statusError := HTTPStatusError{URL: url, StatusCode: response.StatusCode}
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); nil == err && 0 < seconds {
+			statusError.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return httpResponse{}, statusError
+	}
+
+	maxBodySize := receiver.maxBodySize()
+	if maxBodySize < response.ContentLength {
+		return httpResponse{}, erorr.Errorf("nftmeta: HTTP response for %q is %d bytes, which is bigger than %d bytes", url, response.ContentLength, maxBodySize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize+1))
+	if nil != err {
+		return httpResponse{}, erorr.Errorf("nftmeta: problem reading HTTP response for %q: %w", url, err)
+	}
+	if maxBodySize < int64(len(data)) {
+		return httpResponse{}, erorr.Errorf("nftmeta: HTTP response for %q is bigger than %d bytes", url, maxBodySize)
+	}
+
+	return httpResponse{
+		data:         data,
+		etag:         response.Header.Get("ETag"),
+		lastModified: response.Header.Get("Last-Modified"),
+	}, nil
+}
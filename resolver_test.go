@@ -0,0 +1,215 @@
+package nftmeta_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+const resolverTestJSON = `{"name":"Dave Starbelly","image":"ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3.png"}`
+
+func TestResolver_Resolve(t *testing.T) {
+
+	origin := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if "/token/3" != request.URL.Path {
+			http.NotFound(responseWriter, request)
+			return
+		}
+		responseWriter.Header().Set("Content-Type", "application/json")
+		responseWriter.Write([]byte(resolverTestJSON))
+	}))
+	defer origin.Close()
+
+	// The first IPFS gateway is down, so the second one has to be fallen back to.
+	var brokenGatewayRequests int64
+	brokenGateway := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		atomic.AddInt64(&brokenGatewayRequests, 1)
+		http.Error(responseWriter, "down", http.StatusBadGateway)
+	}))
+	defer brokenGateway.Close()
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3":
+		case "/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U":
+		default:
+			http.NotFound(responseWriter, request)
+			return
+		}
+		responseWriter.Write([]byte(resolverTestJSON))
+	}))
+	defer gateway.Close()
+
+	resolver := nftmeta.Resolver{
+		IPFSGateways:   []string{brokenGateway.URL, gateway.URL},
+		ArweaveGateway: gateway.URL,
+	}
+
+	tests := []struct{
+		TokenURI string
+	}{
+		{
+			TokenURI: origin.URL + "/token/3",
+		},
+		{
+			TokenURI: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+		},
+		{
+			// A gateway URL of some other gateway goes through the resolver's gateways.
+			TokenURI: "https://ipfs.io/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+		},
+		{
+			TokenURI: "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U",
+		},
+		{
+			TokenURI: "data:application/json;utf8," + resolverTestJSON,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		metadata, err := resolver.Resolve(context.Background(), test.TokenURI)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("TOKEN-URI: %q", test.TokenURI)
+			continue
+		}
+
+		if expected, actual := "Dave Starbelly", metadata.Name().GetElse(""); expected != actual {
+			t.Errorf("For test #%d, the actual name is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+
+	if expected, actual := int64(2), atomic.LoadInt64(&brokenGatewayRequests); expected != actual {
+		t.Errorf("The actual number of requests to the broken gateway is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+}
+
+func TestResolver_Fetch_limits(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch {
+		case "/slow" == request.URL.Path:
+			select {
+			case <-time.After(2 * time.Second):
+			case <-request.Context().Done():
+			}
+			responseWriter.Write([]byte(resolverTestJSON))
+		case "/big" == request.URL.Path:
+			responseWriter.Write([]byte(strings.Repeat(" ", 1000)))
+		case strings.HasPrefix(request.URL.Path, "/redirect/"):
+			// /redirect/N redirects N times, and then gives the metadata.
+			n, _ := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/redirect/"))
+			if 0 < n {
+				http.Redirect(responseWriter, request, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+				return
+			}
+			responseWriter.Write([]byte(resolverTestJSON))
+		case "/busy" == request.URL.Path:
+			responseWriter.Header().Set("Retry-After", "7")
+			http.Error(responseWriter, "slow down", http.StatusTooManyRequests)
+		default:
+			http.NotFound(responseWriter, request)
+		}
+	}))
+	defer server.Close()
+
+	resolver := nftmeta.Resolver{
+		HTTPClient:   server.Client(),
+		Timeout:      100 * time.Millisecond,
+		MaxBodySize:  500,
+		MaxRedirects: 2,
+	}
+
+	tests := []struct{
+		Path string
+		ExpectError bool
+	}{
+		{
+			Path:        "/slow",
+			ExpectError: true,
+		},
+		{
+			Path:        "/big",
+			ExpectError: true,
+		},
+		{
+			Path:        "/redirect/2",
+			ExpectError: false,
+		},
+		{
+			Path:        "/redirect/3",
+			ExpectError: true,
+		},
+		{
+			Path:        "/busy",
+			ExpectError: true,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		_, err := resolver.Fetch(context.Background(), server.URL+test.Path)
+		if expected, actual := test.ExpectError, nil != err; expected != actual {
+			t.Errorf("For test #%d, whether there was an error is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			t.Logf("PATH: %q", test.Path)
+			t.Logf("ERROR: %v", err)
+			continue
+		}
+	}
+
+	// A status error can be gotten at, for retrying.
+	{
+		_, err := resolver.Fetch(context.Background(), server.URL+"/busy")
+
+		var statusError nftmeta.HTTPStatusError
+		if !errors.As(err, &statusError) {
+			t.Fatalf("Expected an HTTPStatusError but actually got: (%T) %v", err, err)
+		}
+
+		if expected, actual := http.StatusTooManyRequests, statusError.StatusCode; expected != actual {
+			t.Errorf("The actual status code is not what was expected.")
+			t.Logf("EXPECTED: %d", expected)
+			t.Logf("ACTUAL:   %d", actual)
+		}
+		if expected, actual := 7*time.Second, statusError.RetryAfter; expected != actual {
+			t.Errorf("The actual retry-after is not what was expected.")
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+		}
+	}
+}
+
+func TestResolver_Fetch_noGateway(t *testing.T) {
+
+	var resolver nftmeta.Resolver
+
+	for testNumber, tokenURI := range []string{
+		"ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/3",
+		"ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U",
+		"ipns://example.eth",
+	}{
+		_, err := resolver.Fetch(context.Background(), tokenURI)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("TOKEN-URI: %q", tokenURI)
+			continue
+		}
+	}
+}
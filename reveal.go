@@ -0,0 +1,242 @@
+package nftmeta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// RevealController serves the metadata of a drop that is revealed — everyone gets the same Placeholder until the reveal, and the real metadata after it.
+//
+// At the reveal, the token-ids are also shuffled — each token-id is mapped to a metadata-id by a starting-index offset (see RevealStartingIndex) that is derived from a random Seed.
+// Publishing the commitment to the Seed (see RevealCommitment) before the drop, and publishing the RevealProof after the reveal, lets anyone check that the mapping was not chosen after the fact.
+//
+// The reveal happens at RevealTime (if it is not zero), or when Reveal is called — whichever comes first.
+//
+// Its Lookup can be the Lookup of a MetaDataHandler.
+type RevealController struct {
+	// Source returns the (real) metadata with the metadata-id 'metadataID'.
+	Source func(ctx context.Context, metadataID *big.Int) (MetaData, error)
+
+	// Placeholder is the metadata of every token before the reveal.
+	Placeholder MetaData
+
+	// RevealTime is when the reveal happens. If it is zero, the reveal only happens when Reveal is called.
+	RevealTime time.Time
+
+	// FirstTokenID is the first token-id of the collection (which is usually 0 or 1). If it is nil, then 0 is used.
+	FirstTokenID *big.Int
+
+	// Supply is how many tokens the collection has — with the token-ids FirstTokenID, FirstTokenID+1, …, FirstTokenID+Supply-1.
+	Supply uint64
+
+	// Seed is the random seed that the starting-index offset is derived from. (See NewRevealSeed.)
+	// It must be kept secret until the reveal.
+	Seed []byte
+
+	revealed atomic.Bool
+}
+
+// RevealProof is what is published after a reveal, to prove the mapping of the token-ids to the metadata-ids.
+type RevealProof struct {
+	Seed          []byte
+	Commitment    [32]byte
+	FirstTokenID  *big.Int
+	Supply        uint64
+	StartingIndex uint64
+}
+
+// NewRevealSeed returns a new (cryptographically) random 32-byte seed.
+func NewRevealSeed() ([]byte, error) {
+	var seed [32]byte
+	if _, err := rand.Read(seed[:]); nil != err {
+		return nil, erorr.Errorf("nftmeta: problem creating reveal seed: %w", err)
+	}
+	return seed[:], nil
+}
+
+// RevealCommitment returns the commitment to the seed 'seed' — which is its Keccak-256 digest (i.e., keccak256(seed), in Solidity).
+func RevealCommitment(seed []byte) [32]byte {
+	return keccak256(seed)
+}
+
+// RevealStartingIndex returns the starting-index offset that the seed 'seed' gives a collection of 'supply' tokens.
+//
+// It is the Keccak-256 digest of the seed followed by the supply (as 32 big-endian bytes), as an unsigned integer, modulo the supply.
+// In Solidity, that is:
+//
+//	uint256(keccak256(abi.encodePacked(seed, uint256(supply)))) % supply
+func RevealStartingIndex(seed []byte, supply uint64) uint64 {
+	if 0 == supply {
+		return 0
+	}
+
+	var encodedSupply [32]byte
+	new(big.Int).SetUint64(supply).FillBytes(encodedSupply[:])
+
+	digest := keccak256(seed, encodedSupply[:])
+
+	var startingIndex big.Int
+	startingIndex.SetBytes(digest[:])
+	startingIndex.Mod(&startingIndex, new(big.Int).SetUint64(supply))
+	return startingIndex.Uint64()
+}
+
+// revealMetaDataID maps the token-id 'tokenID' to its metadata-id:
+//
+//	metadataID = firstTokenID + ((tokenID - firstTokenID + startingIndex) mod supply)
+//
+// It returns false if 'tokenID' is not one of the token-ids of the collection.
+func revealMetaDataID(tokenID *big.Int, firstTokenID *big.Int, supply uint64, startingIndex uint64) (*big.Int, bool) {
+	if nil == tokenID {
+		return nil, false
+	}
+	if nil == firstTokenID {
+		firstTokenID = new(big.Int)
+	}
+
+	index := new(big.Int).Sub(tokenID, firstTokenID)
+	if index.Sign() < 0 || 0 <= index.Cmp(new(big.Int).SetUint64(supply)) {
+		return nil, false
+	}
+
+	index.Add(index, new(big.Int).SetUint64(startingIndex))
+	index.Mod(index, new(big.Int).SetUint64(supply))
+	return index.Add(index, firstTokenID), true
+}
+
+// Reveal reveals now (if it has not been revealed already).
+// It returns an error if there is no Seed.
+func (receiver *RevealController) Reveal() error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+	if len(receiver.Seed) <= 0 {
+		return errRevealSeedMissing
+	}
+
+	receiver.revealed.Store(true)
+	return nil
+}
+
+// Revealed returns whether it has been revealed — either because RevealTime has passed, or because Reveal was called.
+func (receiver *RevealController) Revealed() bool {
+	if nil == receiver {
+		return false
+	}
+	if receiver.revealed.Load() {
+		return true
+	}
+	return !receiver.RevealTime.IsZero() && !time.Now().Before(receiver.RevealTime) && 0 < len(receiver.Seed)
+}
+
+// Commitment returns the commitment to the Seed (see RevealCommitment) — which is what is published before the drop.
+func (receiver *RevealController) Commitment() [32]byte {
+	return RevealCommitment(receiver.Seed)
+}
+
+// MetaDataID returns the metadata-id that the token-id 'tokenID' is mapped to.
+// It returns an error if it has not been revealed yet, or if 'tokenID' is not one of the token-ids of the collection.
+func (receiver *RevealController) MetaDataID(tokenID *big.Int) (*big.Int, error) {
+	if nil == receiver {
+		return nil, errNilReceiver
+	}
+	if nil == tokenID {
+		return nil, errNilTokenID
+	}
+	if !receiver.Revealed() {
+		return nil, errRevealNotRevealed
+	}
+
+	metadataID, ok := revealMetaDataID(tokenID, receiver.FirstTokenID, receiver.Supply, RevealStartingIndex(receiver.Seed, receiver.Supply))
+	if !ok {
+		return nil, TokenNotFoundError{TokenID: tokenID}
+	}
+	return metadataID, nil
+}
+
+// Lookup returns the metadata of the token 'tokenID' — which is the Placeholder before the reveal, and the metadata (from Source) of the metadata-id that the token-id is mapped to after it.
+//
+// It returns a TokenNotFoundError if 'tokenID' is not one of the token-ids of the collection.
+func (receiver *RevealController) Lookup(ctx context.Context, tokenID *big.Int) (MetaData, error) {
+	if nil == receiver {
+		return MetaData{}, errNilReceiver
+	}
+	if nil == tokenID {
+		return MetaData{}, errNilTokenID
+	}
+
+	if !receiver.Revealed() {
+		if _, ok := revealMetaDataID(tokenID, receiver.FirstTokenID, receiver.Supply, 0); !ok {
+			return MetaData{}, TokenNotFoundError{TokenID: tokenID}
+		}
+		return receiver.Placeholder, nil
+	}
+
+	metadataID, err := receiver.MetaDataID(tokenID)
+	if nil != err {
+		return MetaData{}, err
+	}
+
+	if nil == receiver.Source {
+		return MetaData{}, errRevealSourceMissing
+	}
+	return receiver.Source(ctx, metadataID)
+}
+
+// Proof returns the proof of the mapping of the token-ids to the metadata-ids.
+// It returns an error if it has not been revealed yet — since the proof discloses the Seed.
+func (receiver *RevealController) Proof() (RevealProof, error) {
+	if nil == receiver {
+		return RevealProof{}, errNilReceiver
+	}
+	if !receiver.Revealed() {
+		return RevealProof{}, errRevealNotRevealed
+	}
+
+	firstTokenID := new(big.Int)
+	if nil != receiver.FirstTokenID {
+		firstTokenID.Set(receiver.FirstTokenID)
+	}
+
+	return RevealProof{
+		Seed:          append([]byte(nil), receiver.Seed...),
+		Commitment:    receiver.Commitment(),
+		FirstTokenID:  firstTokenID,
+		Supply:        receiver.Supply,
+		StartingIndex: RevealStartingIndex(receiver.Seed, receiver.Supply),
+	}, nil
+}
+
+// Verify checks that the proof is consistent, and that its seed is the one that 'commitment' (which was published before the drop) commits to.
+func (receiver RevealProof) Verify(commitment [32]byte) error {
+	if actual := RevealCommitment(receiver.Seed); actual != commitment {
+		return erorr.Errorf("nftmeta: reveal seed does not match the commitment — expected commitment 0x%s but the seed gives 0x%s", hex.EncodeToString(commitment[:]), hex.EncodeToString(actual[:]))
+	}
+	if receiver.Commitment != commitment {
+		return erorr.Errorf("nftmeta: reveal proof's commitment 0x%s is not the published commitment 0x%s", hex.EncodeToString(receiver.Commitment[:]), hex.EncodeToString(commitment[:]))
+	}
+	if expected := RevealStartingIndex(receiver.Seed, receiver.Supply); expected != receiver.StartingIndex {
+		return erorr.Errorf("nftmeta: reveal proof's starting-index %d is not what the seed gives, which is %d", receiver.StartingIndex, expected)
+	}
+	return nil
+}
+
+// MetaDataID returns the metadata-id that the proof maps the token-id 'tokenID' to.
+// (It does not Verify the proof.)
+func (receiver RevealProof) MetaDataID(tokenID *big.Int) (*big.Int, error) {
+	if nil == tokenID {
+		return nil, errNilTokenID
+	}
+
+	metadataID, ok := revealMetaDataID(tokenID, receiver.FirstTokenID, receiver.Supply, receiver.StartingIndex)
+	if !ok {
+		return nil, TokenNotFoundError{TokenID: tokenID}
+	}
+	return metadataID, nil
+}
@@ -0,0 +1,230 @@
+package nftmeta_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestRevealCommitment(t *testing.T) {
+
+	tests := []struct{
+		Seed     []byte
+		Expected string
+	}{
+		{
+			Seed:     []byte{},
+			Expected: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		},
+		{
+			// keccak256(abi.encode(uint256(0)))
+			Seed:     make([]byte, 32),
+			Expected: "290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		commitment := nftmeta.RevealCommitment(test.Seed)
+
+		if expected, actual := test.Expected, hex.EncodeToString(commitment[:]); expected != actual {
+			t.Errorf("For test #%d, the actual commitment is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
+
+func revealTestController(revealTime time.Time) *nftmeta.RevealController {
+	var placeholder nftmeta.MetaData
+	placeholder.SetName("Mystery Box")
+
+	return &nftmeta.RevealController{
+		Source: func(ctx context.Context, metadataID *big.Int) (nftmeta.MetaData, error) {
+			var metadata nftmeta.MetaData
+			metadata.SetName("Metadata #" + metadataID.String())
+			return metadata, nil
+		},
+		Placeholder:  placeholder,
+		RevealTime:   revealTime,
+		FirstTokenID: big.NewInt(1),
+		Supply:       100,
+		Seed:         []byte("the seed that was committed to before the drop"),
+	}
+}
+
+func TestRevealController(t *testing.T) {
+
+	controller := revealTestController(time.Time{})
+
+	// Before the reveal, every token is the placeholder.
+	for _, tokenID := range []int64{1, 50, 100} {
+		metadata, err := controller.Lookup(context.Background(), big.NewInt(tokenID))
+		if nil != err {
+			t.Fatalf("For token-id %d, did not expect an error but actually got one: (%T) %s", tokenID, err, err)
+		}
+		if expected, actual := "Mystery Box", metadata.Name().GetElse(""); expected != actual {
+			t.Errorf("For token-id %d, the actual name is not what was expected.", tokenID)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+
+	if _, err := controller.Proof(); nil == err {
+		t.Errorf("Expected an error (for a proof before the reveal) but did not actually get one.")
+	}
+
+	for _, tokenID := range []int64{0, 101} {
+		_, err := controller.Lookup(context.Background(), big.NewInt(tokenID))
+
+		var notFoundError nftmeta.TokenNotFoundError
+		if !errors.As(err, &notFoundError) {
+			t.Errorf("For token-id %d, expected a TokenNotFoundError but actually got something else.", tokenID)
+			t.Logf("ERROR: (%T) %v", err, err)
+		}
+	}
+
+	commitment := controller.Commitment()
+
+	if err := controller.Reveal(); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	if !controller.Revealed() {
+		t.Fatalf("Expected it to be revealed but it actually is not.")
+	}
+
+	proof, err := controller.Proof()
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+	if err := proof.Verify(commitment); nil != err {
+		t.Errorf("Did not expect an error (verifying the proof) but actually got one.")
+		t.Logf("ERROR: (%T) %s", err, err)
+	}
+
+	// After the reveal, the token-ids are mapped — one-to-one — onto the metadata-ids, the same way the proof maps them.
+	var seen = map[string]bool{}
+	for tokenID := int64(1); tokenID <= 100; tokenID++ {
+		metadata, err := controller.Lookup(context.Background(), big.NewInt(tokenID))
+		if nil != err {
+			t.Fatalf("For token-id %d, did not expect an error but actually got one: (%T) %s", tokenID, err, err)
+		}
+
+		metadataID, err := proof.MetaDataID(big.NewInt(tokenID))
+		if nil != err {
+			t.Fatalf("For token-id %d, did not expect an error but actually got one: (%T) %s", tokenID, err, err)
+		}
+
+		if expected, actual := "Metadata #"+metadataID.String(), metadata.Name().GetElse(""); expected != actual {
+			t.Errorf("For token-id %d, the actual name is not what was expected.", tokenID)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+
+		if metadataID.Cmp(big.NewInt(1)) < 0 || 0 < metadataID.Cmp(big.NewInt(100)) {
+			t.Errorf("For token-id %d, the metadata-id %s is out of range.", tokenID, metadataID)
+		}
+		if seen[metadataID.String()] {
+			t.Errorf("For token-id %d, the metadata-id %s was already mapped to.", tokenID, metadataID)
+		}
+		seen[metadataID.String()] = true
+	}
+
+	if expected, actual := nftmeta.RevealStartingIndex(controller.Seed, 100), proof.StartingIndex; expected != actual {
+		t.Errorf("The actual starting-index is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+	metadataID, _ := proof.MetaDataID(big.NewInt(1))
+	if expected, actual := 1+int64(proof.StartingIndex), metadataID.Int64(); expected != actual {
+		t.Errorf("The actual metadata-id of the first token is not what was expected.")
+		t.Logf("EXPECTED: %d", expected)
+		t.Logf("ACTUAL:   %d", actual)
+	}
+}
+
+func TestRevealController_revealTime(t *testing.T) {
+
+	tests := []struct{
+		RevealTime time.Time
+		Expected   bool
+	}{
+		{
+			RevealTime: time.Time{},
+			Expected:   false,
+		},
+		{
+			RevealTime: time.Now().Add(time.Hour),
+			Expected:   false,
+		},
+		{
+			RevealTime: time.Now().Add(-time.Hour),
+			Expected:   true,
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		controller := revealTestController(test.RevealTime)
+
+		if expected, actual := test.Expected, controller.Revealed(); expected != actual {
+			t.Errorf("For test #%d, whether it is revealed is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %t", expected)
+			t.Logf("ACTUAL:   %t", actual)
+			continue
+		}
+	}
+}
+
+func TestRevealProof_Verify_bad(t *testing.T) {
+
+	controller := revealTestController(time.Now().Add(-time.Hour))
+
+	proof, err := controller.Proof()
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	tests := []struct{
+		Modify func(*nftmeta.RevealProof) [32]byte
+	}{
+		{
+			// Someone else's commitment.
+			Modify: func(proof *nftmeta.RevealProof) [32]byte {
+				return nftmeta.RevealCommitment([]byte("some other seed"))
+			},
+		},
+		{
+			// A different seed than was committed to.
+			Modify: func(proof *nftmeta.RevealProof) [32]byte {
+				commitment := proof.Commitment
+				proof.Seed = []byte("some other seed")
+				return commitment
+			},
+		},
+		{
+			// A starting-index that the seed does not give.
+			Modify: func(proof *nftmeta.RevealProof) [32]byte {
+				proof.StartingIndex = (proof.StartingIndex + 1) % proof.Supply
+				return proof.Commitment
+			},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		modified := proof
+		commitment := test.Modify(&modified)
+
+		if err := modified.Verify(commitment); nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			continue
+		}
+	}
+}
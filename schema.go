@@ -0,0 +1,207 @@
+package nftmeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// Schema represents collection-specific rules for the "attributes" of NFT metadata.
+//
+// A Schema is loaded from JSON. For example:
+//
+//	{
+//		"traits": {
+//			"Background": { "required":true, "values":["Blue","Green","Red"] },
+//			"Level":      { "required":true, "minimum":1, "maximum":100, "display_types":["number","boost_number"] },
+//			"Hat":        { "values":["Beanie","Crown"] }
+//		},
+//		"allow_unknown_traits": false
+//	}
+//
+// For each trait:
+// "required" means an attribute with that trait-type must be present;
+// "values" lists the permitted (string) values;
+// "minimum" and "maximum" give an inclusive numeric range;
+// and "display_types" lists the permitted display-types (an attribute without a display-type is always permitted).
+//
+// Regardless of the rules, at most one attribute per trait-type is permitted.
+//
+// Use Schema.Validate to check a MetaData against the Schema.
+type Schema struct {
+	traits             map[string]schemaTrait
+	allowUnknownTraits bool
+}
+
+type schemaTrait struct {
+	required     bool
+	values       []string
+	minimum      opt.Optional[*big.Float]
+	maximum      opt.Optional[*big.Float]
+	displayTypes []string
+}
+
+func (receiver *Schema) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		Traits map[string]struct {
+			Required     bool         `json:"required"`
+			Values       []string     `json:"values"`
+			Minimum      *json.Number `json:"minimum"`
+			Maximum      *json.Number `json:"maximum"`
+			DisplayTypes []string     `json:"display_types"`
+		} `json:"traits"`
+		AllowUnknownTraits bool `json:"allow_unknown_traits"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling schema: %w", err)
+	}
+
+	var schema Schema
+	schema.allowUnknownTraits = raw.AllowUnknownTraits
+	schema.traits = map[string]schemaTrait{}
+
+	for traitType, rawTrait := range raw.Traits {
+		var trait schemaTrait
+
+		trait.required = rawTrait.Required
+		trait.values = rawTrait.Values
+		trait.displayTypes = rawTrait.DisplayTypes
+
+		if nil != rawTrait.Minimum {
+			minimum, ok := big.NewFloat(0).SetString(rawTrait.Minimum.String())
+			if !ok {
+				return erorr.Errorf("nftmeta: schema trait %q has a bad minimum %q", traitType, rawTrait.Minimum.String())
+			}
+			trait.minimum = opt.Something(minimum)
+		}
+		if nil != rawTrait.Maximum {
+			maximum, ok := big.NewFloat(0).SetString(rawTrait.Maximum.String())
+			if !ok {
+				return erorr.Errorf("nftmeta: schema trait %q has a bad maximum %q", traitType, rawTrait.Maximum.String())
+			}
+			trait.maximum = opt.Something(maximum)
+		}
+
+		schema.traits[traitType] = trait
+	}
+
+	*receiver = schema
+	return nil
+}
+
+// Validate checks the attributes of 'metadata' against the schema.
+//
+// If there are no violations, then Validate returns nil.
+// Otherwise it returns SchemaViolations, which contains every violation that was found.
+func (receiver Schema) Validate(metadata MetaData) error {
+	var violations SchemaViolations
+
+	var seen = map[string]int{}
+
+	for index, attribute := range metadata.attributes {
+		traitType, something := attribute.traitType.Get()
+		if !something {
+			violations = append(violations, SchemaViolation{index: index, reason: "missing trait-type"})
+			continue
+		}
+
+		if firstIndex, found := seen[traitType]; found {
+			violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+				reason: fmt.Sprintf("duplicate of attribute #%d", firstIndex)})
+			continue
+		}
+		seen[traitType] = index
+
+		trait, found := receiver.traits[traitType]
+		if !found {
+			if !receiver.allowUnknownTraits {
+				violations = append(violations, SchemaViolation{index: index, traitType: traitType, reason: "unknown trait-type"})
+			}
+			continue
+		}
+
+		violations = append(violations, trait.violations(index, traitType, attribute)...)
+	}
+
+	{
+		var missing []string
+		for traitType, trait := range receiver.traits {
+			if !trait.required {
+				continue
+			}
+			if _, found := seen[traitType]; !found {
+				missing = append(missing, traitType)
+			}
+		}
+		sort.Strings(missing)
+
+		for _, traitType := range missing {
+			violations = append(violations, SchemaViolation{index: -1, traitType: traitType, reason: "required trait-type is missing"})
+		}
+	}
+
+	if len(violations) <= 0 {
+		return nil
+	}
+	return violations
+}
+
+func (receiver schemaTrait) violations(index int, traitType string, attribute Attribute) []SchemaViolation {
+	var violations []SchemaViolation
+
+	if nil != receiver.values {
+		str, isString := attribute.value.(string)
+		switch {
+		case !isString:
+			violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+				reason: fmt.Sprintf("value of type %T is not one of the permitted values", attribute.value)})
+		case !containsString(receiver.values, str):
+			violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+				reason: fmt.Sprintf("value %q is not one of the permitted values", str)})
+		}
+	}
+
+	if receiver.minimum.IsSomething() || receiver.maximum.IsSomething() {
+		number, isNumber := attributeValueBigFloat(attribute.value)
+		if !isNumber {
+			violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+				reason: fmt.Sprintf("value of type %T is not a number", attribute.value)})
+		} else {
+			if minimum, something := receiver.minimum.Get(); something && number.Cmp(minimum) < 0 {
+				violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+					reason: fmt.Sprintf("value %s is less than the minimum %s", number.Text('f', -1), minimum.Text('f', -1))})
+			}
+			if maximum, something := receiver.maximum.Get(); something && 0 < number.Cmp(maximum) {
+				violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+					reason: fmt.Sprintf("value %s is greater than the maximum %s", number.Text('f', -1), maximum.Text('f', -1))})
+			}
+		}
+	}
+
+	if nil != receiver.displayTypes {
+		if displayType, something := attribute.displayType.Get(); something && !containsString(receiver.displayTypes, displayType) {
+			violations = append(violations, SchemaViolation{index: index, traitType: traitType,
+				reason: fmt.Sprintf("display-type %q is not permitted", displayType)})
+		}
+	}
+
+	return violations
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, str := range haystack {
+		if needle == str {
+			return true
+		}
+	}
+	return false
+}
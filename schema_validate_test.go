@@ -0,0 +1,107 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"encoding/json"
+	"errors"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestSchema_Validate(t *testing.T) {
+
+	const schemaJSON = `{
+		"traits": {
+			"Background": { "required":true, "values":["Blue","Green","Red"] },
+			"Level":      { "required":true, "minimum":1, "maximum":100, "display_types":["number","boost_number"] },
+			"Hat":        { "values":["Beanie","Crown"] }
+		}
+	}`
+
+	var schema nftmeta.Schema
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); nil != err {
+		t.Fatalf("Did not expect an error when loading the schema but actually got one: (%T) %s", err, err)
+	}
+
+	tests := []struct{
+		MetaData string
+		ExpectedIndexes []int
+	}{
+		{
+			MetaData: `{"attributes":[{"trait_type":"Background","value":"Blue"},{"display_type":"number","trait_type":"Level","value":7}]}`,
+			ExpectedIndexes: nil,
+		},
+		{
+			MetaData: `{"attributes":[{"trait_type":"Background","value":"Blue"},{"trait_type":"Level","value":100},{"trait_type":"Hat","value":"Crown"}]}`,
+			ExpectedIndexes: nil,
+		},
+		{
+			MetaData: `{"attributes":[{"trait_type":"Background","value":"Purple"},{"trait_type":"Level","value":101}]}`,
+			ExpectedIndexes: []int{0,1},
+		},
+		{
+			MetaData: `{"attributes":[{"trait_type":"Level","value":"high"}]}`,
+			ExpectedIndexes: []int{0,-1},
+		},
+		{
+			MetaData: `{"attributes":[{"trait_type":"Background","value":"Red"},{"trait_type":"Background","value":"Blue"},{"display_type":"date","trait_type":"Level","value":0.5}]}`,
+			ExpectedIndexes: []int{1,2,2},
+		},
+		{
+			MetaData: `{"attributes":[{"trait_type":"Eyes","value":"Laser"}]}`,
+			ExpectedIndexes: []int{0,-1,-1},
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		var metadata nftmeta.MetaData
+		if err := json.Unmarshal([]byte(test.MetaData), &metadata); nil != err {
+			t.Errorf("For test #%d, did not expect an error when unmarshaling the metadata but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		err := schema.Validate(metadata)
+
+		if nil == test.ExpectedIndexes {
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+			}
+			continue
+		}
+
+		var violations nftmeta.SchemaViolations
+		if !errors.As(err, &violations) {
+			t.Errorf("For test #%d, expected schema violations but actually got: (%T) %v", testNumber, err, err)
+			continue
+		}
+
+		{
+			expected := len(test.ExpectedIndexes)
+			actual := len(violations)
+
+			if expected != actual {
+				t.Errorf("For test #%d, the actual number of violations is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %d", expected)
+				t.Logf("ACTUAL:   %d", actual)
+				t.Logf("ERROR: %s", err)
+				continue
+			}
+		}
+
+		for index, violation := range violations {
+			expected := test.ExpectedIndexes[index]
+			actual := violation.Index()
+
+			if expected != actual {
+				t.Errorf("For test #%d and violation #%d, the actual attribute index is not what was expected.", testNumber, index)
+				t.Logf("EXPECTED: %d", expected)
+				t.Logf("ACTUAL:   %d", actual)
+				t.Logf("VIOLATION: %s", violation)
+			}
+		}
+	}
+}
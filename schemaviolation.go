@@ -0,0 +1,54 @@
+package nftmeta
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaViolation represents a single way in which a MetaData does not conform to a Schema.
+type SchemaViolation struct {
+	index     int
+	traitType string
+	reason    string
+}
+
+// Index returns the index (in the "attributes" array) of the attribute that violated the schema.
+//
+// Index returns -1 if the violation is not about any one attribute — for example, when a required trait-type is missing.
+func (receiver SchemaViolation) Index() int {
+	return receiver.index
+}
+
+// TraitType returns the trait-type that the violation is about.
+func (receiver SchemaViolation) TraitType() string {
+	return receiver.traitType
+}
+
+// Reason returns a description of the violation.
+func (receiver SchemaViolation) Reason() string {
+	return receiver.reason
+}
+
+func (receiver SchemaViolation) Error() string {
+	if receiver.index < 0 {
+		return fmt.Sprintf("nftmeta: schema violation for trait-type %q: %s", receiver.traitType, receiver.reason)
+	}
+
+	return fmt.Sprintf("nftmeta: schema violation at attribute #%d (trait-type %q): %s", receiver.index, receiver.traitType, receiver.reason)
+}
+
+// SchemaViolations is returned by Schema.Validate, and contains every violation that was found.
+type SchemaViolations []SchemaViolation
+
+func (receiver SchemaViolations) Error() string {
+	var builder strings.Builder
+
+	for index, violation := range receiver {
+		if 0 < index {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(violation.Error())
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,501 @@
+package nftmeta
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// TZIP21MetaData represents the Tezos TZIP-21 (rich metadata) JSON.
+//
+// The TZIP-21 "attributes" are {"name","value","type"} objects.
+// These are held as Attribute values — with "name" as the trait-type, and "type" as the display-type.
+type TZIP21MetaData struct {
+	artifactURI     opt.Optional[string]
+	description     opt.Optional[string]
+	displayURI      opt.Optional[string]
+	externalURI     opt.Optional[string]
+	name            opt.Optional[string]
+	symbol          opt.Optional[string]
+	thumbnailURI    opt.Optional[string]
+	decimals        opt.Optional[uint64]
+	isBooleanAmount opt.Optional[bool]
+	creators      []string
+	tags          []string
+	formats       []TZIP21Format
+	royalties       opt.Optional[TZIP21Royalties]
+	attributes    []Attribute
+}
+
+// TZIP21Format represents an entry in the "formats" array of the TZIP-21 metadata JSON.
+type TZIP21Format struct {
+	URI        string            `json:"uri"`
+	Hash       string            `json:"hash,omitempty"`
+	MimeType   string            `json:"mimeType,omitempty"`
+	FileSize   uint64            `json:"fileSize,omitempty"`
+	FileName   string            `json:"fileName,omitempty"`
+	Duration   string            `json:"duration,omitempty"`
+	Dimensions *TZIP21Dimensions `json:"dimensions,omitempty"`
+	DataRate   *TZIP21DataRate   `json:"dataRate,omitempty"`
+}
+
+// TZIP21Dimensions represents the "dimensions" of a TZIP-21 format. For example: {"value":"1920x1080","unit":"px"}.
+type TZIP21Dimensions struct {
+	Value string `json:"value"`
+	Unit  string `json:"unit"`
+}
+
+// TZIP21DataRate represents the "dataRate" of a TZIP-21 format. For example: {"value":256,"unit":"kbps"}.
+type TZIP21DataRate struct {
+	Value uint64 `json:"value"`
+	Unit  string `json:"unit"`
+}
+
+// TZIP21Royalties represents the "royalties" of the TZIP-21 metadata JSON.
+//
+// Each share is an amount in units of 10^-Decimals, keyed by the address of its recipient.
+// So, for example, with Decimals of 4, a share of 250 is 2.5%.
+type TZIP21Royalties struct {
+	Decimals uint64            `json:"decimals"`
+	Shares   map[string]uint64 `json:"shares"`
+}
+
+// TZIP21MetaDataFrom returns the TZIP-21 metadata equivalent of 'metadata'.
+//
+// The "image" becomes the "displayUri"; the "external_link" becomes the "externalUri";
+// and the "animation_url" becomes the "artifactUri" — or, if there is no "animation_url", the "image" does.
+//
+// The "background_color", "image_data", and "youtube_url" of 'metadata' have no TZIP-21 equivalent, and are not carried over.
+func TZIP21MetaDataFrom(metadata MetaData) TZIP21MetaData {
+	var tzip21 TZIP21MetaData
+
+	tzip21.name        = metadata.name
+	tzip21.description = metadata.description
+	tzip21.displayURI  = metadata.image
+	tzip21.externalURI = metadata.externalLink
+	tzip21.attributes  = metadata.Attributes()
+
+	tzip21.artifactURI = metadata.animationURL
+	if tzip21.artifactURI.IsNothing() {
+		tzip21.artifactURI = metadata.image
+	}
+
+	return tzip21
+}
+
+// MetaData returns the ERC-721 metadata equivalent of the TZIP-21 metadata.
+//
+// The "displayUri" (or, if there is no "displayUri", the "artifactUri") becomes the "image";
+// and the "artifactUri", if it is different from that, becomes the "animation_url".
+//
+// Nothing else that TZIP-21 has, other than the "name", "description", "externalUri", and "attributes", has an ERC-721 equivalent.
+func (receiver TZIP21MetaData) MetaData() MetaData {
+	var metadata MetaData
+
+	metadata.name         = receiver.name
+	metadata.description  = receiver.description
+	metadata.externalLink = receiver.externalURI
+	metadata.attributes   = receiver.Attributes()
+
+	metadata.image = receiver.displayURI
+	if metadata.image.IsNothing() {
+		metadata.image = receiver.artifactURI
+	}
+
+	if artifactURI, something := receiver.artifactURI.Get(); something {
+		if image, _ := metadata.image.Get(); image != artifactURI {
+			metadata.animationURL = opt.Something(artifactURI)
+		}
+	}
+
+	return metadata
+}
+
+func (receiver TZIP21MetaData) MarshalJSON() ([]byte, error) {
+
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, '{')
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"artifactUri",  receiver.artifactURI},
+		{"description",  receiver.description},
+		{"displayUri",   receiver.displayURI},
+		{"externalUri",  receiver.externalURI},
+		{"name",         receiver.name},
+		{"symbol",       receiver.symbol},
+		{"thumbnailUri", receiver.thumbnailURI},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	{
+		value, something := receiver.decimals.Get()
+		if something {
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			p = append(p, `"decimals":`...)
+			p = strconv.AppendUint(p, value, 10)
+		}
+	}
+
+	{
+		value, something := receiver.isBooleanAmount.Get()
+		if something {
+			if after {
+				p = append(p, ',')
+			}
+			after = true
+
+			p = append(p, `"isBooleanAmount":`...)
+			p = strconv.AppendBool(p, value)
+		}
+	}
+
+	var royalties interface{}
+	if value, something := receiver.royalties.Get(); something {
+		royalties = value
+	}
+
+	for _, field := range []struct{
+		name  string
+		value interface{}
+		empty bool
+	}{
+		{"creators",  receiver.creators,  len(receiver.creators) <= 0},
+		{"tags",      receiver.tags,      len(receiver.tags) <= 0},
+		{"formats",   receiver.formats,   len(receiver.formats) <= 0},
+		{"royalties", royalties,          nil == royalties},
+	}{
+		if field.empty {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		bytes, err := json.Marshal(field.value)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling %s: %w", field.name, err)
+		}
+
+		p = append(p, '"')
+		p = append(p, field.name...)
+		p = append(p, `":`...)
+		p = append(p, bytes...)
+	}
+
+	if 0 < len(receiver.attributes) {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		p = append(p, `"attributes":[`...)
+		for index, attribute := range receiver.attributes {
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			var err error
+			p, err = appendTZIP21Attribute(p, attribute)
+			if nil != err {
+				return nil, err
+			}
+		}
+		p = append(p, ']')
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+// appendTZIP21Attribute appends the TZIP-21 form of 'attribute' — i.e., {"name","value","type"} — to 'p'.
+func appendTZIP21Attribute(p []byte, attribute Attribute) ([]byte, error) {
+	name, something := attribute.traitType.Get()
+	if !something {
+		return nil, errTraitTypeNothing
+	}
+
+	p = append(p, '{')
+
+	{
+		var err error
+		p, err = appendJSONNameValue(p, "name", name)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	p = append(p, `,"value":`...)
+	{
+		var err error
+		p, err = appendAttributeValue(p, attribute.value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	if value, something := attribute.displayType.Get(); something {
+		p = append(p, ',')
+
+		var err error
+		p, err = appendJSONNameValue(p, "type", value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func (receiver *TZIP21MetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		ArtifactURI     *string          `json:"artifactUri"`
+		Description     *string          `json:"description"`
+		DisplayURI      *string          `json:"displayUri"`
+		ExternalURI     *string          `json:"externalUri"`
+		Name            *string          `json:"name"`
+		Symbol          *string          `json:"symbol"`
+		ThumbnailURI    *string          `json:"thumbnailUri"`
+		Decimals        *uint64          `json:"decimals"`
+		IsBooleanAmount *bool            `json:"isBooleanAmount"`
+		Creators        []string         `json:"creators"`
+		Tags            []string         `json:"tags"`
+		Formats         []TZIP21Format   `json:"formats"`
+		Royalties       *TZIP21Royalties `json:"royalties"`
+		Attributes      []struct {
+			Name  *string         `json:"name"`
+			Value json.RawMessage `json:"value"`
+			Type  *string         `json:"type"`
+		} `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling tzip-21 metadata: %w", err)
+	}
+
+	var metadata TZIP21MetaData
+
+	metadata.artifactURI  = optionalString(raw.ArtifactURI)
+	metadata.description  = optionalString(raw.Description)
+	metadata.displayURI   = optionalString(raw.DisplayURI)
+	metadata.externalURI  = optionalString(raw.ExternalURI)
+	metadata.name         = optionalString(raw.Name)
+	metadata.symbol       = optionalString(raw.Symbol)
+	metadata.thumbnailURI = optionalString(raw.ThumbnailURI)
+	if nil != raw.Decimals {
+		metadata.decimals = opt.Something(*raw.Decimals)
+	}
+	if nil != raw.IsBooleanAmount {
+		metadata.isBooleanAmount = opt.Something(*raw.IsBooleanAmount)
+	}
+	metadata.creators = raw.Creators
+	metadata.tags     = raw.Tags
+	metadata.formats  = raw.Formats
+	if nil != raw.Royalties {
+		metadata.royalties = opt.Something(*raw.Royalties)
+	}
+
+	for index, rawAttribute := range raw.Attributes {
+		if nil == rawAttribute.Name {
+			return erorr.Errorf("nftmeta: tzip-21 attribute #%d has no name", index)
+		}
+
+		value, err := unmarshalAttributeValue(rawAttribute.Value)
+		if nil != err {
+			return err
+		}
+
+		var attribute Attribute
+		attribute.traitType = opt.Something(*rawAttribute.Name)
+		if nil != rawAttribute.Type {
+			attribute.displayType = opt.Something(*rawAttribute.Type)
+		}
+		attribute.value = value
+
+		metadata.attributes = append(metadata.attributes, attribute)
+	}
+
+	*receiver = metadata
+	return nil
+}
+
+func (receiver TZIP21MetaData) ArtifactURI() opt.Optional[string] {
+	return receiver.artifactURI
+}
+
+func (receiver TZIP21MetaData) Decimals() opt.Optional[uint64] {
+	return receiver.decimals
+}
+
+func (receiver TZIP21MetaData) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver TZIP21MetaData) DisplayURI() opt.Optional[string] {
+	return receiver.displayURI
+}
+
+func (receiver TZIP21MetaData) ExternalURI() opt.Optional[string] {
+	return receiver.externalURI
+}
+
+func (receiver TZIP21MetaData) IsBooleanAmount() opt.Optional[bool] {
+	return receiver.isBooleanAmount
+}
+
+func (receiver TZIP21MetaData) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver TZIP21MetaData) Royalties() opt.Optional[TZIP21Royalties] {
+	return receiver.royalties
+}
+
+func (receiver TZIP21MetaData) Symbol() opt.Optional[string] {
+	return receiver.symbol
+}
+
+func (receiver TZIP21MetaData) ThumbnailURI() opt.Optional[string] {
+	return receiver.thumbnailURI
+}
+
+// Attributes returns (a copy of) the attributes.
+//
+// The TZIP-21 "name" of each attribute is its trait-type, and the TZIP-21 "type" of each attribute is its display-type.
+func (receiver TZIP21MetaData) Attributes() []Attribute {
+	if len(receiver.attributes) <= 0 {
+		return nil
+	}
+
+	attributes := make([]Attribute, len(receiver.attributes))
+	copy(attributes, receiver.attributes)
+	return attributes
+}
+
+// Creators returns (a copy of) the creators.
+func (receiver TZIP21MetaData) Creators() []string {
+	if len(receiver.creators) <= 0 {
+		return nil
+	}
+
+	creators := make([]string, len(receiver.creators))
+	copy(creators, receiver.creators)
+	return creators
+}
+
+// Formats returns (a copy of) the formats.
+func (receiver TZIP21MetaData) Formats() []TZIP21Format {
+	if len(receiver.formats) <= 0 {
+		return nil
+	}
+
+	formats := make([]TZIP21Format, len(receiver.formats))
+	copy(formats, receiver.formats)
+	return formats
+}
+
+// Tags returns (a copy of) the tags.
+func (receiver TZIP21MetaData) Tags() []string {
+	if len(receiver.tags) <= 0 {
+		return nil
+	}
+
+	tags := make([]string, len(receiver.tags))
+	copy(tags, receiver.tags)
+	return tags
+}
+
+func (receiver *TZIP21MetaData) SetArtifactURI(value string) {
+	receiver.artifactURI = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetDecimals(value uint64) {
+	receiver.decimals = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetDisplayURI(value string) {
+	receiver.displayURI = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetExternalURI(value string) {
+	receiver.externalURI = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetIsBooleanAmount(value bool) {
+	receiver.isBooleanAmount = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetName(value string) {
+	receiver.name = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetRoyalties(value TZIP21Royalties) {
+	receiver.royalties = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetSymbol(value string) {
+	receiver.symbol = opt.Something(value)
+}
+
+func (receiver *TZIP21MetaData) SetThumbnailURI(value string) {
+	receiver.thumbnailURI = opt.Something(value)
+}
+
+// AppendAttribute appends to the "attributes".
+//
+// The trait-type of the attribute becomes the TZIP-21 "name", and the display-type of the attribute becomes the TZIP-21 "type".
+func (receiver *TZIP21MetaData) AppendAttribute(attribute Attribute) {
+	receiver.attributes = append(receiver.attributes, attribute)
+}
+
+func (receiver *TZIP21MetaData) AppendCreator(value string) {
+	receiver.creators = append(receiver.creators, value)
+}
+
+func (receiver *TZIP21MetaData) AppendFormat(value TZIP21Format) {
+	receiver.formats = append(receiver.formats, value)
+}
+
+func (receiver *TZIP21MetaData) AppendTag(value string) {
+	receiver.tags = append(receiver.tags, value)
+}
@@ -0,0 +1,127 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestTZIP21MetaData_MarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		TZIP21MetaData nftmeta.TZIP21MetaData
+		Expected []byte
+	}{
+		{
+			TZIP21MetaData: nftmeta.TZIP21MetaData{},
+			Expected: []byte(`{}`),
+		},
+		{
+			TZIP21MetaData: func()nftmeta.TZIP21MetaData{
+				var metadata nftmeta.TZIP21MetaData
+				metadata.SetName("Lake Tahoe")
+				metadata.SetDescription("A photo of Lake Tahoe.")
+				metadata.SetDecimals(0)
+				metadata.SetIsBooleanAmount(true)
+				metadata.SetArtifactURI("ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco")
+				metadata.SetDisplayURI("ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco/display.jpg")
+				metadata.SetThumbnailURI("ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco/thumbnail.jpg")
+				metadata.AppendCreator("tz1UBZUkXpKGhYsP5KtzDNqLLchwF4uHrGjw")
+				metadata.AppendTag("landscape")
+				metadata.AppendTag("lake")
+				metadata.AppendFormat(nftmeta.TZIP21Format{
+					URI: "ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco",
+					MimeType: "image/jpeg",
+					FileSize: 12345,
+					Dimensions: &nftmeta.TZIP21Dimensions{Value:"1920x1080", Unit:"px"},
+				})
+				metadata.SetRoyalties(nftmeta.TZIP21Royalties{
+					Decimals: 4,
+					Shares: map[string]uint64{
+						"tz1UBZUkXpKGhYsP5KtzDNqLLchwF4uHrGjw": 500,
+					},
+				})
+				metadata.AppendAttribute(nftmeta.AttributeString("Season", "Winter"))
+				metadata.AppendAttribute(nftmeta.TypedAttributeInt64("Edition", 3, "number"))
+
+				return metadata
+			}(),
+			Expected: []byte(`{"artifactUri":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco","description":"A photo of Lake Tahoe.","displayUri":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco/display.jpg","name":"Lake Tahoe","thumbnailUri":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco/thumbnail.jpg","decimals":0,"isBooleanAmount":true,"creators":["tz1UBZUkXpKGhYsP5KtzDNqLLchwF4uHrGjw"],"tags":["landscape","lake"],"formats":[{"uri":"ipfs://QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco","mimeType":"image/jpeg","fileSize":12345,"dimensions":{"value":"1920x1080","unit":"px"}}],"royalties":{"decimals":4,"shares":{"tz1UBZUkXpKGhYsP5KtzDNqLLchwF4uHrGjw":500}},"attributes":[{"name":"Season","value":"Winter"},{"name":"Edition","value":3,"type":"number"}]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := json.Marshal(test.TZIP21MetaData)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("TZIP21-METADATA: %#v", test.TZIP21MetaData)
+			continue
+		}
+
+		{
+			expected := test.Expected
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("For test #%d, the actual marshaled-json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+
+		{
+			var metadata nftmeta.TZIP21MetaData
+
+			if err := json.Unmarshal(actual, &metadata); nil != err {
+				t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			again, err := json.Marshal(metadata)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if !bytes.Equal(actual, again) {
+				t.Errorf("For test #%d, the re-marshaled json is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", actual)
+				t.Logf("ACTUAL:   %q", again)
+				continue
+			}
+		}
+	}
+}
+
+func TestTZIP21MetaData_Attributes(t *testing.T) {
+
+	var metadata nftmeta.TZIP21MetaData
+	if err := json.Unmarshal([]byte(`{"attributes":[{"name":"Color","value":"Blue","type":"string"}]}`), &metadata); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	attributes := metadata.Attributes()
+	if 1 != len(attributes) {
+		t.Fatalf("Expected 1 attribute but actually got %d.", len(attributes))
+	}
+
+	expected := []byte(`{"display_type":"string","trait_type":"Color","value":"Blue"}`)
+
+	actual, err := json.Marshal(attributes[0])
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("The actual attribute is not what was expected.")
+		t.Logf("EXPECTED: %s", expected)
+		t.Logf("ACTUAL:   %s", actual)
+	}
+}
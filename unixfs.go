@@ -0,0 +1,236 @@
+package nftmeta
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// These are the defaults of `ipfs add` — i.e., --chunker=size-262144 with the balanced layout, where a node has (at most) 174 links.
+const (
+	unixfsChunkSize = 262144
+	unixfsMaxLinks  = 174
+)
+
+// UnixFS data-types.
+const (
+	unixfsTypeRaw       = 0
+	unixfsTypeDirectory = 1
+	unixfsTypeFile      = 2
+)
+
+// ipfsBlock is an IPFS block — i.e., the bytes of a node of a DAG, and its CID.
+type ipfsBlock struct {
+	cid  cid
+	data []byte
+}
+
+// unixfsNode is what a parent node (of a UnixFS DAG) needs to know about a child node.
+type unixfsNode struct {
+	cid      cid
+	tsize    uint64 // the (cumulative) size of the serialized DAG under (and including) the node.
+	filesize uint64 // the size of the file data under the node.
+}
+
+// unixfsOptions are the `ipfs add` options that change the CID.
+type unixfsOptions struct {
+	cidVersion uint64 // --cid-version
+	rawLeaves  bool   // --raw-leaves
+}
+
+func (receiver unixfsOptions) cid(codec uint64, block []byte) cid {
+	digest := sha256.Sum256(block)
+
+	if 0 == receiver.cidVersion {
+		return cid{version: 0, codec: multicodecDagPB, multihash: sha256Multihash(digest[:])}
+	}
+	return cid{version: receiver.cidVersion, codec: codec, multihash: sha256Multihash(digest[:])}
+}
+
+// IPFSCIDv0 returns the CIDv0 that `ipfs add` (with its defaults) gives a file whose content is 'data'.
+//
+// The file is chunked, and built into a dag-pb (UnixFS) DAG, the same way `ipfs add` does it — so this works for files of any size, not only ones that fit into a single chunk.
+func IPFSCIDv0(data []byte) string {
+	return unixfsFile(data, unixfsOptions{cidVersion: 0}, nil).cid.String()
+}
+
+// IPFSCIDv1 returns the CIDv1 that `ipfs add --cid-version=1` gives a file whose content is 'data'.
+//
+// If 'rawLeaves' is true (which is the default of `ipfs add --cid-version=1`), the chunks of the file are raw blocks — so a file that fits into a single chunk has a raw CID (bafkrei…).
+// If 'rawLeaves' is false (i.e., `ipfs add --cid-version=1 --raw-leaves=false`), the chunks are dag-pb (UnixFS) nodes, like they are with a CIDv0.
+func IPFSCIDv1(data []byte, rawLeaves bool) string {
+	return unixfsFile(data, unixfsOptions{cidVersion: 1, rawLeaves: rawLeaves}, nil).cid.String()
+}
+
+// ipfsContentHasCID returns whether 'data' is the content of the (whole) file whose CID is 'str' — i.e., whether `ipfs add` (with its defaults, for the version of the CID) gives 'data' that CID.
+//
+// The content of a file that was added with other options (such as another chunker) does not match, even though it is the content of that CID.
+func ipfsContentHasCID(str string, data []byte) bool {
+	parsed, err := parseCID(str)
+	if nil != err {
+		return false
+	}
+	expected := parsed.String()
+
+	if 0 == parsed.version {
+		return expected == IPFSCIDv0(data)
+	}
+	return expected == IPFSCIDv1(data, true) || expected == IPFSCIDv1(data, false)
+}
+
+// CIDv0 returns the CIDv0 that the JSON of the metadata (see MarshalJSON) has, once added to IPFS.
+//
+// See IPFSCIDv0.
+func (receiver MetaData) CIDv0() (string, error) {
+	data, err := receiver.MarshalJSON()
+	if nil != err {
+		return "", err
+	}
+
+	return IPFSCIDv0(data), nil
+}
+
+// CIDv1 returns the CIDv1 that the JSON of the metadata (see MarshalJSON) has, once added to IPFS.
+//
+// See IPFSCIDv1.
+func (receiver MetaData) CIDv1(rawLeaves bool) (string, error) {
+	data, err := receiver.MarshalJSON()
+	if nil != err {
+		return "", err
+	}
+
+	return IPFSCIDv1(data, rawLeaves), nil
+}
+
+// unixfsFile builds the UnixFS DAG of the file whose content is 'data', passing each block of it to 'emit' (if 'emit' is not nil) — children before parents.
+// It returns the root node.
+func unixfsFile(data []byte, options unixfsOptions, emit func(ipfsBlock)) unixfsNode {
+	if nil == emit {
+		emit = func(ipfsBlock) {}
+	}
+
+	var nodes []unixfsNode
+	for offset := 0; offset < len(data) || 0 == offset; offset += unixfsChunkSize {
+		end := offset + unixfsChunkSize
+		if len(data) < end {
+			end = len(data)
+		}
+
+		nodes = append(nodes, unixfsLeaf(data[offset:end], options, emit))
+
+		if len(data) <= end {
+			break
+		}
+	}
+
+	// Each level of the balanced layout groups the nodes of the level below it, unixfsMaxLinks at a time.
+	for 1 < len(nodes) {
+		var parents []unixfsNode
+		for 0 < len(nodes) {
+			count := unixfsMaxLinks
+			if len(nodes) < count {
+				count = len(nodes)
+			}
+
+			parents = append(parents, unixfsFileParent(nodes[:count], options, emit))
+			nodes = nodes[count:]
+		}
+		nodes = parents
+	}
+
+	return nodes[0]
+}
+
+// unixfsLeaf returns the leaf node for the chunk 'chunk'.
+func unixfsLeaf(chunk []byte, options unixfsOptions, emit func(ipfsBlock)) unixfsNode {
+	if options.rawLeaves {
+		block := ipfsBlock{cid: options.cid(multicodecRaw, chunk), data: chunk}
+		emit(block)
+		return unixfsNode{cid: block.cid, tsize: uint64(len(chunk)), filesize: uint64(len(chunk))}
+	}
+
+	var unixfsData []byte
+	unixfsData = appendProtobufVarint(unixfsData, 1, unixfsTypeFile)
+	if 0 < len(chunk) {
+		unixfsData = appendProtobufBytes(unixfsData, 2, chunk)
+	}
+	unixfsData = appendProtobufVarint(unixfsData, 3, uint64(len(chunk)))
+
+	encoded := dagPBNode(nil, unixfsData)
+
+	block := ipfsBlock{cid: options.cid(multicodecDagPB, encoded), data: encoded}
+	emit(block)
+	return unixfsNode{cid: block.cid, tsize: uint64(len(encoded)), filesize: uint64(len(chunk))}
+}
+
+// unixfsFileParent returns the (internal) file node whose children are 'children'.
+func unixfsFileParent(children []unixfsNode, options unixfsOptions, emit func(ipfsBlock)) unixfsNode {
+	var filesize uint64
+	for _, child := range children {
+		filesize += child.filesize
+	}
+
+	var unixfsData []byte
+	unixfsData = appendProtobufVarint(unixfsData, 1, unixfsTypeFile)
+	unixfsData = appendProtobufVarint(unixfsData, 3, filesize)
+	for _, child := range children {
+		unixfsData = appendProtobufVarint(unixfsData, 4, child.filesize)
+	}
+
+	var links []dagPBLink
+	for _, child := range children {
+		links = append(links, dagPBLink{cid: child.cid, tsize: child.tsize})
+	}
+
+	encoded := dagPBNode(links, unixfsData)
+
+	tsize := uint64(len(encoded))
+	for _, child := range children {
+		tsize += child.tsize
+	}
+
+	block := ipfsBlock{cid: options.cid(multicodecDagPB, encoded), data: encoded}
+	emit(block)
+	return unixfsNode{cid: block.cid, tsize: tsize, filesize: filesize}
+}
+
+// dagPBLink is a PBLink of a dag-pb node.
+type dagPBLink struct {
+	cid   cid
+	name  string
+	tsize uint64
+}
+
+// dagPBNode returns the (canonical) dag-pb encoding of the PBNode with the links 'links' and the data 'data'.
+//
+// Canonically, the links come before the data, even though the data is field 1 and the links are field 2.
+func dagPBNode(links []dagPBLink, data []byte) []byte {
+	var p []byte
+
+	for _, link := range links {
+		var encodedLink []byte
+		encodedLink = appendProtobufBytes(encodedLink, 1, link.cid.bytes())
+		encodedLink = appendProtobufBytes(encodedLink, 2, []byte(link.name))
+		encodedLink = appendProtobufVarint(encodedLink, 3, link.tsize)
+
+		p = appendProtobufBytes(p, 2, encodedLink)
+	}
+
+	if nil != data {
+		p = appendProtobufBytes(p, 1, data)
+	}
+
+	return p
+}
+
+// appendProtobufVarint appends the protobuf varint field numbered 'field'.
+func appendProtobufVarint(p []byte, field uint64, value uint64) []byte {
+	p = binary.AppendUvarint(p, field<<3|0)
+	return binary.AppendUvarint(p, value)
+}
+
+// appendProtobufBytes appends the protobuf length-delimited field numbered 'field'.
+func appendProtobufBytes(p []byte, field uint64, value []byte) []byte {
+	p = binary.AppendUvarint(p, field<<3|2)
+	p = binary.AppendUvarint(p, uint64(len(value)))
+	return append(p, value...)
+}
@@ -0,0 +1,118 @@
+package nftmeta_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+// pseudoRandomBytes returns the same bytes as the `random` command of github.com/jbenet/go-random — which the tests of `ipfs add` (in kubo) use to make their big files.
+func pseudoRandomBytes(count int, seed int64) []byte {
+	random := rand.New(rand.NewSource(seed))
+
+	p := make([]byte, count)
+	for index := 0; index < count; {
+		n := random.Uint32()
+		for j := 0; j < 4 && index < count; j++ {
+			p[index] = byte(n)
+			n >>= 8
+			index++
+		}
+	}
+	return p
+}
+
+func TestIPFSCID(t *testing.T) {
+
+	// 5 MiB is 20 chunks, so this is a multi-chunk file.
+	bigFile := pseudoRandomBytes(5242880, 41)
+
+	tests := []struct{
+		Data []byte
+		ExpectedCIDv0          string
+		ExpectedCIDv1RawLeaves string
+		ExpectedCIDv1          string
+	}{
+		{
+			Data:                   []byte{},
+			ExpectedCIDv0:          "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH",
+			ExpectedCIDv1RawLeaves: "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+			ExpectedCIDv1:          "bafybeif7ztnhq65lumvvtr4ekcwd2ifwgm3awq4zfr3srh462rwyinlb4y",
+		},
+		{
+			Data:                   []byte("hello world\n"),
+			ExpectedCIDv0:          "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
+			ExpectedCIDv1RawLeaves: "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4",
+			ExpectedCIDv1:          "bafybeicg2rebjoofv4kbyovkw7af3rpiitvnl6i7ckcywaq6xjcxnc2mby",
+		},
+		{
+			Data:                   bigFile,
+			ExpectedCIDv0:          "QmSr7FqYkxYWGoSfy8ZiaMWQ5vosb18DQGCzjwEQnVHkTb",
+			ExpectedCIDv1RawLeaves: "bafybeigfnx3tka2rf5ovv2slb7ymrt4zbwa3ryeqibe6fipyt5vgsrli3u",
+			ExpectedCIDv1:          "bafybeieyifrgpjn3yengthr7qaj72ozm2aq3wm53srgeprc43w67qpvfqa",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		if expected, actual := test.ExpectedCIDv0, nftmeta.IPFSCIDv0(test.Data); expected != actual {
+			t.Errorf("For test #%d, the actual CIDv0 is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := test.ExpectedCIDv1RawLeaves, nftmeta.IPFSCIDv1(test.Data, true); expected != actual {
+			t.Errorf("For test #%d, the actual CIDv1 (with raw leaves) is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := test.ExpectedCIDv1, nftmeta.IPFSCIDv1(test.Data, false); expected != actual {
+			t.Errorf("For test #%d, the actual CIDv1 (without raw leaves) is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestMetaData_CIDv0(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("Dave Starbelly")
+	metadata.SetImage("https://storage.googleapis.com/opensea-prod.appspot.com/puffs/3.png")
+
+	data, err := metadata.MarshalJSON()
+	if nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	{
+		actual, err := metadata.CIDv0()
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if expected := nftmeta.IPFSCIDv0(data); expected != actual {
+			t.Errorf("The actual CIDv0 is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+
+	{
+		actual, err := metadata.CIDv1(true)
+		if nil != err {
+			t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+		}
+
+		if expected := nftmeta.IPFSCIDv1(data, true); expected != actual {
+			t.Errorf("The actual CIDv1 is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+}
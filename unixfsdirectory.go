@@ -0,0 +1,318 @@
+package nftmeta
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// These are the defaults of `ipfs add -r` for directories — a directory becomes a HAMT-sharded directory once its (estimated) size is bigger than 256 KiB, and each HAMT shard has (up to) 256 children.
+const (
+	unixfsHAMTShardingSize = 262144
+	unixfsHAMTFanout       = 256
+)
+
+const unixfsTypeHAMTShard = 5
+
+// multihashMurmur3X64_64 is the multihash code of the hash that HAMT-sharded directories use to hash names.
+const multihashMurmur3X64_64 = 0x22
+
+// unixfsDirectoryEntry is an entry (i.e., a file or a directory) of a UnixFS directory.
+type unixfsDirectoryEntry struct {
+	name string
+	node unixfsNode
+}
+
+// IPFSDirectoryCIDv0 returns the CIDv0 that `ipfs add -r` (with its defaults) gives a directory of the files 'files' — which is keyed by file name.
+//
+// A directory that is big enough is HAMT-sharded, the same way `ipfs add -r` does it.
+func IPFSDirectoryCIDv0(files map[string][]byte) (string, error) {
+	node, err := unixfsDirectoryOfFiles(files, unixfsOptions{cidVersion: 0}, nil)
+	if nil != err {
+		return "", err
+	}
+
+	return node.cid.String(), nil
+}
+
+// IPFSDirectoryCIDv1 returns the CIDv1 that `ipfs add -r --cid-version=1` gives a directory of the files 'files' — which is keyed by file name.
+//
+// See IPFSCIDv1 for what 'rawLeaves' does.
+func IPFSDirectoryCIDv1(files map[string][]byte, rawLeaves bool) (string, error) {
+	node, err := unixfsDirectoryOfFiles(files, unixfsOptions{cidVersion: 1, rawLeaves: rawLeaves}, nil)
+	if nil != err {
+		return "", err
+	}
+
+	return node.cid.String(), nil
+}
+
+// unixfsDirectoryOfFiles builds the UnixFS DAG of a directory of the files 'files', passing each block of it to 'emit' (if 'emit' is not nil).
+// It returns the root node.
+//
+// The files are built in the order of their names — so that the blocks are passed to 'emit' in the same order each time (and so, for example, a CAR file of the directory is reproducible).
+func unixfsDirectoryOfFiles(files map[string][]byte, options unixfsOptions, emit func(ipfsBlock)) (unixfsNode, error) {
+	var names []string
+	for name := range files {
+		if err := validateUnixFSName(name); nil != err {
+			return unixfsNode{}, err
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []unixfsDirectoryEntry
+	for _, name := range names {
+		entries = append(entries, unixfsDirectoryEntry{
+			name: name,
+			node: unixfsFile(files[name], options, emit),
+		})
+	}
+
+	return unixfsDirectory(entries, options, emit)
+}
+
+// validateUnixFSName returns an error if 'name' cannot be the name of an entry of a UnixFS directory.
+func validateUnixFSName(name string) error {
+	switch {
+	case "" == name:
+		return errFileNameEmpty
+	case "." == name || ".." == name:
+		return erorr.Errorf("nftmeta: %q cannot be a file name", name)
+	case strings.ContainsRune(name, '/'):
+		return erorr.Errorf("nftmeta: file name %q cannot have a '/' in it", name)
+	}
+	return nil
+}
+
+// unixfsDirectory builds the UnixFS directory of the entries 'entries' (whose own DAGs must have been built already), passing each block of it to 'emit' (if 'emit' is not nil).
+// It returns the root node.
+func unixfsDirectory(entries []unixfsDirectoryEntry, options unixfsOptions, emit func(ipfsBlock)) (unixfsNode, error) {
+	if nil == emit {
+		emit = func(ipfsBlock) {}
+	}
+
+	entries = append([]unixfsDirectoryEntry(nil), entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	// `ipfs add -r` estimates the size of a directory as the sum of the lengths of the names and the (binary) CIDs of its entries.
+	var estimatedSize int
+	for index, entry := range entries {
+		if 0 < index && entries[index-1].name == entry.name {
+			return unixfsNode{}, erorr.Errorf("nftmeta: directory has more than one entry named %q", entry.name)
+		}
+
+		estimatedSize += len(entry.name) + len(entry.node.cid.bytes())
+	}
+
+	if unixfsHAMTShardingSize < estimatedSize {
+		return unixfsHAMTDirectory(entries, options, emit)
+	}
+
+	var links []dagPBLink
+	var tsize uint64
+	for _, entry := range entries {
+		links = append(links, dagPBLink{cid: entry.node.cid, name: entry.name, tsize: entry.node.tsize})
+		tsize += entry.node.tsize
+	}
+
+	encoded := dagPBNode(links, appendProtobufVarint(nil, 1, unixfsTypeDirectory))
+	tsize += uint64(len(encoded))
+
+	block := ipfsBlock{cid: options.cid(multicodecDagPB, encoded), data: encoded}
+	emit(block)
+	return unixfsNode{cid: block.cid, tsize: tsize}, nil
+}
+
+// hamtShard is a shard of a HAMT-sharded directory.
+// Each of its children is either an entry of the directory, or another shard.
+type hamtShard struct {
+	depth    int
+	children map[int]hamtChild
+}
+
+type hamtChild struct {
+	shard *hamtShard
+	hash  []byte
+	entry unixfsDirectoryEntry
+}
+
+// unixfsHAMTDirectory is like unixfsDirectory, but builds a HAMT-sharded directory.
+func unixfsHAMTDirectory(entries []unixfsDirectoryEntry, options unixfsOptions, emit func(ipfsBlock)) (unixfsNode, error) {
+	root := hamtShard{children: map[int]hamtChild{}}
+
+	for _, entry := range entries {
+		var hash [8]byte
+		binary.BigEndian.PutUint64(hash[:], murmur3X64_64([]byte(entry.name)))
+
+		if err := root.add(hamtChild{hash: hash[:], entry: entry}); nil != err {
+			return unixfsNode{}, err
+		}
+	}
+
+	return root.build(options, emit), nil
+}
+
+// add adds the entry 'child' — putting it under the index that is the next 8 bits (since the fanout is 256) of its hash.
+// If another entry already is at that index, the two of them get moved into a new shard.
+func (receiver *hamtShard) add(child hamtChild) error {
+	if len(child.hash) <= receiver.depth {
+		return erorr.Errorf("nftmeta: the HAMT-sharded directory is too deep to add %q", child.entry.name)
+	}
+	index := int(child.hash[receiver.depth])
+
+	existing, found := receiver.children[index]
+	switch {
+	case !found:
+		receiver.children[index] = child
+		return nil
+	case nil != existing.shard:
+		return existing.shard.add(child)
+	}
+
+	shard := &hamtShard{depth: receiver.depth + 1, children: map[int]hamtChild{}}
+	if err := shard.add(existing); nil != err {
+		return err
+	}
+	if err := shard.add(child); nil != err {
+		return err
+	}
+	receiver.children[index] = hamtChild{shard: shard}
+	return nil
+}
+
+// build builds the shard (and the shards under it), passing each block to 'emit'.
+func (receiver *hamtShard) build(options unixfsOptions, emit func(ipfsBlock)) unixfsNode {
+	var indexes []int
+	for index := range receiver.children {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	// The bitmap of which indexes are used is big-endian, and without any leading zero bytes.
+	var bitmap [unixfsHAMTFanout / 8]byte
+	for _, index := range indexes {
+		bitmap[len(bitmap)-1-index/8] |= 1 << (index % 8)
+	}
+	trimmedBitmap := bitmap[:]
+	for 0 < len(trimmedBitmap) && 0 == trimmedBitmap[0] {
+		trimmedBitmap = trimmedBitmap[1:]
+	}
+
+	var links []dagPBLink
+	var tsize uint64
+	for _, index := range indexes {
+		child := receiver.children[index]
+
+		// A link name is the index (as upper-case hex) followed by the name of the entry — or, for a shard, just the index.
+		prefix := fmt.Sprintf("%02X", index)
+
+		var link dagPBLink
+		if nil != child.shard {
+			node := child.shard.build(options, emit)
+			link = dagPBLink{cid: node.cid, name: prefix, tsize: node.tsize}
+		} else {
+			link = dagPBLink{cid: child.entry.node.cid, name: prefix + child.entry.name, tsize: child.entry.node.tsize}
+		}
+
+		links = append(links, link)
+		tsize += link.tsize
+	}
+
+	var unixfsData []byte
+	unixfsData = appendProtobufVarint(unixfsData, 1, unixfsTypeHAMTShard)
+	unixfsData = appendProtobufBytes(unixfsData, 2, trimmedBitmap)
+	unixfsData = appendProtobufVarint(unixfsData, 5, multihashMurmur3X64_64)
+	unixfsData = appendProtobufVarint(unixfsData, 6, unixfsHAMTFanout)
+
+	encoded := dagPBNode(links, unixfsData)
+	tsize += uint64(len(encoded))
+
+	block := ipfsBlock{cid: options.cid(multicodecDagPB, encoded), data: encoded}
+	emit(block)
+	return unixfsNode{cid: block.cid, tsize: tsize}
+}
+
+// murmur3X64_64 returns the first 64 bits (i.e., h1) of the x64 128-bit MurmurHash3 (with a seed of 0) of 'data'.
+func murmur3X64_64(data []byte) uint64 {
+	const (
+		c1 = 0x87c37b91114253d5
+		c2 = 0x4cf5ad432745937f
+	)
+
+	var h1, h2 uint64
+
+	length := len(data)
+	for ; 16 <= len(data); data = data[16:] {
+		k1 := binary.LittleEndian.Uint64(data[0:8])
+		k2 := binary.LittleEndian.Uint64(data[8:16])
+
+		k1 *= c1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+
+		k2 *= c2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	// The tail.
+	{
+		var tail [16]byte
+		copy(tail[:], data)
+		k1 := binary.LittleEndian.Uint64(tail[0:8])
+		k2 := binary.LittleEndian.Uint64(tail[8:16])
+
+		if 8 < len(data) {
+			k2 *= c2
+			k2 = bits.RotateLeft64(k2, 33)
+			k2 *= c1
+			h2 ^= k2
+		}
+		if 0 < len(data) {
+			k1 *= c1
+			k1 = bits.RotateLeft64(k1, 31)
+			k1 *= c2
+			h1 ^= k1
+		}
+	}
+
+	h1 ^= uint64(length)
+	h2 ^= uint64(length)
+
+	h1 += h2
+	h2 += h1
+
+	h1 = murmur3FMix64(h1)
+	h2 = murmur3FMix64(h2)
+
+	h1 += h2
+
+	return h1
+}
+
+func murmur3FMix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
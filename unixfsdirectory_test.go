@@ -0,0 +1,114 @@
+package nftmeta_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestIPFSDirectoryCID(t *testing.T) {
+
+	// These long names make a directory big enough to be HAMT-sharded.
+	// For CIDv1 — with 1344 files it is, with 1343 files it is not. For CIDv0 (whose CIDs are 2 bytes shorter) — with 1357 files it is, with 1356 files it is not.
+	//
+	// The CIDv0s were cross-checked with a separate (Python) implementation, which gives the kubo CIDs of the empty directory and of single files, and the CIDv1s here.
+	longNamedFiles := func(count int) map[string][]byte {
+		var files = map[string][]byte{}
+		for i := 0; i < count; i++ {
+			name := fmt.Sprintf("long name to fill out bytes to make the sharded directory test flip over the sharded directory limit because link names are included in the directory entry %d", i)
+			files[name] = []byte(name)
+		}
+		return files
+	}
+
+	tests := []struct{
+		Files map[string][]byte
+		ExpectedCIDv0          string
+		ExpectedCIDv1RawLeaves string
+	}{
+		{
+			Files:                  map[string][]byte{},
+			ExpectedCIDv0:          "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn",
+			ExpectedCIDv1RawLeaves: "bafybeiczsscdsbs7ffqz55asqdf3smv6klcw3gofszvwlyarci47bgf354",
+		},
+		{
+			Files: map[string][]byte{
+				"1": []byte("111"),
+				"2": []byte("222"),
+			},
+			ExpectedCIDv0:          "QmcGkboq1EnLSA2kweS5LV8Gk2H9DvgCtUgYt4fpmUF8s7",
+			ExpectedCIDv1RawLeaves: "bafybeibohj54uixf2mso4t53suyarv6cfuxt6b5cj6qjsqaa2ezfxnu5pu",
+		},
+		{
+			Files:                  longNamedFiles(1343),
+			ExpectedCIDv0:          "QmdxLLEMKWrLU19aebBvVJY7QRSnaT9BHHmHMkJy5V6LmC",
+			ExpectedCIDv1RawLeaves: "bafybeihecq4rpl4nw3cgfb2uiwltgsmw5sutouvuldv5fxn4gfbihvnalq",
+		},
+		{
+			Files:                  longNamedFiles(1344),
+			ExpectedCIDv0:          "QmRf5WqG6Wu4Mi6Z2kjKu8ShvcTzu8uwCev427Gh72dEFw",
+			ExpectedCIDv1RawLeaves: "bafybeigyvxs6og5jbmpaa43qbhhd5swklqcfzqdrtjgfh53qjon6hpjaye",
+		},
+		{
+			Files:                  longNamedFiles(1357),
+			ExpectedCIDv0:          "QmPe1fnNzkbuWuAqGSsrHPvJd8wyCYsKWmUJ9mMsXr6gMc",
+			ExpectedCIDv1RawLeaves: "bafybeich3lazjj3rsyy5yatnftfykxhwx4j6t4zchwp7jp4vkmqa3yrhvi",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		{
+			actual, err := nftmeta.IPFSDirectoryCIDv0(test.Files)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected := test.ExpectedCIDv0; expected != actual {
+				t.Errorf("For test #%d, the actual CIDv0 is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+
+		{
+			actual, err := nftmeta.IPFSDirectoryCIDv1(test.Files, true)
+			if nil != err {
+				t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+				t.Logf("ERROR: (%T) %s", err, err)
+				continue
+			}
+
+			if expected := test.ExpectedCIDv1RawLeaves; expected != actual {
+				t.Errorf("For test #%d, the actual CIDv1 (with raw leaves) is not what was expected.", testNumber)
+				t.Logf("EXPECTED: %q", expected)
+				t.Logf("ACTUAL:   %q", actual)
+				continue
+			}
+		}
+	}
+}
+
+func TestIPFSDirectoryCID_badFileName(t *testing.T) {
+
+	tests := []string{
+		"",
+		".",
+		"..",
+		"a/b",
+	}
+
+	for testNumber, name := range tests {
+
+		_, err := nftmeta.IPFSDirectoryCIDv0(map[string][]byte{name: []byte("x")})
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("NAME: %q", name)
+			continue
+		}
+	}
+}
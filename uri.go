@@ -0,0 +1,370 @@
+package nftmeta
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// URIKind is what kind of content a URI points to.
+type URIKind int
+
+const (
+	// URIKindOther is a URI that is not one of the others — usually an ordinary http or https URL.
+	URIKindOther URIKind = iota
+
+	// URIKindIPFS is IPFS content — whether as ipfs://<cid>, or as an IPFS gateway URL.
+	URIKindIPFS
+
+	// URIKindArweave is Arweave content — ar://<txid>.
+	URIKindArweave
+
+	// URIKindData is content inside of the URI itself — data:….
+	URIKindData
+)
+
+func (receiver URIKind) String() string {
+	switch receiver {
+	case URIKindOther:
+		return "other"
+	case URIKindIPFS:
+		return "ipfs"
+	case URIKindArweave:
+		return "arweave"
+	case URIKindData:
+		return "data"
+	default:
+		return "unknown"
+	}
+}
+
+// URI is a parsed URI, of the kind that is found in the URI fields of NFT metadata ("image", "animation_url", "external_link", "youtube_url"), and in tokenURI's.
+//
+// These are understood:
+//
+//	ipfs://<cid>/<path>
+//	ipfs://ipfs/<cid>/<path>            (a common mistake, which is fixed)
+//	https://<gateway>/ipfs/<cid>/<path> (an IPFS path-gateway URL)
+//	https://<cid>.ipfs.<gateway>/<path> (an IPFS subdomain-gateway URL)
+//	ar://<txid>/<path>
+//	data:<media-type>;base64,<data>
+//
+// Anything else (such as an ordinary https URL) is URIKindOther, and is left as it is.
+type URI struct {
+	kind URIKind
+	raw  string
+	id   string // the CID (for IPFS) or the transaction-id (for Arweave).
+	path string // what comes after the CID or transaction-id (including any query and fragment).
+}
+
+// arweaveTxIDLength is the length of an Arweave transaction-id — the (unpadded) base64url of 32 bytes.
+const arweaveTxIDLength = 43
+
+// ParseURI parses 'str' as a URI.
+//
+// It returns an error if 'str' is an ipfs:, ar:, or data: URI (or an IPFS gateway URL) that is malformed — for example, one with a bad CID — or if 'str' is not a URI at all.
+func ParseURI(str string) (URI, error) {
+	str = strings.TrimSpace(str)
+	if "" == str {
+		return URI{}, errURIEmpty
+	}
+
+	scheme, rest, found := strings.Cut(str, ":")
+	if !found {
+		return URI{}, erorr.Errorf("nftmeta: %q is not a URI (it has no scheme)", str)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "ipfs":
+		rest = strings.TrimPrefix(rest, "//")
+		// The common mistake of ipfs://ipfs/<cid>.
+		rest = strings.TrimPrefix(rest, "ipfs/")
+
+		return parseIPFSURI(str, rest)
+	case "ar":
+		rest = strings.TrimPrefix(rest, "//")
+
+		txID, path := cutURIPath(rest)
+		if arweaveTxIDLength != len(txID) {
+			return URI{}, erorr.Errorf("nftmeta: %q does not have a valid Arweave transaction-id", str)
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(txID); nil != err {
+			return URI{}, erorr.Errorf("nftmeta: %q does not have a valid Arweave transaction-id: %w", str, err)
+		}
+
+		return URI{kind: URIKindArweave, raw: str, id: txID, path: path}, nil
+	case "data":
+		if !strings.Contains(rest, ",") {
+			return URI{}, erorr.Errorf("nftmeta: data URI %q is missing its ','", truncateForError(str))
+		}
+
+		return URI{kind: URIKindData, raw: str}, nil
+	case "http", "https":
+		parsed, err := url.Parse(str)
+		if nil != err {
+			return URI{}, erorr.Errorf("nftmeta: problem parsing URL %q: %w", str, err)
+		}
+
+		// A path-gateway URL — https://<gateway>/ipfs/<cid>/<path>.
+		if after, found := strings.CutPrefix(parsed.EscapedPath(), "/ipfs/"); found {
+			uri, err := parseIPFSURI(str, after+uriQueryAndFragment(parsed))
+			if nil == err {
+				return uri, nil
+			}
+		}
+
+		// A subdomain-gateway URL — https://<cid>.ipfs.<gateway>/<path>.
+		if label, after, found := strings.Cut(parsed.Hostname(), "."); found && strings.HasPrefix(after, "ipfs.") {
+			if _, err := parseCID(label); nil == err {
+				return URI{kind: URIKindIPFS, raw: str, id: label, path: parsed.EscapedPath() + uriQueryAndFragment(parsed)}, nil
+			}
+		}
+
+		return URI{kind: URIKindOther, raw: str}, nil
+	default:
+		return URI{kind: URIKindOther, raw: str}, nil
+	}
+}
+
+// parseIPFSURI parses 'rest' — i.e., <cid>/<path> — of the IPFS URI (or gateway URL) 'str'.
+func parseIPFSURI(str string, rest string) (URI, error) {
+	id, path := cutURIPath(rest)
+
+	// A (base32) CIDv1 is case-insensitive — "B" is the multibase prefix of upper-case base32 — but canonically lower-case.
+	if !strings.HasPrefix(id, "Qm") {
+		id = strings.ToLower(id)
+	}
+
+	if _, err := parseCID(id); nil != err {
+		return URI{}, erorr.Errorf("nftmeta: %q does not have a valid CID: %w", str, err)
+	}
+
+	return URI{kind: URIKindIPFS, raw: str, id: id, path: path}, nil
+}
+
+// cutURIPath cuts 'str' into what comes before a '/', '?', or '#' — and the rest.
+func cutURIPath(str string) (string, string) {
+	index := strings.IndexAny(str, "/?#")
+	if index < 0 {
+		return str, ""
+	}
+	return str[:index], str[index:]
+}
+
+func uriQueryAndFragment(parsed *url.URL) string {
+	var str string
+	if "" != parsed.RawQuery || parsed.ForceQuery {
+		str += "?" + parsed.RawQuery
+	}
+	if "" != parsed.Fragment {
+		str += "#" + parsed.EscapedFragment()
+	}
+	return str
+}
+
+// truncateForError returns 'str' — shortened, if it is long (as data URIs can be).
+func truncateForError(str string) string {
+	const max = 64
+	if len(str) <= max {
+		return str
+	}
+	return str[:max] + "…"
+}
+
+// Kind returns what kind of content the URI points to.
+func (receiver URI) Kind() URIKind {
+	return receiver.kind
+}
+
+// CID returns the CID of an IPFS URI — or "" for any other kind of URI.
+func (receiver URI) CID() string {
+	if URIKindIPFS != receiver.kind {
+		return ""
+	}
+	return receiver.id
+}
+
+// ArweaveTxID returns the transaction-id of an Arweave URI — or "" for any other kind of URI.
+func (receiver URI) ArweaveTxID() string {
+	if URIKindArweave != receiver.kind {
+		return ""
+	}
+	return receiver.id
+}
+
+// Path returns what comes after the CID (of an IPFS URI) or the transaction-id (of an Arweave URI) — including any query and fragment.
+// For example, the path of ipfs://<cid>/1.json is "/1.json".
+func (receiver URI) Path() string {
+	return receiver.path
+}
+
+// DataMediaType returns the media-type of a data URI — which is "text/plain;charset=US-ASCII" if the data URI does not have one — or "" for any other kind of URI.
+func (receiver URI) DataMediaType() string {
+	if URIKindData != receiver.kind {
+		return ""
+	}
+
+	header, _, _ := strings.Cut(receiver.raw[len("data:"):], ",")
+	header = strings.TrimSuffix(header, ";base64")
+	if "" == header {
+		return "text/plain;charset=US-ASCII"
+	}
+	return header
+}
+
+// Data returns the (decoded) content of a data URI.
+func (receiver URI) Data() ([]byte, error) {
+	if URIKindData != receiver.kind {
+		return nil, erorr.Errorf("nftmeta: %q is not a data URI", truncateForError(receiver.raw))
+	}
+
+	header, data, _ := strings.Cut(receiver.raw[len("data:"):], ",")
+
+	if strings.HasSuffix(header, ";base64") {
+		data, err := url.PathUnescape(data)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem decoding data URI: %w", err)
+		}
+
+		// Some data URIs in the wild use base64url, or leave off the padding.
+		data = strings.TrimRight(data, "=")
+		if strings.ContainsAny(data, "-_") {
+			return decodeBase64(base64.RawURLEncoding, data)
+		}
+		return decodeBase64(base64.RawStdEncoding, data)
+	}
+
+	unescaped, err := url.PathUnescape(data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem decoding data URI: %w", err)
+	}
+	return []byte(unescaped), nil
+}
+
+func decodeBase64(encoding *base64.Encoding, data string) ([]byte, error) {
+	p, err := encoding.DecodeString(data)
+	if nil != err {
+		return nil, erorr.Errorf("nftmeta: problem base64-decoding data URI: %w", err)
+	}
+	return p, nil
+}
+
+// String returns the canonical form of the URI:
+//
+//	ipfs://<cid>/<path>  (for IPFS — even if it was parsed from an IPFS gateway URL)
+//	ar://<txid>/<path>   (for Arweave)
+//
+// Any other kind of URI is returned as it was parsed.
+func (receiver URI) String() string {
+	switch receiver.kind {
+	case URIKindIPFS:
+		return "ipfs://" + receiver.id + receiver.path
+	case URIKindArweave:
+		return "ar://" + receiver.id + receiver.path
+	default:
+		return receiver.raw
+	}
+}
+
+// Gateway says how IPFS and Arweave URIs are rewritten into HTTP(S) URLs. See URI.Rewrite.
+//
+// For example:
+//
+//	nftmeta.Gateway{IPFS: "https://ipfs.io", Arweave: "https://arweave.net"}
+type Gateway struct {
+	// IPFS is the base URL of an IPFS gateway — for example, "https://ipfs.io".
+	// If it is "", IPFS URIs are not rewritten.
+	IPFS string
+
+	// IPFSSubdomain is true if the IPFS gateway is a subdomain-gateway — so, for example, ipfs://<cid> is rewritten as https://<cidv1>.ipfs.dweb.link rather than as https://dweb.link/ipfs/<cid>.
+	IPFSSubdomain bool
+
+	// Arweave is the base URL of an Arweave gateway — for example, "https://arweave.net".
+	// If it is "", Arweave URIs are not rewritten.
+	Arweave string
+}
+
+// Rewrite returns the URI as a URL of the gateway 'gateway' — if it is an IPFS or Arweave URI, and 'gateway' has a gateway for it.
+// Otherwise, it returns the canonical form of the URI (see String).
+//
+// An IPFS gateway URL (of any gateway) is rewritten to be a URL of 'gateway'.
+func (receiver URI) Rewrite(gateway Gateway) (string, error) {
+	switch {
+	case URIKindIPFS == receiver.kind && "" != gateway.IPFS:
+		if !gateway.IPFSSubdomain {
+			return strings.TrimSuffix(gateway.IPFS, "/") + "/ipfs/" + receiver.id + receiver.path, nil
+		}
+
+		base, err := url.Parse(gateway.IPFS)
+		if nil != err || "" == base.Host {
+			return "", erorr.Errorf("nftmeta: IPFS gateway %q is not a valid URL", gateway.IPFS)
+		}
+
+		// A subdomain (i.e., DNS label) is case-insensitive, so it needs a (base32) CIDv1.
+		parsed, err := parseCID(receiver.id)
+		if nil != err {
+			return "", err
+		}
+		parsed.version = 1
+
+		return base.Scheme + "://" + parsed.String() + ".ipfs." + base.Host + receiver.path, nil
+	case URIKindArweave == receiver.kind && "" != gateway.Arweave:
+		return strings.TrimSuffix(gateway.Arweave, "/") + "/" + receiver.id + receiver.path, nil
+	default:
+		return receiver.String(), nil
+	}
+}
+
+// NormalizeURIs replaces each of the URI fields of the metadata ("image", "animation_url", "external_link", "youtube_url") with its canonical form (see URI.String).
+//
+// A field that cannot be parsed as a URI is left as it is.
+func (receiver *MetaData) NormalizeURIs() {
+	receiver.mapURIs(func(uri URI) (string, error) {
+		return uri.String(), nil
+	})
+}
+
+// RewriteURIs replaces each of the URI fields of the metadata ("image", "animation_url", "external_link", "youtube_url") with it rewritten to the gateway 'gateway' (see URI.Rewrite).
+//
+// A field that cannot be parsed as a URI is left as it is.
+func (receiver *MetaData) RewriteURIs(gateway Gateway) error {
+	return receiver.mapURIs(func(uri URI) (string, error) {
+		return uri.Rewrite(gateway)
+	})
+}
+
+func (receiver *MetaData) mapURIs(fn func(URI) (string, error)) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	for _, field := range []struct{
+		get func() (string, bool)
+		set func(string)
+	}{
+		{receiver.animationURL.Get, receiver.SetAnimationURL},
+		{receiver.externalLink.Get, receiver.SetExternalLink},
+		{receiver.image.Get,        receiver.SetImage},
+		{receiver.youtubeURL.Get,   receiver.SetYouTubeURL},
+	}{
+		value, something := field.get()
+		if !something {
+			continue
+		}
+
+		uri, err := ParseURI(value)
+		if nil != err {
+			continue
+		}
+
+		rewritten, err := fn(uri)
+		if nil != err {
+			return err
+		}
+		field.set(rewritten)
+	}
+
+	return nil
+}
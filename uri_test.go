@@ -0,0 +1,321 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestParseURI(t *testing.T) {
+
+	tests := []struct{
+		URI string
+		ExpectedKind      nftmeta.URIKind
+		ExpectedCanonical string
+		ExpectedCID       string
+		ExpectedPath      string
+	}{
+		{
+			URI:               "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedCID:       "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedPath:      "",
+		},
+		{
+			URI:               "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			ExpectedCID:       "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedPath:      "/1.json",
+		},
+		{
+			URI:               "ipfs://ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			ExpectedCID:       "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedPath:      "/1.json",
+		},
+		{
+			URI:               "ipfs://BAFYBEIGDYRZT5SFP7UDM7HU76UH7Y26NF3EFUYLQABF3OCLGTQY55FBZDI",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+			ExpectedCID:       "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+			ExpectedPath:      "",
+		},
+		{
+			URI:               "https://ipfs.io/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/images/1.png?download=true",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/images/1.png?download=true",
+			ExpectedCID:       "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR",
+			ExpectedPath:      "/images/1.png?download=true",
+		},
+		{
+			URI:               "https://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi.ipfs.dweb.link/1.json",
+			ExpectedKind:      nftmeta.URIKindIPFS,
+			ExpectedCanonical: "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.json",
+			ExpectedCID:       "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+			ExpectedPath:      "/1.json",
+		},
+		{
+			URI:               "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+			ExpectedKind:      nftmeta.URIKindArweave,
+			ExpectedCanonical: "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+			ExpectedPath:      "/0.json",
+		},
+		{
+			URI:               "data:application/json;base64,eyJuYW1lIjoieCJ9",
+			ExpectedKind:      nftmeta.URIKindData,
+			ExpectedCanonical: "data:application/json;base64,eyJuYW1lIjoieCJ9",
+		},
+		{
+			URI:               "https://example.com/token/1",
+			ExpectedKind:      nftmeta.URIKindOther,
+			ExpectedCanonical: "https://example.com/token/1",
+		},
+		{
+			// Not an IPFS path, since "ipfs" is not a CID.
+			URI:               "https://example.com/ipfs/ipfs",
+			ExpectedKind:      nftmeta.URIKindOther,
+			ExpectedCanonical: "https://example.com/ipfs/ipfs",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		uri, err := nftmeta.ParseURI(test.URI)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			t.Logf("URI: %q", test.URI)
+			continue
+		}
+
+		if expected, actual := test.ExpectedKind, uri.Kind(); expected != actual {
+			t.Errorf("For test #%d, the actual kind is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			t.Logf("URI: %q", test.URI)
+			continue
+		}
+
+		if expected, actual := test.ExpectedCanonical, uri.String(); expected != actual {
+			t.Errorf("For test #%d, the actual canonical form is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := test.ExpectedCID, uri.CID(); expected != actual {
+			t.Errorf("For test #%d, the actual CID is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		if expected, actual := test.ExpectedPath, uri.Path(); expected != actual {
+			t.Errorf("For test #%d, the actual path is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestParseURI_bad(t *testing.T) {
+
+	tests := []string{
+		"",
+		"not a uri",
+		"ipfs://not-a-cid/1.json",
+		"ar://too-short",
+		"data:no-comma",
+	}
+
+	for testNumber, str := range tests {
+
+		_, err := nftmeta.ParseURI(str)
+		if nil == err {
+			t.Errorf("For test #%d, expected an error but did not actually get one.", testNumber)
+			t.Logf("URI: %q", str)
+			continue
+		}
+	}
+}
+
+func TestURI_Data(t *testing.T) {
+
+	tests := []struct{
+		URI string
+		ExpectedMediaType string
+		ExpectedData      string
+	}{
+		{
+			URI:               "data:application/json;base64,eyJuYW1lIjoieCJ9",
+			ExpectedMediaType: "application/json",
+			ExpectedData:      `{"name":"x"}`,
+		},
+		{
+			URI:               "data:application/json;utf8,%7B%22name%22%3A%22x%22%7D",
+			ExpectedMediaType: "application/json;utf8",
+			ExpectedData:      `{"name":"x"}`,
+		},
+		{
+			URI:               "data:,hello%20world",
+			ExpectedMediaType: "text/plain;charset=US-ASCII",
+			ExpectedData:      "hello world",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		uri, err := nftmeta.ParseURI(test.URI)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.ExpectedMediaType, uri.DataMediaType(); expected != actual {
+			t.Errorf("For test #%d, the actual media-type is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		data, err := uri.Data()
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.ExpectedData, string(data); expected != actual {
+			t.Errorf("For test #%d, the actual data is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestURI_Rewrite(t *testing.T) {
+
+	pathGateway := nftmeta.Gateway{IPFS: "https://cloudflare-ipfs.com/", Arweave: "https://arweave.net"}
+	subdomainGateway := nftmeta.Gateway{IPFS: "https://dweb.link", IPFSSubdomain: true}
+
+	tests := []struct{
+		URI string
+		Gateway nftmeta.Gateway
+		Expected string
+	}{
+		{
+			URI:      "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			Gateway:  pathGateway,
+			Expected: "https://cloudflare-ipfs.com/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+		},
+		{
+			URI:      "https://ipfs.io/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			Gateway:  pathGateway,
+			Expected: "https://cloudflare-ipfs.com/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+		},
+		{
+			// A CIDv0 becomes a CIDv1 for a subdomain-gateway.
+			URI:      "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.json",
+			Gateway:  subdomainGateway,
+			Expected: "https://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi.ipfs.dweb.link/1.json",
+		},
+		{
+			URI:      "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+			Gateway:  pathGateway,
+			Expected: "https://arweave.net/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+		},
+		{
+			// No Arweave gateway, so it is left in its canonical form.
+			URI:      "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+			Gateway:  subdomainGateway,
+			Expected: "ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U/0.json",
+		},
+		{
+			URI:      "https://example.com/token/1",
+			Gateway:  pathGateway,
+			Expected: "https://example.com/token/1",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		uri, err := nftmeta.ParseURI(test.URI)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		actual, err := uri.Rewrite(test.Gateway)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, the actual rewritten URI is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+}
+
+func TestMetaData_RewriteURIs(t *testing.T) {
+
+	var metadata nftmeta.MetaData
+	metadata.SetName("ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/not-a-uri-field")
+	metadata.SetImage("ipfs://ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.png")
+	metadata.SetAnimationURL("ar://bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U")
+	metadata.SetExternalLink("https://example.com/1")
+
+	{
+		normalized := metadata
+		normalized.NormalizeURIs()
+
+		if expected, actual := "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.png", normalized.Image().GetElse(""); expected != actual {
+			t.Errorf("The actual normalized image is not what was expected.")
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+		}
+	}
+
+	if err := metadata.RewriteURIs(nftmeta.Gateway{IPFS: "https://ipfs.io", Arweave: "https://arweave.net"}); nil != err {
+		t.Fatalf("Did not expect an error but actually got one: (%T) %s", err, err)
+	}
+
+	for testNumber, test := range []struct{
+		Actual   string
+		Expected string
+	}{
+		{
+			Actual:   metadata.Name().GetElse(""),
+			Expected: "ipfs://QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/not-a-uri-field",
+		},
+		{
+			Actual:   metadata.Image().GetElse(""),
+			Expected: "https://ipfs.io/ipfs/QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR/1.png",
+		},
+		{
+			Actual:   metadata.AnimationURL().GetElse(""),
+			Expected: "https://arweave.net/bNbA3TEQVL60xlgCcqdz4ZPHFZ711cZ3hmkpGttDt_U",
+		},
+		{
+			Actual:   metadata.ExternalLink().GetElse(""),
+			Expected: "https://example.com/1",
+		},
+	}{
+		if test.Expected != test.Actual {
+			t.Errorf("For test #%d, the actual value is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", test.Expected)
+			t.Logf("ACTUAL:   %q", test.Actual)
+		}
+	}
+}
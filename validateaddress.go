@@ -0,0 +1,26 @@
+package nftmeta
+
+import (
+	"sourcecode.social/reiver/go-erorr"
+)
+
+// validateAddress returns an error if 'value' is not a hexadecimal Ethereum address — i.e., "0x" followed by 40 hexadecimal digits.
+func validateAddress(value string) error {
+	const length = 2 + 40
+
+	if length != len(value) || '0' != value[0] || ('x' != value[1] && 'X' != value[1]) {
+		return erorr.Errorf("nftmeta: %q is not a valid address", value)
+	}
+
+	for _, r := range value[2:] {
+		switch {
+		case '0' <= r && r <= '9':
+		case 'a' <= r && r <= 'f':
+		case 'A' <= r && r <= 'F':
+		default:
+			return erorr.Errorf("nftmeta: %q is not a valid address", value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,391 @@
+package nftmeta
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"sourcecode.social/reiver/go-erorr"
+	"sourcecode.social/reiver/go-opt"
+)
+
+// xrplMaxURILength is the maximum length (in bytes) of the URI field of an XRP Ledger NFToken.
+const xrplMaxURILength = 256
+
+// XLS24dMetaData represents the (off-chain) JSON of the XRP Ledger XLS-24d NFT metadata standard.
+//
+// For example:
+//
+//	{"schema":"ipfs://...","nftType":"art.v0","name":"...","description":"...","image":"ipfs://...","collection":{"name":"...","family":"..."},"attributes":[...]}
+//
+// Its attributes can each have a "description". (See XLS24dAttribute.)
+//
+// Use XLS24dMetaDataFrom and XLS24dMetaData.MetaData to convert to and from MetaData.
+type XLS24dMetaData struct {
+	schema      opt.Optional[string]
+	nftType     opt.Optional[string]
+	name        opt.Optional[string]
+	description opt.Optional[string]
+	image       opt.Optional[string]
+	animation   opt.Optional[string]
+	video       opt.Optional[string]
+	audio       opt.Optional[string]
+	file        opt.Optional[string]
+	collection  opt.Optional[XLS24dCollection]
+	attributes []XLS24dAttribute
+}
+
+// XLS24dCollection represents the "collection" of the XLS-24d metadata JSON.
+type XLS24dCollection struct {
+	Name   string `json:"name"`
+	Family string `json:"family,omitempty"`
+}
+
+// XLS24dAttribute represents an entry in the "attributes" array of the XLS-24d metadata JSON.
+//
+// It is an Attribute that can also have a "description". For example:
+//
+//	{"trait_type":"Background","value":"Blue","description":"The sky on a clear day"}
+type XLS24dAttribute struct {
+	Attribute
+	description opt.Optional[string]
+}
+
+// Description returns the "description" of the attribute, if there is one.
+func (receiver XLS24dAttribute) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver *XLS24dAttribute) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver XLS24dAttribute) MarshalJSON() ([]byte, error) {
+	p, err := receiver.Attribute.MarshalJSON()
+	if nil != err {
+		return nil, err
+	}
+
+	value, something := receiver.description.Get()
+	if !something {
+		return p, nil
+	}
+
+	// Put the "description" just before the closing '}' of the attribute.
+	p = p[:len(p)-1]
+	p = append(p, ',')
+	p, err = appendJSONNameValue(p, "description", value)
+	if nil != err {
+		return nil, err
+	}
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func (receiver *XLS24dAttribute) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var attribute Attribute
+	if err := attribute.UnmarshalJSON(data); nil != err {
+		return err
+	}
+
+	var raw struct {
+		Description *string `json:"description"`
+	}
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling xls-24d attribute: %w", err)
+	}
+
+	receiver.Attribute   = attribute
+	receiver.description = optionalString(raw.Description)
+	return nil
+}
+
+// XLS24dMetaDataFrom returns the XLS-24d metadata equivalent of 'metadata'.
+//
+// The "animation_url" becomes the "animation". The "background_color", "external_link", "image_data", and "youtube_url" of 'metadata' have no XLS-24d equivalent, and are not carried over.
+func XLS24dMetaDataFrom(metadata MetaData) XLS24dMetaData {
+	var xls24d XLS24dMetaData
+
+	xls24d.name        = metadata.name
+	xls24d.description = metadata.description
+	xls24d.image       = metadata.image
+	xls24d.animation   = metadata.animationURL
+
+	for _, attribute := range metadata.attributes {
+		xls24d.attributes = append(xls24d.attributes, XLS24dAttribute{Attribute: attribute})
+	}
+
+	return xls24d
+}
+
+// MetaData returns the ERC-721 metadata equivalent of the XLS-24d metadata.
+//
+// The "schema", "nftType", "video", "audio", "file", and "collection" — and the "description" of each attribute — have no ERC-721 equivalent, and are not carried over.
+func (receiver XLS24dMetaData) MetaData() MetaData {
+	var metadata MetaData
+
+	metadata.name         = receiver.name
+	metadata.description  = receiver.description
+	metadata.image        = receiver.image
+	metadata.animationURL = receiver.animation
+
+	for _, attribute := range receiver.attributes {
+		metadata.attributes = append(metadata.attributes, attribute.Attribute)
+	}
+
+	return metadata
+}
+
+func (receiver XLS24dMetaData) MarshalJSON() ([]byte, error) {
+	var after bool
+
+	var buffer [512]byte
+	var p []byte = buffer[0:0]
+
+	p = append(p, '{')
+
+	for _, field := range []struct{
+		name  string
+		value opt.Optional[string]
+	}{
+		{"schema",      receiver.schema},
+		{"nftType",     receiver.nftType},
+		{"name",        receiver.name},
+		{"description", receiver.description},
+		{"image",       receiver.image},
+		{"animation",   receiver.animation},
+		{"video",       receiver.video},
+		{"audio",       receiver.audio},
+		{"file",        receiver.file},
+	}{
+		value, something := field.value.Get()
+		if !something {
+			continue
+		}
+
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		var err error
+		p, err = appendJSONNameValue(p, field.name, value)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	if collection, something := receiver.collection.Get(); something {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		bytes, err := json.Marshal(collection)
+		if nil != err {
+			return nil, erorr.Errorf("nftmeta: problem json-marshaling collection: %w", err)
+		}
+
+		p = append(p, `"collection":`...)
+		p = append(p, bytes...)
+	}
+
+	if 0 < len(receiver.attributes) {
+		if after {
+			p = append(p, ',')
+		}
+		after = true
+
+		p = append(p, `"attributes":[`...)
+		for index, attribute := range receiver.attributes {
+			if 0 < index {
+				p = append(p, ',')
+			}
+
+			bytes, err := attribute.MarshalJSON()
+			if nil != err {
+				return nil, err
+			}
+
+			p = append(p, bytes...)
+		}
+		p = append(p, ']')
+	}
+
+	p = append(p, '}')
+
+	return p, nil
+}
+
+func (receiver *XLS24dMetaData) UnmarshalJSON(data []byte) error {
+	if nil == receiver {
+		return errNilReceiver
+	}
+
+	var raw struct {
+		Schema      *string           `json:"schema"`
+		NFTType     *string           `json:"nftType"`
+		Name        *string           `json:"name"`
+		Description *string           `json:"description"`
+		Image       *string           `json:"image"`
+		Animation   *string           `json:"animation"`
+		Video       *string           `json:"video"`
+		Audio       *string           `json:"audio"`
+		File        *string           `json:"file"`
+		Collection  *XLS24dCollection `json:"collection"`
+		Attributes  []XLS24dAttribute `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(data, &raw); nil != err {
+		return erorr.Errorf("nftmeta: problem json-unmarshaling xls-24d metadata: %w", err)
+	}
+
+	var metadata XLS24dMetaData
+
+	metadata.schema      = optionalString(raw.Schema)
+	metadata.nftType     = optionalString(raw.NFTType)
+	metadata.name        = optionalString(raw.Name)
+	metadata.description = optionalString(raw.Description)
+	metadata.image       = optionalString(raw.Image)
+	metadata.animation   = optionalString(raw.Animation)
+	metadata.video       = optionalString(raw.Video)
+	metadata.audio       = optionalString(raw.Audio)
+	metadata.file        = optionalString(raw.File)
+	if nil != raw.Collection {
+		metadata.collection = opt.Something(*raw.Collection)
+	}
+	metadata.attributes  = raw.Attributes
+
+	*receiver = metadata
+	return nil
+}
+
+func (receiver XLS24dMetaData) Animation() opt.Optional[string] {
+	return receiver.animation
+}
+
+func (receiver XLS24dMetaData) Audio() opt.Optional[string] {
+	return receiver.audio
+}
+
+func (receiver XLS24dMetaData) Collection() opt.Optional[XLS24dCollection] {
+	return receiver.collection
+}
+
+func (receiver XLS24dMetaData) Description() opt.Optional[string] {
+	return receiver.description
+}
+
+func (receiver XLS24dMetaData) File() opt.Optional[string] {
+	return receiver.file
+}
+
+func (receiver XLS24dMetaData) Image() opt.Optional[string] {
+	return receiver.image
+}
+
+func (receiver XLS24dMetaData) Name() opt.Optional[string] {
+	return receiver.name
+}
+
+func (receiver XLS24dMetaData) NFTType() opt.Optional[string] {
+	return receiver.nftType
+}
+
+func (receiver XLS24dMetaData) Schema() opt.Optional[string] {
+	return receiver.schema
+}
+
+func (receiver XLS24dMetaData) Video() opt.Optional[string] {
+	return receiver.video
+}
+
+// Attributes returns (a copy of) the attributes.
+func (receiver XLS24dMetaData) Attributes() []XLS24dAttribute {
+	if len(receiver.attributes) <= 0 {
+		return nil
+	}
+
+	attributes := make([]XLS24dAttribute, len(receiver.attributes))
+	copy(attributes, receiver.attributes)
+	return attributes
+}
+
+func (receiver *XLS24dMetaData) SetAnimation(value string) {
+	receiver.animation = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetAudio(value string) {
+	receiver.audio = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetCollection(value XLS24dCollection) {
+	receiver.collection = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetDescription(value string) {
+	receiver.description = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetFile(value string) {
+	receiver.file = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetImage(value string) {
+	receiver.image = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetName(value string) {
+	receiver.name = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetNFTType(value string) {
+	receiver.nftType = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetSchema(value string) {
+	receiver.schema = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) SetVideo(value string) {
+	receiver.video = opt.Something(value)
+}
+
+func (receiver *XLS24dMetaData) AppendAttribute(attribute XLS24dAttribute) {
+	receiver.attributes = append(receiver.attributes, attribute)
+}
+
+// EncodeNFTokenURI returns the hex-encoded form of 'uri' (the URI of the metadata JSON) that goes into the "URI" field of an XRP Ledger NFTokenMint transaction.
+//
+// EncodeNFTokenURI returns an error if 'uri' is longer than 256 bytes (which is the most the "URI" field can hold).
+func EncodeNFTokenURI(uri string) (string, error) {
+	if xrplMaxURILength < len(uri) {
+		return "", erorr.Errorf("nftmeta: URI is %d bytes long, but an NFToken URI can be at most %d bytes", len(uri), xrplMaxURILength)
+	}
+
+	return strings.ToUpper(hex.EncodeToString([]byte(uri))), nil
+}
+
+// DecodeNFTokenURI returns the URI that is hex-encoded in 'hexURI' (such as from the "URI" field of an XRP Ledger NFToken).
+//
+// Both upper-case and lower-case hexadecimal are accepted.
+func DecodeNFTokenURI(hexURI string) (string, error) {
+	p, err := hex.DecodeString(hexURI)
+	if nil != err {
+		return "", erorr.Errorf("nftmeta: NFToken URI %q is not valid hexadecimal: %w", hexURI, err)
+	}
+	if xrplMaxURILength < len(p) {
+		return "", erorr.Errorf("nftmeta: NFToken URI is %d bytes long, but can be at most %d bytes", len(p), xrplMaxURILength)
+	}
+	if !utf8.Valid(p) {
+		return "", erorr.Errorf("nftmeta: NFToken URI %q is not UTF-8", hexURI)
+	}
+
+	return string(p), nil
+}
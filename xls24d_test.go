@@ -0,0 +1,136 @@
+package nftmeta_test
+
+import (
+	"testing"
+
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/reiver/go-nftmeta"
+)
+
+func TestXLS24dMetaData_MarshalJSON(t *testing.T) {
+
+	tests := []struct{
+		XLS24dMetaData nftmeta.XLS24dMetaData
+		Expected []byte
+	}{
+		{
+			XLS24dMetaData: nftmeta.XLS24dMetaData{},
+			Expected: []byte(`{}`),
+		},
+		{
+			XLS24dMetaData: func()nftmeta.XLS24dMetaData{
+				var metadata nftmeta.XLS24dMetaData
+				metadata.SetSchema("ipfs://QmNpi8rcXEkohca8iXu7zysKKSJYqCvBJn3xJwga8jXqWU")
+				metadata.SetNFTType("art.v0")
+				metadata.SetName("Pirate #1")
+				metadata.SetDescription("A pirate")
+				metadata.SetImage("ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.png")
+				metadata.SetCollection(nftmeta.XLS24dCollection{Name:"Pirates", Family:"Seafarers"})
+
+				var hat = nftmeta.XLS24dAttribute{Attribute: nftmeta.AttributeString("Hat", "Tricorn")}
+				hat.SetDescription("A three-cornered hat")
+				metadata.AppendAttribute(hat)
+				metadata.AppendAttribute(nftmeta.XLS24dAttribute{Attribute: nftmeta.AttributeInt64("Gold", 12)})
+
+				return metadata
+			}(),
+			Expected: []byte(`{"schema":"ipfs://QmNpi8rcXEkohca8iXu7zysKKSJYqCvBJn3xJwga8jXqWU","nftType":"art.v0","name":"Pirate #1","description":"A pirate","image":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.png","collection":{"name":"Pirates","family":"Seafarers"},"attributes":[{"trait_type":"Hat","value":"Tricorn","description":"A three-cornered hat"},{"trait_type":"Gold","value":12}]}`),
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := json.Marshal(test.XLS24dMetaData)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the actual JSON is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+
+		var metadata nftmeta.XLS24dMetaData
+		if err := json.Unmarshal(actual, &metadata); nil != err {
+			t.Errorf("For test #%d, did not expect an error when unmarshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		again, err := json.Marshal(metadata)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error when re-marshaling but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.Expected, again; !bytes.Equal(expected, actual) {
+			t.Errorf("For test #%d, the XLS-24d metadata did not round-trip.", testNumber)
+			t.Logf("EXPECTED: %s", expected)
+			t.Logf("ACTUAL:   %s", actual)
+			continue
+		}
+	}
+}
+
+func TestNFTokenURI(t *testing.T) {
+
+	tests := []struct{
+		URI string
+		Expected string
+	}{
+		{
+			URI:      "",
+			Expected: "",
+		},
+		{
+			URI:      "ipfs://x",
+			Expected: "697066733A2F2F78",
+		},
+	}
+
+	for testNumber, test := range tests {
+
+		actual, err := nftmeta.EncodeNFTokenURI(test.URI)
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected := test.Expected; expected != actual {
+			t.Errorf("For test #%d, the actual hex-encoded URI is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+
+		uri, err := nftmeta.DecodeNFTokenURI(strings.ToLower(actual))
+		if nil != err {
+			t.Errorf("For test #%d, did not expect an error when decoding but actually got one.", testNumber)
+			t.Logf("ERROR: (%T) %s", err, err)
+			continue
+		}
+
+		if expected, actual := test.URI, uri; expected != actual {
+			t.Errorf("For test #%d, the actual decoded URI is not what was expected.", testNumber)
+			t.Logf("EXPECTED: %q", expected)
+			t.Logf("ACTUAL:   %q", actual)
+			continue
+		}
+	}
+
+	if _, err := nftmeta.EncodeNFTokenURI("ipfs://" + strings.Repeat("x", 250)); nil == err {
+		t.Errorf("Expected an error for a URI longer than 256 bytes, but did not actually get one.")
+	}
+	if _, err := nftmeta.DecodeNFTokenURI("not hex"); nil == err {
+		t.Errorf("Expected an error for a URI that is not hexadecimal, but did not actually get one.")
+	}
+}